@@ -9,6 +9,7 @@ import (
 
 	"github.com/firebolt-db/firebolt-go-sdk/logging"
 	"github.com/firebolt-db/firebolt-go-sdk/rows"
+	"github.com/firebolt-db/firebolt-go-sdk/statement"
 
 	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
 )
@@ -16,11 +17,16 @@ import (
 func convertToNamedValues(args []any) ([]driver.NamedValue, error) {
 	named := make([]driver.NamedValue, len(args))
 	for i, a := range args {
-		val, err := driver.DefaultParameterConverter.ConvertValue(a)
+		name := ""
+		if namedArg, ok := a.(sql.NamedArg); ok {
+			name, a = namedArg.Name, namedArg.Value
+		}
+		val, err := statement.CheckParameter(a)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert arg[%d]=%v (%T): %w", i, a, a, err)
 		}
 		named[i] = driver.NamedValue{
+			Name:    name,
 			Ordinal: i + 1,
 			Value:   val,
 		}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/firebolt-db/firebolt-go-sdk/client"
 	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
+	"github.com/firebolt-db/firebolt-go-sdk/rows"
 	"github.com/parquet-go/parquet-go"
 )
 
@@ -2145,6 +2146,7 @@ func TestTruncateAllColumnTypes(t *testing.T) { // NOSONAR - each column type ne
 		{"timestampntz", []interface{}{day, day.Add(time.Hour), day.Add(2 * time.Hour), day.Add(3 * time.Hour)}},
 		{"timestamptz", []interface{}{day, day.Add(time.Hour), day.Add(2 * time.Hour), day.Add(3 * time.Hour)}},
 		{"bytea", []interface{}{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}},
+		{"geography", []interface{}{"POINT(1 1)", rows.Geography("0101000000000000000000F03F000000000000F03F"), "POINT(3 3)", "POINT(4 4)"}},
 		{"text null", []interface{}{"a", nil, "c", "d"}},
 		{"int null", []interface{}{int32(1), nil, int32(3), int32(4)}},
 		{"array(int)", []interface{}{
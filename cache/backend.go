@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"time"
+)
+
+// Entry is a serialized cache value along with its expiry
+type Entry struct {
+	Value []byte `json:"value"`
+	// ExpiresAt is the time the entry expires at, a zero value means the entry doesn't expire
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (e *Entry) isExpired() bool {
+	return !e.ExpiresAt.IsZero() && time.Now().After(e.ExpiresAt)
+}
+
+// Backend stores cache entries outside the process memory, so that they survive restarts and
+// can be shared between processes, e.g. in a file or in a Redis-like store.
+// Backends must be safe for concurrent use.
+type Backend interface {
+	// Get returns the entry stored under key, or nil if there is none.
+	// Expired entries may be returned, they are skipped by the cache.
+	Get(key string) (*Entry, error)
+	// Put stores the entry under key. Stores with native expiration should expire it at entry.ExpiresAt.
+	Put(key string, entry Entry) error
+	// Delete removes the entry stored under key, if any
+	Delete(key string) error
+}
+
+// PrefixDeleter is implemented by backends that can remove all the entries whose key starts with a prefix,
+// it is used by Cache.Invalidate and Cache.Clear
+type PrefixDeleter interface {
+	DeletePrefix(prefix string) error
+}
@@ -1,56 +1,332 @@
 package cache
 
 import (
+	"container/list"
+	"encoding/json"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/logging"
 )
 
 type item struct {
+	key       string
 	value     interface{}
 	expiresAt time.Time
 	hasTTL    bool
 }
 
-func (i item) isExpired() bool {
-	return i.hasTTL && time.Now().After(i.expiresAt)
+func (i *item) isExpired(now time.Time) bool {
+	return i.hasTTL && now.After(i.expiresAt)
+}
+
+// Stats are the counters of a cache
+type Stats struct {
+	// Size is the number of items currently in memory, including the expired ones not removed yet
+	Size int
+	// Hits and Misses count the lookups that found a value or didn't
+	Hits   uint64
+	Misses uint64
+	// Evictions counts the least recently used items removed to respect the size bound
+	Evictions uint64
+	// Expirations counts the expired items removed
+	Expirations uint64
+}
+
+// Option configures a Cache
+type Option func(c *Cache)
+
+// WithMaxSize bounds the number of items kept in memory, the least recently used items are
+// evicted once it is reached. A zero size means no bound.
+func WithMaxSize(size int) Option {
+	return func(c *Cache) {
+		c.maxSize = size
+	}
+}
+
+// WithJanitor removes the expired items from memory every interval in the background,
+// instead of only when they are looked up. Close stops the janitor.
+func WithJanitor(interval time.Duration) Option {
+	return func(c *Cache) {
+		c.janitorInterval = interval
+	}
 }
 
-// Cache is a thread-safe in-memory key-value store with optional per-item TTL.
+// Cache is a thread-safe in-memory key-value store with optional per-item TTL,
+// an optional size bound with LRU eviction and an optional janitor for expired items.
+// With a Backend, the values are also stored as JSON in the backend, so that other
+// processes can load them with Load.
 type Cache struct {
-	mu    sync.RWMutex
-	items map[string]item
+	*memory
+	// backendMu guards backend and namespace
+	backendMu sync.Mutex
+	// backend is nil for memory-only caches
+	backend Backend
+	// namespace prefixes the keys in the backend, so that several caches can share it
+	namespace string
+}
+
+// memory holds the values of a cache in memory, it is shared by the caches returned by WithBackend
+type memory struct {
+	mu    sync.Mutex
+	items map[string]*list.Element
+	// lru holds the items from the most to the least recently used
+	lru             *list.List
+	maxSize         int
+	stats           Stats
+	janitorInterval time.Duration
+	stopJanitor     chan struct{}
+	closeOnce       sync.Once
+}
+
+// New returns an empty cache, it is unbounded and without a janitor unless configured otherwise by the options
+func New(options ...Option) *Cache {
+	c := &Cache{memory: &memory{items: make(map[string]*list.Element), lru: list.New()}}
+	for _, option := range options {
+		option(c)
+	}
+	if c.janitorInterval > 0 {
+		c.stopJanitor = make(chan struct{})
+		go c.runJanitor()
+	}
+	return c
+}
+
+// Close stops the janitor of the cache, if any, along with the one of the caches sharing its memory
+func (c *Cache) Close() {
+	c.closeOnce.Do(func() {
+		if c.stopJanitor != nil {
+			close(c.stopJanitor)
+		}
+	})
+}
+
+func (c *Cache) runJanitor() {
+	ticker := time.NewTicker(c.janitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.RemoveExpired()
+		case <-c.stopJanitor:
+			return
+		}
+	}
+}
+
+// RemoveExpired removes the expired items from memory
+func (c *Cache) RemoveExpired() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, element := range c.items {
+		if element.Value.(*item).isExpired(now) {
+			c.removeElement(element)
+			c.stats.Expirations++
+		}
+	}
+}
+
+// SetBackend makes the cache store its values in backend as well, under keys prefixed with namespace.
+// A nil backend makes the cache memory-only again.
+func (c *Cache) SetBackend(backend Backend, namespace string) {
+	c.backendMu.Lock()
+	defer c.backendMu.Unlock()
+	c.backend = backend
+	c.namespace = namespace
+}
+
+// WithBackend returns a cache sharing the values in memory, the size bound and the janitor of c,
+// which stores its values in backend under keys prefixed with namespace instead of the backend of c.
+// It lets a single in-memory cache be persisted to different backends, e.g. one per connector.
+func (c *Cache) WithBackend(backend Backend, namespace string) *Cache {
+	return &Cache{memory: c.memory, backend: backend, namespace: namespace}
+}
+
+func (c *Cache) getBackend() (Backend, string) {
+	c.backendMu.Lock()
+	defer c.backendMu.Unlock()
+	return c.backend, c.namespace
+}
+
+// Stats returns the current counters of the cache
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = len(c.items)
+	return stats
+}
+
+// get returns the value for the given key from memory and marks it as recently used, without counting the lookup
+func (c *Cache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*item)
+	if entry.isExpired(time.Now()) {
+		c.removeElement(element)
+		c.stats.Expirations++
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry.value, true
 }
 
-func New() *Cache {
-	return &Cache{items: make(map[string]item)}
+// countLookup counts a hit or a miss
+func (c *Cache) countLookup(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
 }
 
 // Get returns the value for the given key, or nil if not found or expired.
+// Only the values in memory are returned, use Load to also look the value up in the backend.
 func (c *Cache) Get(key string) interface{} {
-	c.mu.RLock()
-	entry, ok := c.items[key]
-	c.mu.RUnlock()
-	if !ok || entry.isExpired() {
-		return nil
-	}
-	return entry.value
+	value, ok := c.get(key)
+	c.countLookup(ok)
+	return value
 }
 
 // Put stores a value under the given key. A zero ttl means no expiration.
 func (c *Cache) Put(key string, value interface{}, ttl time.Duration) {
-	entry := item{value: value}
+	c.putInMemory(key, value, ttl)
+	backend, namespace := c.getBackend()
+	if backend == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		logging.Infolog.Printf("unable to serialize the cache value of %s: %v", key, err)
+		return
+	}
+	entry := Entry{Value: data}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+	if err = backend.Put(namespace+key, entry); err != nil {
+		logging.Infolog.Printf("unable to store %s in the cache backend: %v", key, err)
+	}
+}
+
+func (c *Cache) putInMemory(key string, value interface{}, ttl time.Duration) {
+	entry := &item{key: key, value: value}
 	if ttl > 0 {
 		entry.hasTTL = true
 		entry.expiresAt = time.Now().Add(ttl)
 	}
 	c.mu.Lock()
-	c.items[key] = entry
-	c.mu.Unlock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.lru.PushFront(entry)
+	for c.maxSize > 0 && len(c.items) > c.maxSize {
+		c.removeElement(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// removeElement removes an item from memory, the caller must hold the lock
+func (c *Cache) removeElement(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.items, element.Value.(*item).key)
 }
 
 // Delete removes the value for the given key.
 func (c *Cache) Delete(key string) {
 	c.mu.Lock()
-	delete(c.items, key)
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+	c.mu.Unlock()
+	if backend, namespace := c.getBackend(); backend != nil {
+		if err := backend.Delete(namespace + key); err != nil {
+			logging.Infolog.Printf("unable to delete %s from the cache backend: %v", key, err)
+		}
+	}
+}
+
+// Invalidate removes the values whose key starts with prefix, e.g. after a topology change.
+// The values are removed from the backend as well if it implements PrefixDeleter,
+// otherwise only the ones present in memory are removed from it.
+func (c *Cache) Invalidate(prefix string) {
+	var keys []string
+	c.mu.Lock()
+	for key, element := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(element)
+			keys = append(keys, key)
+		}
+	}
 	c.mu.Unlock()
+	backend, namespace := c.getBackend()
+	if backend == nil {
+		return
+	}
+	if prefixDeleter, ok := backend.(PrefixDeleter); ok {
+		if err := prefixDeleter.DeletePrefix(namespace + prefix); err != nil {
+			logging.Infolog.Printf("unable to delete %s* from the cache backend: %v", prefix, err)
+		}
+		return
+	}
+	for _, key := range keys {
+		if err := backend.Delete(namespace + key); err != nil {
+			logging.Infolog.Printf("unable to delete %s from the cache backend: %v", key, err)
+		}
+	}
+}
+
+// Clear removes all the values, see Invalidate for how they are removed from the backend
+func (c *Cache) Clear() {
+	c.Invalidate("")
+}
+
+// Load returns the value of type T for the given key. Values missing in memory are looked up
+// in the backend and decoded from JSON, they are then kept in memory until they expire.
+// ok is false if the value is not found, expired or of another type.
+func Load[T any](c *Cache, key string) (value T, ok bool) {
+	if cached, found := c.get(key); found {
+		value, ok = cached.(T)
+		c.countLookup(ok)
+		return value, ok
+	}
+	value, ok = loadFromBackend[T](c, key)
+	c.countLookup(ok)
+	return value, ok
+}
+
+func loadFromBackend[T any](c *Cache, key string) (value T, ok bool) {
+	backend, namespace := c.getBackend()
+	if backend == nil {
+		return value, false
+	}
+	entry, err := backend.Get(namespace + key)
+	if err != nil {
+		logging.Infolog.Printf("unable to load %s from the cache backend: %v", key, err)
+		return value, false
+	}
+	if entry == nil || entry.isExpired() {
+		return value, false
+	}
+	if err = json.Unmarshal(entry.Value, &value); err != nil {
+		logging.Infolog.Printf("unable to decode the cache value of %s: %v", key, err)
+		return value, false
+	}
+	if entry.ExpiresAt.IsZero() {
+		c.putInMemory(key, value, 0)
+	} else if ttl := time.Until(entry.ExpiresAt); ttl > 0 {
+		c.putInMemory(key, value, ttl)
+	}
+	return value, true
 }
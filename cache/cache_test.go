@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+type testValue struct {
+	Name string `json:"name"`
+}
+
+func newTestFileBackend(t *testing.T) *FileBackend {
+	backend, err := NewFileBackend(filepath.Join(t.TempDir(), "cache"), []byte("secret"))
+	utils.RaiseIfError(t, err)
+	return backend
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := New()
+	c.Put("forever", "value", 0)
+	c.Put("short", "value", 10*time.Millisecond)
+	utils.AssertEqual(c.Get("short"), "value", t, "value should be cached before its expiry")
+
+	time.Sleep(20 * time.Millisecond)
+	utils.AssertEqual(c.Get("short"), nil, t, "expired value should not be returned")
+	utils.AssertEqual(c.Get("forever"), "value", t, "value without TTL should not expire")
+
+	c.Delete("forever")
+	utils.AssertEqual(c.Get("forever"), nil, t, "deleted value should not be returned")
+}
+
+func TestCacheBackendSharing(t *testing.T) {
+	backend := newTestFileBackend(t)
+	first := New()
+	first.SetBackend(backend, "test/")
+	first.Put("key", testValue{Name: "shared"}, time.Hour)
+
+	// another process using the same backend
+	second := New()
+	second.SetBackend(backend, "test/")
+	value, ok := Load[testValue](second, "key")
+	utils.AssertEqual(ok, true, t, "value should be loaded from the backend")
+	utils.AssertEqual(value.Name, "shared", t, "wrong value loaded from the backend")
+
+	// namespaces separate caches sharing a backend
+	other := New()
+	other.SetBackend(backend, "other/")
+	_, ok = Load[testValue](other, "key")
+	utils.AssertEqual(ok, false, t, "value should not be loaded from another namespace")
+
+	second.Delete("key")
+	third := New()
+	third.SetBackend(backend, "test/")
+	_, ok = Load[testValue](third, "key")
+	utils.AssertEqual(ok, false, t, "deleted value should not be loaded from the backend")
+}
+
+func TestCacheWithBackend(t *testing.T) {
+	c := New()
+	backend := newTestFileBackend(t)
+	view := c.WithBackend(backend, "view/")
+	view.Put("key", testValue{Name: "view"}, time.Hour)
+	c.Put("memory", "value", 0)
+
+	value, ok := Load[testValue](c, "key")
+	utils.AssertEqual(ok, true, t, "the view should share the values in memory")
+	utils.AssertEqual(value.Name, "view", t, "wrong value shared by the view")
+	utils.AssertEqual(view.Get("memory"), "value", t, "the cache values should be visible in the view")
+
+	other := New()
+	other.SetBackend(backend, "view/")
+	_, ok = Load[testValue](other, "key")
+	utils.AssertEqual(ok, true, t, "the view should store its values in its backend")
+	entry, err := backend.Get("view/memory")
+	utils.RaiseIfError(t, err)
+	if entry != nil {
+		t.Errorf("the values of the cache should not be stored in the backend of the view")
+	}
+
+	view.Delete("key")
+	utils.AssertEqual(c.Get("key"), nil, t, "a value deleted from the view should be deleted from memory")
+}
+
+func TestCacheBackendTTL(t *testing.T) {
+	backend := newTestFileBackend(t)
+	first := New()
+	first.SetBackend(backend, "")
+	first.Put("key", testValue{Name: "short"}, 20*time.Millisecond)
+
+	second := New()
+	second.SetBackend(backend, "")
+	_, ok := Load[testValue](second, "key")
+	utils.AssertEqual(ok, true, t, "value should be loaded before its expiry")
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = Load[testValue](second, "key")
+	utils.AssertEqual(ok, false, t, "expired value loaded from the backend should expire in memory as well")
+	third := New()
+	third.SetBackend(backend, "")
+	_, ok = Load[testValue](third, "key")
+	utils.AssertEqual(ok, false, t, "expired value should not be loaded from the backend")
+}
+
+func TestLoadWrongType(t *testing.T) {
+	c := New()
+	c.Put("key", "value", 0)
+	_, ok := Load[testValue](c, "key")
+	utils.AssertEqual(ok, false, t, "value of another type should not be loaded")
+	value, ok := Load[string](c, "key")
+	utils.AssertEqual(ok, true, t, "value should be loaded from memory")
+	utils.AssertEqual(value, "value", t, "wrong value loaded from memory")
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	c := New(WithMaxSize(2))
+	c.Put("a", 1, 0)
+	c.Put("b", 2, 0)
+	// a becomes the most recently used, so b is evicted
+	utils.AssertEqual(c.Get("a"), 1, t, "a should be cached")
+	c.Put("c", 3, 0)
+
+	utils.AssertEqual(c.Get("b"), nil, t, "least recently used value should be evicted")
+	utils.AssertEqual(c.Get("a"), 1, t, "recently used value should not be evicted")
+	utils.AssertEqual(c.Get("c"), 3, t, "new value should be cached")
+
+	// updating a value doesn't grow the cache
+	c.Put("c", 4, 0)
+	stats := c.Stats()
+	utils.AssertEqual(stats.Size, 2, t, "wrong cache size")
+	utils.AssertEqual(stats.Evictions, uint64(1), t, "wrong number of evictions")
+	utils.AssertEqual(stats.Hits, uint64(3), t, "wrong number of hits")
+	utils.AssertEqual(stats.Misses, uint64(1), t, "wrong number of misses")
+}
+
+func TestCacheJanitor(t *testing.T) {
+	c := New(WithJanitor(10 * time.Millisecond))
+	defer c.Close()
+	c.Put("short", "value", time.Millisecond)
+	c.Put("forever", "value", 0)
+
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().Size > 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stats := c.Stats()
+	utils.AssertEqual(stats.Size, 1, t, "expired value should be removed by the janitor")
+	utils.AssertEqual(stats.Expirations, uint64(1), t, "wrong number of expirations")
+	utils.AssertEqual(stats.Misses, uint64(0), t, "janitor should not count lookups")
+
+	c.Close()
+	// closing twice is allowed
+	c.Close()
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	backend := newTestFileBackend(t)
+	c := New()
+	c.SetBackend(backend, "test/")
+	c.Put("account1/system", testValue{Name: "1"}, 0)
+	c.Put("account1/engine", testValue{Name: "2"}, 0)
+	c.Put("account2/system", testValue{Name: "3"}, 0)
+
+	// a value only present in the backend is invalidated as well
+	other := New()
+	other.SetBackend(backend, "test/")
+	other.Put("account1/other", testValue{Name: "4"}, 0)
+
+	c.Invalidate("account1/")
+	utils.AssertEqual(c.Stats().Size, 1, t, "invalidated values should be removed from memory")
+	for _, key := range []string{"account1/system", "account1/engine", "account1/other"} {
+		_, ok := Load[testValue](c, key)
+		utils.AssertEqual(ok, false, t, "invalidated value should not be loaded: "+key)
+	}
+	value, ok := Load[testValue](c, "account2/system")
+	utils.AssertEqual(ok, true, t, "value with another prefix should not be invalidated")
+	utils.AssertEqual(value.Name, "3", t, "wrong value loaded")
+
+	c.Clear()
+	utils.AssertEqual(c.Stats().Size, 0, t, "cleared cache should be empty")
+	fresh := New()
+	fresh.SetBackend(backend, "test/")
+	_, ok = Load[testValue](fresh, "account2/system")
+	utils.AssertEqual(ok, false, t, "cleared value should not be loaded from the backend")
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// fileFormatVersion is the first byte of the cache file, followed by the salt, the nonce and the encrypted entries
+const fileFormatVersion = 1
+
+// saltSize is the size of the random salt the key of the cache file is derived with
+const saltSize = 16
+
+// keyDerivationInfo binds the keys derived from the secret to the cache file
+const keyDerivationInfo = "firebolt-go-sdk cache file"
+
+// FileBackend stores the cache entries in a file encrypted with AES-GCM, so that they survive
+// restarts of short-lived processes such as CLI invocations or serverless functions.
+// The key is derived from the secret with HKDF-SHA256 and a random salt stored in the file,
+// which is renewed on each write. The secret should be random, e.g. 32 bytes from crypto/rand,
+// rather than a password. Processes sharing the file should use the same secret. Writes replace
+// the whole file atomically; entries written concurrently by several processes may be lost,
+// which only costs a cache miss.
+type FileBackend struct {
+	path   string
+	secret []byte
+	mutex  sync.Mutex
+}
+
+// NewFileBackend returns a backend storing the entries in the file at path, encrypted with a key derived from secret.
+// The file is created with the 0600 permissions once the first entry is stored.
+func NewFileBackend(path string, secret []byte) (*FileBackend, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("the secret of the file cache backend must not be empty")
+	}
+	return &FileBackend{path: path, secret: bytes.Clone(secret)}, nil
+}
+
+// cipherFor returns the cipher of the cache file whose key is derived with salt
+func (b *FileBackend) cipherFor(salt []byte) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, b.secret, salt, keyDerivationInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive the cache file key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the cache file cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the cache file cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// Get returns the entry stored under key, or nil if there is none
+func (b *FileBackend) Get(key string) (*Entry, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	entries, err := b.read()
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := entries[key]; ok {
+		return &entry, nil
+	}
+	return nil, nil
+}
+
+// Put stores the entry under key, the expired entries are dropped from the file
+func (b *FileBackend) Put(key string, entry Entry) error {
+	return b.update(func(entries map[string]Entry) {
+		entries[key] = entry
+	})
+}
+
+// Delete removes the entry stored under key
+func (b *FileBackend) Delete(key string) error {
+	return b.update(func(entries map[string]Entry) {
+		delete(entries, key)
+	})
+}
+
+// DeletePrefix removes the entries whose key starts with prefix
+func (b *FileBackend) DeletePrefix(prefix string) error {
+	return b.update(func(entries map[string]Entry) {
+		for key := range entries {
+			if strings.HasPrefix(key, prefix) {
+				delete(entries, key)
+			}
+		}
+	})
+}
+
+// update applies change to the entries of the file and writes them back
+func (b *FileBackend) update(change func(entries map[string]Entry)) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	entries, err := b.read()
+	if err != nil {
+		// an unreadable file, e.g. encrypted with another secret or in an older format, is replaced
+		entries = make(map[string]Entry)
+	}
+	for key, entry := range entries {
+		if entry.isExpired() {
+			delete(entries, key)
+		}
+	}
+	change(entries)
+	return b.write(entries)
+}
+
+// read decrypts the entries of the file, a missing file has no entries
+func (b *FileBackend) read() (map[string]Entry, error) {
+	entries := make(map[string]Entry)
+	content, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the cache file: %w", err)
+	}
+	if len(content) < 1+saltSize || content[0] != fileFormatVersion {
+		return nil, errors.New("the cache file is corrupted or of an unsupported version")
+	}
+	salt, content := content[1:1+saltSize], content[1+saltSize:]
+	aead, err := b.cipherFor(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := aead.NonceSize()
+	if len(content) < nonceSize {
+		return nil, errors.New("the cache file is corrupted")
+	}
+	plaintext, err := aead.Open(nil, content[:nonceSize], content[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.New("unable to decrypt the cache file, it is corrupted or encrypted with another secret")
+	}
+	if err = json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("unable to parse the cache file: %w", err)
+	}
+	return entries, nil
+}
+
+// write encrypts the entries into a temporary file, which then replaces the cache file
+func (b *FileBackend) write(entries map[string]Entry) error {
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("unable to serialize the cache entries: %w", err)
+	}
+	salt := make([]byte, saltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return fmt.Errorf("unable to generate a salt: %w", err)
+	}
+	aead, err := b.cipherFor(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return fmt.Errorf("unable to generate a nonce: %w", err)
+	}
+	content := append([]byte{fileFormatVersion}, salt...)
+	content = append(content, nonce...)
+	content = aead.Seal(content, nonce, plaintext, nil)
+
+	tmp, err := os.CreateTemp(filepath.Dir(b.path), filepath.Base(b.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("unable to create a temporary cache file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to write the cache file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write the cache file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), b.path); err != nil {
+		return fmt.Errorf("unable to replace the cache file: %w", err)
+	}
+	return nil
+}
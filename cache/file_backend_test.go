@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+func TestFileBackendEncryption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	backend, err := NewFileBackend(path, []byte("secret"))
+	utils.RaiseIfError(t, err)
+	utils.RaiseIfError(t, backend.Put("token", Entry{Value: []byte("aMysteriousToken")}))
+
+	content, err := os.ReadFile(path)
+	utils.RaiseIfError(t, err)
+	if bytes.Contains(content, []byte("aMysteriousToken")) {
+		t.Errorf("the cache file should be encrypted")
+	}
+	info, err := os.Stat(path)
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(info.Mode().Perm(), os.FileMode(0600), t, "the cache file should only be readable by its owner")
+
+	entry, err := backend.Get("token")
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(string(entry.Value), "aMysteriousToken", t, "wrong entry read from the file")
+
+	otherBackend, err := NewFileBackend(path, []byte("another secret"))
+	utils.RaiseIfError(t, err)
+	if _, err = otherBackend.Get("token"); err == nil {
+		t.Errorf("expected an error when reading the file with another secret")
+	}
+	// the unreadable file is replaced
+	utils.RaiseIfError(t, otherBackend.Put("other", Entry{Value: []byte("value")}))
+	entry, err = otherBackend.Get("other")
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(string(entry.Value), "value", t, "wrong entry read from the replaced file")
+
+	if _, err = NewFileBackend(path, nil); err == nil {
+		t.Errorf("expected an error for an empty secret")
+	}
+}
+
+func TestFileBackendKeyDerivation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	backend, err := NewFileBackend(path, []byte("secret"))
+	utils.RaiseIfError(t, err)
+	utils.RaiseIfError(t, backend.Put("token", Entry{Value: []byte("value")}))
+	first, err := os.ReadFile(path)
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(first[0], byte(fileFormatVersion), t, "the cache file should start with its format version")
+
+	utils.RaiseIfError(t, backend.Put("token", Entry{Value: []byte("value")}))
+	second, err := os.ReadFile(path)
+	utils.RaiseIfError(t, err)
+	if bytes.Equal(first[1:1+saltSize], second[1:1+saltSize]) {
+		t.Errorf("a new salt should be generated on each write")
+	}
+
+	// a file in another format, e.g. written by an older version of the SDK, is unreadable and replaced
+	utils.RaiseIfError(t, os.WriteFile(path, []byte("not a cache file"), 0600))
+	if _, err = backend.Get("token"); err == nil {
+		t.Errorf("expected an error when reading a file in another format")
+	}
+	utils.RaiseIfError(t, backend.Put("token", Entry{Value: []byte("value")}))
+	entry, err := backend.Get("token")
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(string(entry.Value), "value", t, "wrong entry read from the replaced file")
+}
+
+func TestFileBackendEntries(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	entry, err := backend.Get("missing")
+	utils.RaiseIfError(t, err)
+	if entry != nil {
+		t.Errorf("expected no entry for a missing key")
+	}
+
+	utils.RaiseIfError(t, backend.Put("expired", Entry{Value: []byte("1"), ExpiresAt: time.Now().Add(-time.Second)}))
+	utils.RaiseIfError(t, backend.Put("valid", Entry{Value: []byte("2"), ExpiresAt: time.Now().Add(time.Hour)}))
+	entry, err = backend.Get("expired")
+	utils.RaiseIfError(t, err)
+	if entry != nil {
+		t.Errorf("expired entries should be dropped when the file is written")
+	}
+
+	utils.RaiseIfError(t, backend.Delete("valid"))
+	entry, err = backend.Get("valid")
+	utils.RaiseIfError(t, err)
+	if entry != nil {
+		t.Errorf("deleted entry should not be returned")
+	}
+}
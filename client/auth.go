@@ -25,7 +25,7 @@ type AuthenticationResponse struct {
 	Scope        string `json:"scope"`
 }
 
-var tokenCache = cache.New()
+var tokenCache = cache.New(cache.WithMaxSize(cacheMaxSize), cache.WithJanitor(cacheJanitorInterval))
 
 // jsonStrictUnmarshall unmarshalls json into object, and returns an error
 // if some fields are missing, or extra fields are present
@@ -35,42 +35,62 @@ func jsonStrictUnmarshall(data []byte, v interface{}) error {
 	return decoder.Decode(v)
 }
 
+// usernamePasswordTokenSource authenticates with a username and a password
+type usernamePasswordTokenSource struct {
+	username    string
+	password    string
+	apiEndpoint string
+	userAgent   string
+	httpClient  *http.Client
+}
+
+// Token sends an authentication request with the username and password
+func (s *usernamePasswordTokenSource) Token(ctx context.Context) (*Token, error) {
+	loginUrl, contentType, body, err := prepareUsernamePasswordLogin(s.username, s.password)
+	if err != nil {
+		return nil, err
+	}
+	logging.Infolog.Printf("Start authentication into '%s' using '%s'", s.apiEndpoint, loginUrl)
+	resp := DoHttpRequest(s.httpClient, requestParameters{ctx, "", "POST", s.apiEndpoint + loginUrl, s.userAgent, nil, body, contentType, "", "", ""})
+	if resp.statusCode == http.StatusBadRequest || resp.statusCode == http.StatusForbidden {
+		return nil, errors.Wrap(errors.AuthenticationError, resp.err)
+	} else if resp.err != nil {
+		fmt.Printf("Status code: %d\n", resp.statusCode)
+		return nil, errors.ConstructNestedError("authentication request failed", resp.err)
+	}
+	return readAuthenticationResponse(resp)
+}
+
 // getAccessTokenUsernamePassword gets an access token from the cache when it is available in the cache or from the server when it is not available in the cache
-func getAccessTokenUsernamePassword(username string, password string, apiEndpoint string, userAgent string) (string, error) {
-	cachedToken := getCachedAccessToken(username, apiEndpoint)
-	if len(cachedToken) > 0 {
-		return cachedToken, nil
-	} else {
-		var loginUrl string
-		var contentType string
-		var body string
-		var err error
-		loginUrl, contentType, body, err = prepareUsernamePasswordLogin(username, password)
-		if err != nil {
-			return "", err
-		}
-		logging.Infolog.Printf("Start authentication into '%s' using '%s'", apiEndpoint, loginUrl)
-		resp := DoHttpRequest(nil, requestParameters{context.TODO(), "", "POST", apiEndpoint + loginUrl, userAgent, nil, body, contentType, ""})
-		if resp.statusCode == http.StatusBadRequest || resp.statusCode == http.StatusForbidden {
-			return "", errors.Wrap(errors.AuthenticationError, resp.err)
-		} else if resp.err != nil {
-			fmt.Printf("Status code: %d\n", resp.statusCode)
-			return "", errors.ConstructNestedError("authentication request failed", resp.err)
-		}
-
-		content, err := resp.Content()
-		if err != nil {
-			return "", errors.ConstructNestedError("error during reading response content", err)
-		}
-
-		var authResp AuthenticationResponse
-		if err = jsonStrictUnmarshall(content, &authResp); err != nil {
-			return "", errors.ConstructNestedError("failed to unmarshal authentication response with error", err)
-		}
-		logging.Infolog.Printf("Authentication was successful")
-		tokenCache.Put(getCacheKey(username, apiEndpoint), authResp.AccessToken, time.Duration(authResp.ExpiresIn)*time.Millisecond)
-		return authResp.AccessToken, nil
+func getAccessTokenUsernamePassword(ctx context.Context, httpClient *http.Client, username string, password string, apiEndpoint string, userAgent string) (string, error) {
+	return newUsernamePasswordTokenManager(tokenCache, httpClient, username, password, apiEndpoint, userAgent).accessToken(ctx)
+}
+
+// newUsernamePasswordTokenManager returns a manager of the tokens for a username and a password, shared by all clients using them
+// through tokens. The authentication requests are sent with httpClient.
+func newUsernamePasswordTokenManager(tokens *cache.Cache, httpClient *http.Client, username string, password string, apiEndpoint string, userAgent string) *tokenManager {
+	source := &usernamePasswordTokenSource{username: username, password: password, apiEndpoint: apiEndpoint, userAgent: userAgent, httpClient: httpClient}
+	return newSharedTokenManager(source, tokens, getCacheKey(username, apiEndpoint))
+}
+
+// readAuthenticationResponse parses the token and its expiry from an authentication response
+func readAuthenticationResponse(resp *Response) (*Token, error) {
+	content, err := resp.Content()
+	if err != nil {
+		return nil, errors.ConstructNestedError("error during reading response content", err)
 	}
+
+	var authResp AuthenticationResponse
+	if err = jsonStrictUnmarshall(content, &authResp); err != nil {
+		return nil, errors.ConstructNestedError("failed to unmarshal authentication response with error", err)
+	}
+	logging.Infolog.Printf("Authentication was successful")
+	token := &Token{AccessToken: authResp.AccessToken}
+	if authResp.ExpiresIn > 0 {
+		// expires_in is the lifetime of the token in seconds
+		token.Expiry = time.Now().Add(time.Duration(authResp.ExpiresIn) * time.Second)
+	}
+	return token, nil
 }
 
 // prepareUsernamePasswordLogin returns the loginUrl, contentType and body needed to query an access token using a username and a password
@@ -85,43 +105,42 @@ func prepareUsernamePasswordLogin(username string, password string) (string, str
 	}
 }
 
-// getAccessTokenServiceAccount gets an access token from the cache when it is available in the cache or from the server when it is not available in the cache
-func getAccessTokenServiceAccount(clientId string, clientSecret string, apiEndpoint string, userAgent string) (string, error) {
-	cachedToken := getCachedAccessToken(clientId, apiEndpoint)
-	if len(cachedToken) > 0 {
-		return cachedToken, nil
-	} else {
-		var loginUrl string
-		var contentType string
-		var body string
-		var err error
-
-		loginUrl, contentType, body = prepareServiceAccountLogin(clientId, clientSecret, AuthAudienceValue)
-		authEndpoint, err := getServiceAccountAuthEndpoint(apiEndpoint)
-		if err != nil {
-			return "", errors.ConstructNestedError("error building auth endpoint", err)
-		}
-		logging.Infolog.Printf("Start authentication into '%s' using '%s'", authEndpoint, loginUrl)
-		resp := DoHttpRequest(nil, requestParameters{context.TODO(), "", "POST", authEndpoint + loginUrl, userAgent, nil, body, contentType, ""})
-		if resp.statusCode == http.StatusUnauthorized {
-			return "", errors.Wrap(errors.AuthenticationError, resp.err)
-		} else if resp.err != nil {
-			return "", errors.ConstructNestedError("authentication request failed", resp.err)
-		}
-
-		content, err := resp.Content()
-		if err != nil {
-			return "", errors.ConstructNestedError("error during reading response content", err)
-		}
-
-		var authResp AuthenticationResponse
-		if err = jsonStrictUnmarshall(content, &authResp); err != nil {
-			return "", errors.ConstructNestedError("failed to unmarshal authentication response with error", err)
-		}
-		logging.Infolog.Printf("Authentication was successful")
-		tokenCache.Put(getCacheKey(clientId, apiEndpoint), authResp.AccessToken, time.Duration(authResp.ExpiresIn)*time.Millisecond)
-		return authResp.AccessToken, nil
+// serviceAccountTokenSource authenticates with the client ID and secret of a service account
+type serviceAccountTokenSource struct {
+	clientID     string
+	clientSecret string
+	apiEndpoint  string
+	userAgent    string
+	httpClient   *http.Client
+}
+
+// Token sends an authentication request with the client ID and secret
+func (s *serviceAccountTokenSource) Token(ctx context.Context) (*Token, error) {
+	loginUrl, contentType, body := prepareServiceAccountLogin(s.clientID, s.clientSecret, AuthAudienceValue)
+	authEndpoint, err := getServiceAccountAuthEndpoint(s.apiEndpoint)
+	if err != nil {
+		return nil, errors.ConstructNestedError("error building auth endpoint", err)
 	}
+	logging.Infolog.Printf("Start authentication into '%s' using '%s'", authEndpoint, loginUrl)
+	resp := DoHttpRequest(s.httpClient, requestParameters{ctx, "", "POST", authEndpoint + loginUrl, s.userAgent, nil, body, contentType, "", "", ""})
+	if resp.statusCode == http.StatusUnauthorized {
+		return nil, errors.Wrap(errors.AuthenticationError, resp.err)
+	} else if resp.err != nil {
+		return nil, errors.ConstructNestedError("authentication request failed", resp.err)
+	}
+	return readAuthenticationResponse(resp)
+}
+
+// getAccessTokenServiceAccount gets an access token from the cache when it is available in the cache or from the server when it is not available in the cache
+func getAccessTokenServiceAccount(ctx context.Context, httpClient *http.Client, clientId string, clientSecret string, apiEndpoint string, userAgent string) (string, error) {
+	return newServiceAccountTokenManager(tokenCache, httpClient, clientId, clientSecret, apiEndpoint, userAgent).accessToken(ctx)
+}
+
+// newServiceAccountTokenManager returns a manager of the tokens for a service account, shared by all clients using it
+// through tokens. The authentication requests are sent with httpClient.
+func newServiceAccountTokenManager(tokens *cache.Cache, httpClient *http.Client, clientId string, clientSecret string, apiEndpoint string, userAgent string) *tokenManager {
+	source := &serviceAccountTokenSource{clientID: clientId, clientSecret: clientSecret, apiEndpoint: apiEndpoint, userAgent: userAgent, httpClient: httpClient}
+	return newSharedTokenManager(source, tokens, getCacheKey(clientId, apiEndpoint))
 }
 
 // prepareServiceAccountLogin returns the loginUrl, contentType and body needed to query an access token using a client id and a client secret
@@ -165,9 +184,8 @@ func getCacheKey(clientID, apiEndpoint string) string {
 
 // getCachedAccessToken returns a cached access token or empty when a token could not be found
 func getCachedAccessToken(clientID, apiEndpoint string) string {
-	cachedToken := tokenCache.Get(getCacheKey(clientID, apiEndpoint))
-	if cachedToken != nil {
-		return fmt.Sprint(cachedToken)
+	if cached, ok := cache.Load[*cachedToken](tokenCache, getCacheKey(clientID, apiEndpoint)); ok && cached != nil && cached.Token != nil {
+		return cached.Token.AccessToken
 	}
 	return ""
 }
@@ -4,6 +4,7 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"math/rand"
 	"testing"
@@ -34,7 +35,7 @@ func randomString(length int) string {
 func testAuthWrongCredential(t *testing.T, newVersion bool) {
 	wrong_client_id := "wrong_client_id" + randomString(10)
 	wrong_secret := "wrong_secret" + randomString(10)
-	_, err := ClientFactory(&types.FireboltSettings{
+	_, err := ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     wrong_client_id,
 		ClientSecret: wrong_secret,
 		NewVersion:   newVersion,
@@ -48,7 +49,7 @@ func testAuthWrongCredential(t *testing.T, newVersion bool) {
 }
 
 func testAuthEmptyCredential(t *testing.T, newVersion bool) {
-	_, err := ClientFactory(&types.FireboltSettings{
+	_, err := ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     "test_auth_empty_credential",
 		ClientSecret: "",
 		NewVersion:   newVersion,
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
 
@@ -15,27 +16,109 @@ import (
 	"github.com/firebolt-db/firebolt-go-sdk/logging"
 )
 
-var urlCache = cache.New()
+// cacheMaxSize bounds the number of entries of the token and URL caches, which grow with the number of accounts and service accounts used
+const cacheMaxSize = 1000
+
+// cacheJanitorInterval is how often the expired entries of the token and URL caches are removed
+const cacheJanitorInterval = time.Minute
+
+// tokenCacheNamespace and urlCacheNamespace prefix the keys of the token and URL caches in their backend
+const (
+	tokenCacheNamespace = "firebolt/token/"
+	urlCacheNamespace   = "firebolt/url/"
+)
+
+var urlCache = cache.New(cache.WithMaxSize(cacheMaxSize), cache.WithJanitor(cacheJanitorInterval))
+
+// systemEngineURLResponse is the response of the system engine URL request, it is cached in urlCache
+type systemEngineURLResponse struct {
+	EngineUrl string `json:"engineUrl"`
+}
+
+// TokenCache returns the cache of the access tokens, shared by all clients of the process and keyed by
+// the client ID followed by the API endpoint. It can be used to read its stats or to invalidate tokens.
+func TokenCache() *cache.Cache {
+	return tokenCache
+}
+
+// URLCache returns the cache of the system engine URLs, shared by all clients of the process and keyed by
+// the URL of the system engine URL request, which starts with the API endpoint. It can be used to read its
+// stats or to flush the URLs after a topology change.
+func URLCache() *cache.Cache {
+	return urlCache
+}
+
+// InvalidateSystemEngineURL removes the cached system engine URL of the account, so that it is resolved
+// again on the next connection, e.g. after the account is moved to another region
+func InvalidateSystemEngineURL(apiEndpoint, accountName string) {
+	urlCache.Delete(fmt.Sprintf(apiEndpoint+EngineUrlByAccountName, accountName))
+}
+
+// SetCacheBackend makes the access token and system engine URL caches, which are shared by all clients
+// of the process, store their entries in backend as well. Other processes using the same backend then
+// reuse them instead of authenticating and resolving the system engine URL again.
+// The backend is the default of the connectors that don't set their own with the CacheBackend setting,
+// including the ones opened before the call. A nil backend keeps the caches in memory only.
+//
+//	backend, _ := cache.NewFileBackend(filepath.Join(os.TempDir(), "firebolt-cache"), secret)
+//	client.SetCacheBackend(backend)
+func SetCacheBackend(backend cache.Backend) {
+	tokenCache.SetBackend(backend, tokenCacheNamespace)
+	urlCache.SetBackend(backend, urlCacheNamespace)
+}
+
+// tokenCacheFor returns the token cache storing its entries in backend, or in the default backend if nil
+func tokenCacheFor(backend cache.Backend) *cache.Cache {
+	if backend == nil {
+		return tokenCache
+	}
+	return tokenCache.WithBackend(backend, tokenCacheNamespace)
+}
+
+// urlCacheFor returns the system engine URL cache storing its entries in backend, or in the default backend if nil
+func urlCacheFor(backend cache.Backend) *cache.Cache {
+	if backend == nil {
+		return urlCache
+	}
+	return urlCache.WithBackend(backend, urlCacheNamespace)
+}
 
 type ClientImpl struct {
 	ConnectedToSystemEngine bool
 	AccountName             string
 	BaseClient
+	// urls caches the system engine URLs, urlCache is used if nil
+	urls *cache.Cache
 }
 
 func MakeClient(settings *types.FireboltSettings, apiEndpoint string) (*ClientImpl, error) {
+	transport, err := NewTransport(settings)
+	if err != nil {
+		return nil, errorUtils.ConstructNestedError("error during configuring the transport", err)
+	}
 	client := &ClientImpl{
 		BaseClient: BaseClient{
-			ClientID:     settings.ClientID,
-			ClientSecret: settings.ClientSecret,
-			ApiEndpoint:  apiEndpoint,
-			UserAgent:    ConstructUserAgentString(),
-			HttpClient:   NewHttpClientWithTransport(settings.Transport),
+			ClientID:            settings.ClientID,
+			ClientSecret:        settings.ClientSecret,
+			ApiEndpoint:         apiEndpoint,
+			UserAgent:           ConstructUserAgentString(),
+			HttpClient:          NewHttpClientWithTransport(transport),
+			ResponseCompression: settings.ResponseCompression,
+			RequestCompression:  settings.RequestCompression,
 		},
 		AccountName: settings.AccountName,
+		urls:        urlCacheFor(settings.CacheBackend),
 	}
 	client.ParameterGetter = client.GetQueryParams
+	client.tokens = newServiceAccountTokenManager(tokenCacheFor(settings.CacheBackend), client.HttpClient, client.ClientID, client.ClientSecret, client.ApiEndpoint, client.UserAgent)
 	client.AccessTokenGetter = client.getAccessToken
+	if settings.ClientSideLB {
+		lbConfig, err := loadBalancingConfig(settings)
+		if err != nil {
+			return nil, err
+		}
+		client.EnableLoadBalancing(lbConfig)
+	}
 
 	return client, nil
 }
@@ -54,21 +137,15 @@ func constructParameters(databaseName string, queryParams map[string][]string) m
 func (c *ClientImpl) getSystemEngineURLAndParameters(ctx context.Context, accountName string, databaseName string) (string, map[string]string, error) {
 	logging.Infolog.Printf("Get system engine URL for account '%s'", accountName)
 
-	type SystemEngineURLResponse struct {
-		EngineUrl string `json:"engineUrl"`
-	}
-
 	url := fmt.Sprintf(c.ApiEndpoint+EngineUrlByAccountName, accountName)
-	if val := urlCache.Get(url); val != nil {
-		if systemEngineURLResponse, ok := val.(SystemEngineURLResponse); ok {
-			logging.Infolog.Printf("Resolved account %s to system engine URL %s from cache", accountName, systemEngineURLResponse.EngineUrl)
-			engineUrl, queryParams, err := splitEngineEndpoint(systemEngineURLResponse.EngineUrl)
-			if err != nil {
-				return "", nil, errorUtils.ConstructNestedError("error during splitting system engine URL", err)
-			}
-			parameters := constructParameters(databaseName, queryParams)
-			return engineUrl, parameters, nil
+	if cachedResponse, ok := cache.Load[systemEngineURLResponse](c.urlCache(), url); ok {
+		logging.Infolog.Printf("Resolved account %s to system engine URL %s from cache", accountName, cachedResponse.EngineUrl)
+		engineUrl, queryParams, err := splitEngineEndpoint(cachedResponse.EngineUrl)
+		if err != nil {
+			return "", nil, errorUtils.ConstructNestedError("error during splitting system engine URL", err)
 		}
+		parameters := constructParameters(databaseName, queryParams)
+		return engineUrl, parameters, nil
 	}
 
 	resp := c.requestWithAuthRetry(ctx, "GET", url, make(map[string]string), "")
@@ -84,12 +161,12 @@ func (c *ClientImpl) getSystemEngineURLAndParameters(ctx context.Context, accoun
 		return "", nil, errorUtils.ConstructNestedError("error during reading response content", err)
 	}
 
-	var systemEngineURLResponse SystemEngineURLResponse
-	if err := json.Unmarshal(content, &systemEngineURLResponse); err != nil {
+	var response systemEngineURLResponse
+	if err := json.Unmarshal(content, &response); err != nil {
 		return "", nil, errorUtils.ConstructNestedError("error during unmarshalling system engine URL response", errors.New(string(content)))
 	}
-	urlCache.Put(url, systemEngineURLResponse, 0)
-	engineUrl, queryParams, err := splitEngineEndpoint(systemEngineURLResponse.EngineUrl)
+	c.urlCache().Put(url, response, 0)
+	engineUrl, queryParams, err := splitEngineEndpoint(response.EngineUrl)
 	if err != nil {
 		return "", nil, errorUtils.ConstructNestedError("error during splitting system engine URL", err)
 	}
@@ -120,8 +197,19 @@ func (c *ClientImpl) GetQueryParams(ctx context.Context, setStatements map[strin
 	return params, nil
 }
 
-func (c *ClientImpl) getAccessToken() (string, error) {
-	return getAccessTokenServiceAccount(c.ClientID, c.ClientSecret, c.ApiEndpoint, c.UserAgent)
+// urlCache returns the cache of the system engine URLs of the client
+func (c *ClientImpl) urlCache() *cache.Cache {
+	if c.urls != nil {
+		return c.urls
+	}
+	return urlCache
+}
+
+func (c *ClientImpl) getAccessToken(ctx context.Context) (string, error) {
+	if c.tokens != nil {
+		return c.tokens.accessToken(ctx)
+	}
+	return getAccessTokenServiceAccount(ctx, c.HttpClient, c.ClientID, c.ClientSecret, c.ApiEndpoint, c.UserAgent)
 }
 
 func consumeCommandResponse(response *Response) error {
@@ -147,11 +235,12 @@ func consumeCommandResponse(response *Response) error {
 func (c *ClientImpl) GetConnectionParameters(ctx context.Context, engineName, databaseName string) (string, map[string]string, error) {
 	// Assume we are connected to a system engine in the beginning
 
-	engineURL, parameters, err := c.getSystemEngineURLAndParameters(context.Background(), c.AccountName, databaseName)
+	engineURL, parameters, err := c.getSystemEngineURLAndParameters(ctx, c.AccountName, databaseName)
 	if err != nil {
 		return "", nil, errorUtils.ConstructNestedError("error during getting system engine url", err)
 	}
 	c.ConnectedToSystemEngine = true
+	c.balanceEngineURL(engineURL)
 
 	control := ConnectionControl{
 		UpdateParameters: func(key, value string) {
@@ -42,8 +42,67 @@ type BaseClient struct {
 	UserAgent         string
 	HttpClient        *http.Client
 	ParameterGetter   func(context.Context, map[string]string) (map[string]string, error)
-	AccessTokenGetter func() (string, error)
+	AccessTokenGetter func(context.Context) (string, error)
 	URLResolver       *RoundRobinResolver // nil disables client-side load balancing
+	// balancer holds the resolvers of the engine URLs the client is connected to, nil unless EnableLoadBalancing is called
+	balancer *resolverCache
+	// ResponseCompression lists the compression algorithms to request for responses in the order
+	// of preference, nil leaves it to the transport, which transparently requests gzip
+	ResponseCompression []string
+	// RequestCompression is the algorithm used to compress large SQL bodies and batch uploads, empty disables it
+	RequestCompression string
+	// tokens manages the access tokens of the client, nil if they are provided by AccessTokenGetter alone
+	tokens *tokenManager
+}
+
+// EnableLoadBalancing makes the client balance the requests sent to the engine URLs it gets connected to,
+// e.g. the system engine URL or the engine URL of a USE ENGINE endpoint update, across the IP addresses
+// their hostnames resolve to. A resolver is built for each of these engine URLs.
+func (c *BaseClient) EnableLoadBalancing(config LoadBalancingConfig) {
+	c.balancer = &resolverCache{config: config}
+}
+
+// balanceEngineURL builds a resolver for the engine URL if load balancing is enabled
+func (c *BaseClient) balanceEngineURL(engineURL string) {
+	if c.balancer != nil {
+		c.balancer.add(MakeCanonicalUrl(engineURL))
+	}
+}
+
+// resolverFor returns the resolver of the engine URL, or nil if its requests aren't balanced
+func (c *BaseClient) resolverFor(rawURL string) *RoundRobinResolver {
+	canonical := MakeCanonicalUrl(rawURL)
+	if c.URLResolver != nil && canonical == c.URLResolver.originalURL.String() {
+		return c.URLResolver
+	}
+	if c.balancer != nil {
+		return c.balancer.get(canonical)
+	}
+	return nil
+}
+
+// SetTokenSource makes the client get its access tokens from source instead of the built-in authentication.
+// The tokens are cached, refreshed in the background before they expire and fetched again when rejected by the server.
+func (c *BaseClient) SetTokenSource(source TokenSource) {
+	c.tokens = newTokenManager(source)
+	c.AccessTokenGetter = c.tokens.accessToken
+}
+
+// Token returns the current access token of the client along with its expiry
+func (c *BaseClient) Token(ctx context.Context) (*Token, error) {
+	if c.tokens == nil {
+		return nil, errors.New("the access token of the client is not managed by the SDK")
+	}
+	return c.tokens.token(ctx)
+}
+
+// invalidateAccessToken drops the cached access token after it was rejected by the server
+func (c *BaseClient) invalidateAccessToken() {
+	if c.tokens != nil {
+		c.tokens.invalidate()
+		return
+	}
+	deleteAccessTokenFromCache(c.ClientID, c.ApiEndpoint)
 }
 
 // Close releases resources held by the client, including idle HTTP connections.
@@ -51,9 +110,19 @@ func (c *BaseClient) Close() error {
 	if c.HttpClient != nil {
 		c.HttpClient.CloseIdleConnections()
 	}
+	if c.URLResolver != nil {
+		c.URLResolver.closeIdleConnections()
+	}
+	if c.balancer != nil {
+		c.balancer.closeIdleConnections()
+	}
 	return nil
 }
 
+func (c *BaseClient) acceptEncoding() string {
+	return makeAcceptEncoding(c.ResponseCompression)
+}
+
 // ConnectionControl is a struct that holds methods for updating connection properties
 // it's passed to Query method to allow it to update connection parameters and engine URL
 type ConnectionControl struct {
@@ -121,6 +190,8 @@ func (c *BaseClient) handleUpdateEndpoint(updateEndpointRaw string, control Conn
 	}
 	// set engine URL as a full URL excluding query parameters
 	control.SetEngineURL(updateEndpoint)
+	// the resolvers are bound to an engine URL, build one for the new engine
+	c.balanceEngineURL(updateEndpoint)
 	// update client parameters with new parameters
 	for k, v := range newParameters {
 		control.UpdateParameters(k, v[0])
@@ -179,24 +250,39 @@ func (c *BaseClient) UploadBatch(ctx context.Context, engineUrl, sql string, pay
 	return resp, nil
 }
 
-// resolveURL returns the (possibly rewritten) URL and host override for the
-// next request. When no URLResolver is configured, the URL is returned as-is.
-// If the engine URL has been changed at runtime (e.g. via a
-// Firebolt-Update-Endpoint response header), the resolver is bypassed
-// because it is configured for the original hostname only.
-func (c *BaseClient) resolveURL(ctx context.Context, rawURL string) (string, string) {
-	if c.URLResolver == nil {
-		return rawURL, ""
-	}
-	if MakeCanonicalUrl(rawURL) != c.URLResolver.originalURL.String() {
-		return rawURL, ""
+// sendBalanced sends a request built by send with the HTTP client, URL and
+// host override to use. Requests to the engine URLs with a resolver, either
+// URLResolver or one built by EnableLoadBalancing, are balanced across the
+// addresses of the resolver; other requests are sent as they are.
+// The outcome of a balanced request is recorded in the health of the address
+// it was sent to, and a request that didn't reach the server is sent again
+// to another address.
+func (c *BaseClient) sendBalanced(ctx context.Context, rawURL string, send func(httpClient *http.Client, resolvedURL, hostOverride string) *Response) *Response {
+	resolver := c.resolverFor(rawURL)
+	if resolver == nil {
+		return send(c.HttpClient, rawURL, "")
 	}
-	resolved, originalHost, err := c.URLResolver.Next(ctx)
-	if err != nil {
-		logging.Infolog.Printf("client-side LB resolution failed, using original URL: %v", err)
-		return rawURL, ""
+	httpClient := resolver.client(c.HttpClient)
+	tried := make(map[string]bool)
+	var resp *Response
+	for {
+		ep, err := resolver.acquire(ctx, tried)
+		if err != nil {
+			if resp != nil {
+				return resp
+			}
+			logging.Infolog.Printf("client-side LB resolution failed, using original URL: %v", err)
+			return send(c.HttpClient, rawURL, "")
+		}
+		resp = send(httpClient, ep.url, ep.host)
+		outcome, retriable := classifyResponse(ctx, resp)
+		ep.done(outcome)
+		tried[ep.health.address] = true
+		if !retriable || len(tried) >= maxLBAttempts {
+			return resp
+		}
+		logging.Infolog.Printf("request to %s didn't reach the engine, retrying on another address: %v", ep.health.address, resp.err)
 	}
-	return resolved, originalHost
 }
 
 func (c *BaseClient) requestMultipartWithAuthRetry(ctx context.Context, url string, params map[string]string, sql string, payload BatchPayload, fileName, fileExt string) *Response {
@@ -204,29 +290,28 @@ func (c *BaseClient) requestMultipartWithAuthRetry(ctx context.Context, url stri
 		return MakeResponse(nil, 0, nil, errors.New("AccessTokenGetter is not set"))
 	}
 
-	resolvedURL, hostOverride := c.resolveURL(ctx, url)
-
-	accessToken, err := c.AccessTokenGetter()
+	accessToken, err := c.AccessTokenGetter(ctx)
 	if err != nil {
 		return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error while getting access token", err))
 	}
-	reader, err := payload.NewReader()
-	if err != nil {
-		return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error creating batch reader", err))
+	readerErrorMessage := "error creating batch reader"
+	send := func(httpClient *http.Client, resolvedURL, hostOverride string) *Response {
+		reader, err := payload.NewReader()
+		if err != nil {
+			return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError(readerErrorMessage, err))
+		}
+		return DoHttpRequestMultipart(httpClient, requestParametersMultipart{ctx, accessToken, resolvedURL, c.UserAgent, params, sql, reader, fileName, fileExt, hostOverride, c.acceptEncoding(), c.RequestCompression})
 	}
-	resp := DoHttpRequestMultipart(c.HttpClient, requestParametersMultipart{ctx, accessToken, resolvedURL, c.UserAgent, params, sql, reader, fileName, fileExt, hostOverride})
+	resp := c.sendBalanced(ctx, url, send)
 	if resp.statusCode == http.StatusUnauthorized {
-		deleteAccessTokenFromCache(c.ClientID, c.ApiEndpoint)
+		c.invalidateAccessToken()
 
-		accessToken, err = c.AccessTokenGetter()
+		accessToken, err = c.AccessTokenGetter(ctx)
 		if err != nil {
 			return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error while getting access token", err))
 		}
-		reader, err = payload.NewReader()
-		if err != nil {
-			return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error creating batch reader for retry", err))
-		}
-		resp = DoHttpRequestMultipart(c.HttpClient, requestParametersMultipart{ctx, accessToken, resolvedURL, c.UserAgent, params, sql, reader, fileName, fileExt, hostOverride})
+		readerErrorMessage = "error creating batch reader for retry"
+		resp = c.sendBalanced(ctx, url, send)
 		if resp.statusCode == http.StatusUnauthorized {
 			resp.err = errorUtils.Wrap(errorUtils.AuthorizationError, resp.err)
 		}
@@ -243,21 +328,22 @@ func (c *BaseClient) requestWithAuthRetry(ctx context.Context, method string, ur
 		return MakeResponse(nil, 0, nil, errors.New("AccessTokenGetter is not set"))
 	}
 
-	resolvedURL, hostOverride := c.resolveURL(ctx, url)
-
-	accessToken, err := c.AccessTokenGetter()
+	accessToken, err := c.AccessTokenGetter(ctx)
 	if err != nil {
 		return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error while getting access token", err))
 	}
-	resp := DoHttpRequest(c.HttpClient, requestParameters{ctx, accessToken, method, resolvedURL, c.UserAgent, params, bodyStr, ContentTypeJSON, hostOverride})
+	send := func(httpClient *http.Client, resolvedURL, hostOverride string) *Response {
+		return DoHttpRequest(httpClient, requestParameters{ctx, accessToken, method, resolvedURL, c.UserAgent, params, bodyStr, ContentTypeJSON, hostOverride, c.acceptEncoding(), c.RequestCompression})
+	}
+	resp := c.sendBalanced(ctx, url, send)
 	if resp.statusCode == http.StatusUnauthorized {
-		deleteAccessTokenFromCache(c.ClientID, c.ApiEndpoint)
+		c.invalidateAccessToken()
 
-		accessToken, err = c.AccessTokenGetter()
+		accessToken, err = c.AccessTokenGetter(ctx)
 		if err != nil {
 			return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error while getting access token", err))
 		}
-		resp = DoHttpRequest(c.HttpClient, requestParameters{ctx, accessToken, method, resolvedURL, c.UserAgent, params, bodyStr, ContentTypeJSON, hostOverride})
+		resp = c.sendBalanced(ctx, url, send)
 
 		if resp.statusCode == http.StatusUnauthorized {
 			resp.err = errorUtils.Wrap(errorUtils.AuthorizationError, resp.err)
@@ -2,7 +2,7 @@ package client
 
 import (
 	"context"
-	"net/url"
+	"errors"
 
 	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
 	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
@@ -16,39 +16,57 @@ type ClientImplEngine struct {
 }
 
 func MakeClientEngine(settings *types.FireboltSettings) (*ClientImplEngine, error) {
-	httpClient := NewHttpClientWithTransport(settings.Transport)
+	transport, err := NewTransport(settings)
+	if err != nil {
+		return nil, errorUtils.ConstructNestedError("error during configuring the transport", err)
+	}
 	var resolver *RoundRobinResolver
+	var lbConfig LoadBalancingConfig
 
 	if settings.ClientSideLB {
-		var err error
-		resolver, err = NewRoundRobinResolver(settings.Url, nil)
-		if err != nil {
+		if lbConfig, err = loadBalancingConfig(settings); err != nil {
 			return nil, err
 		}
-		if settings.DNSTTL > 0 {
-			resolver.TTL = settings.DNSTTL
+		switch {
+		case len(settings.EngineURLs) > 1:
+			resolver, err = NewStaticResolver(settings.EngineURLs)
+		case settings.SRVName != "":
+			var lookupSRV LookupSRVFunc
+			if settings.DNSServer != "" {
+				lookupSRV = dnsServerLookupSRV(settings.DNSServer)
+			}
+			resolver, err = NewSRVResolver(settings.Url, settings.SRVName, lookupSRV, lbConfig.lookupHost())
+		default:
+			resolver, err = NewRoundRobinResolver(settings.Url, lbConfig.lookupHost())
+		}
+		if err != nil {
+			return nil, err
 		}
-		// Use a TLS-aware client when the scheme is HTTPS, so certificate
-		// verification still works after the resolver rewrites the URL host
-		// to a raw IP address.
-		canonical := MakeCanonicalUrl(settings.Url)
-		if parsed, err := url.Parse(canonical); err == nil && parsed.Scheme == "https" {
-			httpClient = NewHttpClientForLBWithTransport(settings.Transport, parsed.Hostname())
+		if lbConfig.DNSTTL > 0 {
+			resolver.TTL = lbConfig.DNSTTL
 		}
-		logging.Infolog.Printf("client-side load balancing enabled for %s (DNS TTL: %s)", settings.Url, resolver.TTL)
+		resolver.Policy = lbConfig.Policy
+		logging.Infolog.Printf("client-side load balancing enabled for %s (DNS TTL: %s, policy: %s)", settings.Url, resolver.TTL, resolver.Policy)
+	} else if len(settings.EngineURLs) > 1 || settings.SRVName != "" {
+		return nil, errors.New("several engine URLs and SRV records require client-side load balancing")
 	}
 
 	client := &ClientImplEngine{
 		BaseClient: BaseClient{
-			ApiEndpoint: settings.Url,
-			UserAgent:   ConstructUserAgentString(),
-			HttpClient:  httpClient,
-			URLResolver: resolver,
+			ApiEndpoint:         settings.Url,
+			UserAgent:           ConstructUserAgentString(),
+			HttpClient:          NewHttpClientWithTransport(transport),
+			URLResolver:         resolver,
+			ResponseCompression: settings.ResponseCompression,
+			RequestCompression:  settings.RequestCompression,
 		},
 		AccountName: settings.AccountName,
 	}
 	client.ParameterGetter = client.GetQueryParams
 	client.AccessTokenGetter = client.getAccessToken
+	if settings.ClientSideLB {
+		client.EnableLoadBalancing(lbConfig)
+	}
 
 	return client, nil
 }
@@ -65,13 +83,16 @@ func (c *ClientImplEngine) GetQueryParams(ctx context.Context, setStatements map
 	if contextUtils.IsAsync(ctx) {
 		return nil, errorUtils.AsyncNotSupportedError
 	}
+	if contextUtils.IsDescribe(ctx) {
+		params["execution_mode"] = "describe_parameters"
+	}
 	for setKey, setValue := range setStatements {
 		params[setKey] = setValue
 	}
 	return params, nil
 }
 
-func (c *ClientImplEngine) getAccessToken() (string, error) {
+func (c *ClientImplEngine) getAccessToken(context.Context) (string, error) {
 	return "", nil // No access token needed for an Engine client.
 }
 
@@ -41,6 +41,20 @@ func TestAsyncQueryEngine(t *testing.T) {
 	}
 }
 
+func TestDescribeQueryEngine(t *testing.T) {
+	client := clientFactoryEngine("http://localhost:1234").(*ClientImplEngine)
+	params, err := client.GetQueryParams(contextUtils.WithDescribe(contextUtils.WithStreaming(context.Background())), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if params["execution_mode"] != "describe_parameters" {
+		t.Errorf("Expected describe_parameters execution mode, got %s", params["execution_mode"])
+	}
+	if params["output_format"] != jsonOutputFormat {
+		t.Errorf("Describe queries shouldn't be streamed, got output format %s", params["output_format"])
+	}
+}
+
 func TestGetConnectionParametersEngine(t *testing.T) {
 	apiEndpoint := "http://localhost:1234"
 	client := clientFactoryEngine(apiEndpoint)
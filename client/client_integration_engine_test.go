@@ -22,7 +22,7 @@ func init() {
 	engineUrlMock = "http://localhost:3473"
 	databaseMock = "integration_test_db"
 
-	client, _ := ClientFactory(&types.FireboltSettings{
+	client, _ := ClientFactory(context.TODO(), &types.FireboltSettings{
 		Database:   databaseMock,
 		Url:        engineUrlMock,
 		NewVersion: true,
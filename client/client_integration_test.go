@@ -34,7 +34,7 @@ func init() {
 	accountName = os.Getenv("ACCOUNT_NAME")
 
 	var err error
-	client, err := ClientFactory(&types.FireboltSettings{
+	client, err := ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     clientIdMock,
 		ClientSecret: clientSecretMock,
 		AccountName:  accountName,
@@ -46,7 +46,7 @@ func init() {
 		panic(fmt.Errorf("Error authenticating with client id %s: %v", clientIdMock, err))
 	}
 	clientMock = client.(*ClientImpl)
-	clientWithAccount, err := ClientFactory(&types.FireboltSettings{
+	clientWithAccount, err := ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     clientIdMock,
 		ClientSecret: clientSecretMock,
 		AccountName:  accountName,
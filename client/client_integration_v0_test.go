@@ -31,7 +31,7 @@ func init() {
 	engineUrlMock = os.Getenv("ENGINE_URL")
 	accountNameMock = os.Getenv("ACCOUNT_NAME")
 
-	client, err := ClientFactory(&types.FireboltSettings{
+	client, err := ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     usernameMock,
 		ClientSecret: passwordMock,
 		NewVersion:   false,
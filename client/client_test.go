@@ -2,17 +2,23 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/firebolt-db/firebolt-go-sdk/cache"
 	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
 
+	"github.com/firebolt-db/firebolt-go-sdk/types"
 	"github.com/firebolt-db/firebolt-go-sdk/utils"
 )
 
@@ -48,7 +54,7 @@ func TestCacheAccessToken(t *testing.T) {
 		utils.RaiseIfError(t, resp.err)
 	}
 
-	token, _ := getAccessTokenServiceAccount("client_id", "", server.URL, "")
+	token, _ := getAccessTokenServiceAccount(context.TODO(), nil, "client_id", "", server.URL, "")
 
 	if token != "aMysteriousToken" {
 		t.Error(missingTokenError)
@@ -111,7 +117,7 @@ func TestFetchTokenWhenExpired(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == ServiceAccountLoginURLSuffix {
 			fetchTokenCount++
-			_, _ = w.Write(utils.GetAuthResponse(10))
+			_, _ = w.Write(utils.GetAuthResponse(1))
 		} else {
 			w.WriteHeader(http.StatusOK)
 		}
@@ -124,11 +130,11 @@ func TestFetchTokenWhenExpired(t *testing.T) {
 	}
 	client.AccessTokenGetter = client.getAccessToken
 	_ = client.requestWithAuthRetry(context.TODO(), "GET", server.URL, nil, "")
-	// Waiting for the token to get expired
-	time.Sleep(15 * time.Millisecond)
+	// Waiting for the token to get expired, expires_in is in seconds
+	time.Sleep(time.Second)
 	_ = client.requestWithAuthRetry(context.TODO(), "GET", server.URL, nil, "")
 
-	token, _ := getAccessTokenServiceAccount("client_id", "", server.URL, "")
+	token, _ := getAccessTokenServiceAccount(context.TODO(), nil, "client_id", "", server.URL, "")
 
 	if token != "aMysteriousToken" {
 		t.Error(missingTokenError)
@@ -148,6 +154,62 @@ func TestFetchTokenWhenExpired(t *testing.T) {
 	}
 }
 
+// TestTokenExpiry tests that the expiry of a token is exposed, and that expires_in is in seconds
+func TestTokenExpiry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ServiceAccountLoginURLSuffix {
+			_, _ = w.Write(utils.GetAuthResponse(3600))
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	prepareEnvVariablesForTest(t, server)
+	client, err := MakeClient(&types.FireboltSettings{ClientID: "expiry_client_id", ClientSecret: "client_secret"}, server.URL)
+	utils.RaiseIfError(t, err)
+
+	token, err := client.Token(context.TODO())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token.AccessToken, "aMysteriousToken", t, "wrong access token")
+	if lifetime := time.Until(token.Expiry); lifetime < 59*time.Minute || lifetime > time.Hour {
+		t.Errorf("expected the token to expire in an hour, expires in %s", lifetime)
+	}
+}
+
+// TestTokenSingleFlight tests that concurrent requests with a cold cache authenticate once
+func TestTokenSingleFlight(t *testing.T) {
+	var fetchTokenCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ServiceAccountLoginURLSuffix {
+			atomic.AddInt32(&fetchTokenCount, 1)
+			time.Sleep(50 * time.Millisecond)
+			_, _ = w.Write(utils.GetAuthResponse(3600))
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	prepareEnvVariablesForTest(t, server)
+	var client = &ClientImpl{
+		BaseClient: BaseClient{ClientID: "single_flight_client_id", ClientSecret: "client_secret", ApiEndpoint: server.URL, UserAgent: "userAgent"},
+	}
+	client.AccessTokenGetter = client.getAccessToken
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := client.requestWithAuthRetry(context.TODO(), "GET", server.URL, nil, "")
+			if resp.err != nil {
+				t.Errorf("request failed: %v", resp.err)
+			}
+		}()
+	}
+	wg.Wait()
+	utils.AssertEqual(atomic.LoadInt32(&fetchTokenCount), int32(1), t, "the token should be fetched once")
+}
+
 // TestUserAgent tests that UserAgent is correctly set on request
 func TestUserAgent(t *testing.T) {
 	var userAgentValue = "userAgent"
@@ -259,6 +321,14 @@ func TestGetSystemEngineURLCaching(t *testing.T) {
 	if urlCalled != 1 {
 		t.Errorf("Expected to call the server only once, got %d", urlCalled)
 	}
+
+	// The URL is resolved again once invalidated
+	InvalidateSystemEngineURL(server.URL, testAccountName)
+	_, _, err = client.getSystemEngineURLAndParameters(context.Background(), testAccountName, "")
+	utils.RaiseIfError(t, err)
+	if urlCalled != 2 {
+		t.Errorf("Expected to call the server again after invalidation, got %d calls", urlCalled)
+	}
 }
 
 func TestUpdateEndpoint(t *testing.T) {
@@ -446,3 +516,157 @@ func TestConsumeCommandResponseRejectsServerErrorsAndMalformedJSON(t *testing.T)
 		t.Fatalf("consumeCommandResponse(success) error = %v", err)
 	}
 }
+
+// recordingRoundTripper records the paths of the requests sent through it
+type recordingRoundTripper struct {
+	paths sync.Map
+}
+
+func (rt *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.paths.Store(req.URL.Path, true)
+	return DefaultTransport().RoundTrip(req)
+}
+
+// TestAuthUsesConfiguredTransport tests that authentication and system engine URL resolution go through the configured transport
+func TestAuthUsesConfiguredTransport(t *testing.T) {
+	var authUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ServiceAccountLoginURLSuffix:
+			authUserAgent = r.Header.Get("User-Agent")
+			_, _ = w.Write(utils.GetAuthResponse(3600))
+		case fmt.Sprintf(EngineUrlByAccountName, "transport_account"):
+			_, _ = w.Write([]byte(`{"engineUrl": "http://` + r.Host + `"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	prepareEnvVariablesForTest(t, server)
+
+	transport := &recordingRoundTripper{}
+	settings := &types.FireboltSettings{
+		ClientID:     "transport_client_id",
+		ClientSecret: "client_secret",
+		AccountName:  "transport_account",
+		NewVersion:   true,
+		Transport:    transport,
+	}
+	cl, err := ClientFactory(context.TODO(), settings, server.URL)
+	utils.RaiseIfError(t, err)
+	_, _, err = cl.GetConnectionParameters(context.TODO(), "", "")
+	utils.RaiseIfError(t, err)
+
+	for _, path := range []string{ServiceAccountLoginURLSuffix, fmt.Sprintf(EngineUrlByAccountName, "transport_account")} {
+		if _, ok := transport.paths.Load(path); !ok {
+			t.Errorf("request to %s didn't go through the configured transport", path)
+		}
+	}
+	utils.AssertEqual(authUserAgent, cl.(*ClientImpl).UserAgent, t, "authentication should use the client user agent")
+}
+
+// TestAuthUsesCallerContext tests that authentication is bound by the caller's context
+func TestAuthUsesCallerContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write(utils.GetAuthResponse(3600))
+	}))
+	defer server.Close()
+	defer close(release)
+	prepareEnvVariablesForTest(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	settings := &types.FireboltSettings{ClientID: "context_client_id", ClientSecret: "client_secret", NewVersion: true}
+	start := time.Now()
+	_, err := ClientFactory(ctx, settings, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("authentication wasn't cancelled with the context, took %s", elapsed)
+	}
+}
+
+// TestCacheBackendSharesTokens tests that a process reuses the token and system engine URL stored in the cache backend by another one
+func TestCacheBackendSharesTokens(t *testing.T) {
+	var fetchTokenCount, fetchURLCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ServiceAccountLoginURLSuffix:
+			atomic.AddInt32(&fetchTokenCount, 1)
+			_, _ = w.Write(utils.GetAuthResponse(3600))
+		case fmt.Sprintf(EngineUrlByAccountName, "backend_account"):
+			atomic.AddInt32(&fetchURLCount, 1)
+			_, _ = w.Write([]byte(`{"engineUrl": "http://` + r.Host + `"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	prepareEnvVariablesForTest(t, server)
+
+	backend, err := cache.NewFileBackend(filepath.Join(t.TempDir(), "cache"), []byte("secret"))
+	utils.RaiseIfError(t, err)
+	originalTokenCache, originalURLCache := tokenCache, urlCache
+	t.Cleanup(func() { tokenCache, urlCache = originalTokenCache, originalURLCache })
+
+	settings := &types.FireboltSettings{ClientID: "backend_client_id", ClientSecret: "client_secret", AccountName: "backend_account", NewVersion: true}
+	for i := 0; i < 2; i++ {
+		// every iteration simulates a new process with empty in-memory caches
+		tokenCache, urlCache = cache.New(), cache.New()
+		SetCacheBackend(backend)
+		cl, err := ClientFactory(context.TODO(), settings, server.URL)
+		utils.RaiseIfError(t, err)
+		_, _, err = cl.GetConnectionParameters(context.TODO(), "", "")
+		utils.RaiseIfError(t, err)
+	}
+	utils.AssertEqual(atomic.LoadInt32(&fetchTokenCount), int32(1), t, "the token should be loaded from the backend")
+	utils.AssertEqual(atomic.LoadInt32(&fetchURLCount), int32(1), t, "the system engine URL should be loaded from the backend")
+}
+
+// TestCacheBackendPerClient tests that the cache backend of the settings replaces the default one for the client only
+func TestCacheBackendPerClient(t *testing.T) {
+	var fetchTokenCount, fetchURLCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ServiceAccountLoginURLSuffix:
+			atomic.AddInt32(&fetchTokenCount, 1)
+			_, _ = w.Write(utils.GetAuthResponse(3600))
+		case fmt.Sprintf(EngineUrlByAccountName, "connector_account"):
+			atomic.AddInt32(&fetchURLCount, 1)
+			_, _ = w.Write([]byte(`{"engineUrl": "http://` + r.Host + `"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	prepareEnvVariablesForTest(t, server)
+
+	backend, err := cache.NewFileBackend(filepath.Join(t.TempDir(), "cache"), []byte("secret"))
+	utils.RaiseIfError(t, err)
+	defaultBackend, err := cache.NewFileBackend(filepath.Join(t.TempDir(), "default"), []byte("secret"))
+	utils.RaiseIfError(t, err)
+	originalTokenCache, originalURLCache := tokenCache, urlCache
+	t.Cleanup(func() { tokenCache, urlCache = originalTokenCache, originalURLCache })
+
+	settings := &types.FireboltSettings{ClientID: "connector_client_id", ClientSecret: "client_secret", AccountName: "connector_account", NewVersion: true, CacheBackend: backend}
+	for i := 0; i < 2; i++ {
+		// every iteration simulates a new process with empty in-memory caches
+		tokenCache, urlCache = cache.New(), cache.New()
+		SetCacheBackend(defaultBackend)
+		cl, err := ClientFactory(context.TODO(), settings, server.URL)
+		utils.RaiseIfError(t, err)
+		_, _, err = cl.GetConnectionParameters(context.TODO(), "", "")
+		utils.RaiseIfError(t, err)
+	}
+	utils.AssertEqual(atomic.LoadInt32(&fetchTokenCount), int32(1), t, "the token should be loaded from the backend of the client")
+	utils.AssertEqual(atomic.LoadInt32(&fetchURLCount), int32(1), t, "the system engine URL should be loaded from the backend of the client")
+
+	entry, err := defaultBackend.Get(tokenCacheNamespace + getCacheKey(settings.ClientID, server.URL))
+	utils.RaiseIfError(t, err)
+	if entry != nil {
+		t.Errorf("the token should not be stored in the default backend")
+	}
+}
@@ -20,21 +20,31 @@ type ClientImplV0 struct {
 	BaseClient
 }
 
-func MakeClientV0(settings *types.FireboltSettings, apiEndpoint string) (*ClientImplV0, error) {
+func MakeClientV0(ctx context.Context, settings *types.FireboltSettings, apiEndpoint string) (*ClientImplV0, error) {
+	transport, err := NewTransport(settings)
+	if err != nil {
+		return nil, errorUtils.ConstructNestedError("error during configuring the transport", err)
+	}
 	client := &ClientImplV0{
 		BaseClient: BaseClient{
-			ClientID:     settings.ClientID,
-			ClientSecret: settings.ClientSecret,
-			ApiEndpoint:  apiEndpoint,
-			UserAgent:    ConstructUserAgentString(),
-			HttpClient:   NewHttpClientWithTransport(settings.Transport),
+			ClientID:            settings.ClientID,
+			ClientSecret:        settings.ClientSecret,
+			ApiEndpoint:         apiEndpoint,
+			UserAgent:           ConstructUserAgentString(),
+			HttpClient:          NewHttpClientWithTransport(transport),
+			ResponseCompression: settings.ResponseCompression,
+			RequestCompression:  settings.RequestCompression,
 		},
 	}
 	client.ParameterGetter = client.getQueryParams
+	client.tokens = newUsernamePasswordTokenManager(tokenCacheFor(settings.CacheBackend), client.HttpClient, client.ClientID, client.ClientSecret, client.ApiEndpoint, client.UserAgent)
 	client.AccessTokenGetter = client.getAccessToken
 
-	var err error
-	client.AccountID, err = client.GetAccountID(context.Background(), settings.AccountName)
+	if _, err := client.AccessTokenGetter(ctx); err != nil {
+		return nil, errorUtils.ConstructNestedError("error while getting access token", err)
+	}
+
+	client.AccountID, err = client.GetAccountID(ctx, settings.AccountName)
 	if err != nil {
 		return nil, errorUtils.ConstructNestedError("error during getting account id", err)
 	}
@@ -242,12 +252,18 @@ func (c *ClientImplV0) getQueryParams(ctx context.Context, setStatements map[str
 	if contextUtils.IsAsync(ctx) {
 		return nil, errorUtils.AsyncNotSupportedError
 	}
+	if contextUtils.IsDescribe(ctx) {
+		return nil, errorUtils.DescribeNotSupportedError
+	}
 	for setKey, setValue := range setStatements {
 		params[setKey] = setValue
 	}
 	return params, nil
 }
 
-func (c *ClientImplV0) getAccessToken() (string, error) {
-	return getAccessTokenUsernamePassword(c.ClientID, c.ClientSecret, c.ApiEndpoint, c.UserAgent)
+func (c *ClientImplV0) getAccessToken(ctx context.Context) (string, error) {
+	if c.tokens != nil {
+		return c.tokens.accessToken(ctx)
+	}
+	return getAccessTokenUsernamePassword(ctx, c.HttpClient, c.ClientID, c.ClientSecret, c.ApiEndpoint, c.UserAgent)
 }
@@ -44,7 +44,7 @@ func TestCacheAccessTokenV0(t *testing.T) {
 		}
 	}
 
-	token, _ := getAccessTokenUsernamePassword(mockClientId, "", server.URL, "")
+	token, _ := getAccessTokenUsernamePassword(context.TODO(), nil, mockClientId, "", server.URL, "")
 
 	if token != "aMysteriousToken" {
 		t.Error(missingTokenError)
@@ -122,11 +122,11 @@ func TestFetchTokenWhenExpiredV0(t *testing.T) {
 	}
 	client.AccessTokenGetter = client.getAccessToken
 	_ = client.requestWithAuthRetry(context.TODO(), "GET", server.URL, nil, "")
-	// Waiting for the token to get expired
-	time.Sleep(2 * time.Millisecond)
+	// Waiting for the token to get expired, expires_in is in seconds
+	time.Sleep(time.Second)
 	_ = client.requestWithAuthRetry(context.TODO(), "GET", server.URL, nil, "")
 
-	token, _ := getAccessTokenUsernamePassword(mockClientId, "", server.URL, "")
+	token, _ := getAccessTokenUsernamePassword(context.TODO(), nil, mockClientId, "", server.URL, "")
 
 	if token != "aMysteriousToken" {
 		t.Error(missingTokenError)
@@ -226,3 +226,29 @@ func TestAsyncQueryV0(t *testing.T) {
 		t.Errorf("Expected AsyncNotSupportedError, got: %v", err)
 	}
 }
+
+func TestDescribeQueryV0(t *testing.T) {
+	queried := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case UsernamePasswordURLSuffix:
+			_, _ = w.Write(utils.GetAuthResponse(10000))
+		default:
+			// the query must not be executed instead of being described
+			queried = true
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	prepareEnvVariablesForTest(t, server)
+	client := clientFactoryV0(server.URL)
+
+	_, err := client.Query(contextUtils.WithDescribe(context.Background()), server.URL, selectOne, map[string]string{}, ConnectionControl{})
+	if !errors.Is(err, errorUtils.DescribeNotSupportedError) {
+		t.Errorf("Expected DescribeNotSupportedError, got: %v", err)
+	}
+	if queried {
+		t.Errorf("Query shouldn't be sent to the server")
+	}
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressionGzip requests gzip compressed responses
+	CompressionGzip = "gzip"
+	// CompressionZstd requests zstd compressed responses
+	CompressionZstd = "zstd"
+	// CompressionNone disables response compression, including Go's transparent gzip
+	CompressionNone = "none"
+)
+
+const identityEncoding = "identity"
+
+// minRequestCompressionSize is the size of a SQL body below which compression isn't worth the overhead
+const minRequestCompressionSize = 1024
+
+// ParseCompression parses a comma separated list of compression algorithms in the order
+// of preference, e.g. "zstd,gzip". Returns an error for unsupported algorithms.
+func ParseCompression(value string) ([]string, error) {
+	var res []string
+	algorithms := strings.Split(value, ",")
+	for _, algorithm := range algorithms {
+		algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+		switch algorithm {
+		case CompressionGzip, CompressionZstd:
+			res = append(res, algorithm)
+		case CompressionNone:
+			if len(algorithms) > 1 {
+				return nil, fmt.Errorf("compression %s can't be combined with other algorithms", CompressionNone)
+			}
+			res = append(res, algorithm)
+		default:
+			return nil, fmt.Errorf("unsupported compression algorithm %q, expected one of %s, %s, %s", algorithm, CompressionZstd, CompressionGzip, CompressionNone)
+		}
+	}
+	return res, nil
+}
+
+// makeAcceptEncoding builds the Accept-Encoding header value for the compression algorithms.
+// An empty value leaves the header to the transport, which transparently requests gzip.
+func makeAcceptEncoding(compression []string) string {
+	if len(compression) == 1 && compression[0] == CompressionNone {
+		return identityEncoding
+	}
+	return strings.Join(compression, ", ")
+}
+
+// decompressingBody decompresses the response body as it is read
+type decompressingBody struct {
+	io.Reader
+	decoder io.Closer
+	body    io.ReadCloser
+}
+
+func (b *decompressingBody) Close() error {
+	var decoderErr error
+	if b.decoder != nil {
+		decoderErr = b.decoder.Close()
+	}
+	return errors.Join(decoderErr, b.body.Close())
+}
+
+type zstdDecoderCloser struct {
+	decoder *zstd.Decoder
+}
+
+func (c zstdDecoderCloser) Close() error {
+	c.decoder.Close()
+	return nil
+}
+
+// newDecompressingBody wraps body into a reader that decompresses it according to the
+// Content-Encoding of the response. Bodies without a known encoding are returned as is.
+func newDecompressingBody(body io.ReadCloser, contentEncoding string) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", identityEncoding:
+		return body, nil
+	case CompressionGzip:
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressingBody{Reader: reader, decoder: reader, body: body}, nil
+	case CompressionZstd:
+		// a single goroutine is enough since the body is read sequentially
+		decoder, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return &decompressingBody{Reader: decoder, decoder: zstdDecoderCloser{decoder}, body: body}, nil
+	}
+	return nil, fmt.Errorf("unsupported response content encoding %q", contentEncoding)
+}
+
+// newCompressor creates a writer that compresses data into w with the algorithm
+func newCompressor(w io.Writer, algorithm string) (io.WriteCloser, error) {
+	switch algorithm {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	}
+	return nil, fmt.Errorf("unsupported request compression algorithm %q, expected %s or %s", algorithm, CompressionZstd, CompressionGzip)
+}
+
+var (
+	bodyEncoderOnce sync.Once
+	bodyEncoder     *zstd.Encoder
+	bodyEncoderErr  error
+)
+
+// compressBody compresses a request body that fits into memory
+func compressBody(body string, algorithm string) ([]byte, error) {
+	if algorithm == CompressionZstd {
+		// zstd encoders are expensive to create, EncodeAll allows sharing one between requests
+		bodyEncoderOnce.Do(func() {
+			bodyEncoder, bodyEncoderErr = zstd.NewWriter(nil)
+		})
+		if bodyEncoderErr != nil {
+			return nil, bodyEncoderErr
+		}
+		return bodyEncoder.EncodeAll([]byte(body), nil), nil
+	}
+	var buf bytes.Buffer
+	compressor, err := newCompressor(&buf, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.WriteString(compressor, body); err != nil {
+		return nil, err
+	}
+	if err = compressor.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressingReader compresses src as it is read, so that streamed request bodies
+// are compressed chunk by chunk without buffering the whole payload
+type compressingReader struct {
+	src        io.Reader
+	buf        bytes.Buffer
+	compressor io.WriteCloser
+	chunk      []byte
+	done       bool
+}
+
+func newCompressingReader(src io.Reader, algorithm string) (*compressingReader, error) {
+	r := &compressingReader{src: src, chunk: make([]byte, 32*1024)}
+	var err error
+	if r.compressor, err = newCompressor(&r.buf, algorithm); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *compressingReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 && !r.done {
+		n, err := r.src.Read(r.chunk)
+		if n > 0 {
+			if _, writeErr := r.compressor.Write(r.chunk[:n]); writeErr != nil {
+				return 0, writeErr
+			}
+		}
+		if err == io.EOF {
+			if closeErr := r.compressor.Close(); closeErr != nil {
+				return 0, closeErr
+			}
+			r.done = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	if r.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return r.buf.Read(p)
+}
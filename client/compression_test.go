@@ -0,0 +1,226 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestParseCompression(t *testing.T) {
+	compression, err := ParseCompression(" ZSTD , gzip")
+	if err != nil || strings.Join(compression, ",") != "zstd,gzip" {
+		t.Errorf("ParseCompression() = %v, %v; want [zstd gzip]", compression, err)
+	}
+	for _, value := range []string{"lz4", "", "gzip,", "none,zstd"} {
+		if _, err := ParseCompression(value); err == nil {
+			t.Errorf("ParseCompression(%q) expected to fail", value)
+		}
+	}
+}
+
+func TestMakeAcceptEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		compression []string
+		expected    string
+	}{
+		{nil, ""},
+		{[]string{CompressionNone}, "identity"},
+		{[]string{CompressionZstd, CompressionGzip}, "zstd, gzip"},
+	} {
+		if actual := makeAcceptEncoding(tc.compression); actual != tc.expected {
+			t.Errorf("makeAcceptEncoding(%v) = %q; want %q", tc.compression, actual, tc.expected)
+		}
+	}
+}
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case CompressionGzip:
+		writer = gzip.NewWriter(&buf)
+	case CompressionZstd:
+		var err error
+		if writer, err = zstd.NewWriter(&buf); err != nil {
+			t.Fatalf("failed to create zstd writer: %v", err)
+		}
+	default:
+		return data
+	}
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("failed to compress data: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to compress data: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestQueryDecompressesResponse(t *testing.T) {
+	content := []byte(strings.Repeat(`{"message_type":"DATA","data":[[1]]}`+"\n", 1000))
+
+	for _, tc := range []struct {
+		compression    []string
+		acceptEncoding string
+		serverEncoding string
+	}{
+		{[]string{CompressionZstd, CompressionGzip}, "zstd, gzip", CompressionZstd},
+		{[]string{CompressionGzip}, "gzip", CompressionGzip},
+		{[]string{CompressionNone}, "identity", ""},
+		// Go transport requests gzip and decompresses it transparently by default
+		{nil, "gzip", CompressionGzip},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if actual := r.Header.Get("Accept-Encoding"); actual != tc.acceptEncoding {
+				t.Errorf("Accept-Encoding = %q; want %q", actual, tc.acceptEncoding)
+			}
+			if tc.serverEncoding != "" {
+				w.Header().Set("Content-Encoding", tc.serverEncoding)
+			}
+			_, _ = w.Write(compress(t, tc.serverEncoding, content))
+		}))
+
+		baseClient := &BaseClient{
+			HttpClient:          NewHttpClient(),
+			UserAgent:           "test-agent",
+			ResponseCompression: tc.compression,
+			AccessTokenGetter:   func(context.Context) (string, error) { return "token", nil },
+			ParameterGetter: func(context.Context, map[string]string) (map[string]string, error) {
+				return nil, nil
+			},
+		}
+		resp, err := baseClient.Query(context.Background(), server.URL, "SELECT 1", nil, ConnectionControl{})
+		if err != nil {
+			t.Fatalf("Query() failed for compression %v: %v", tc.compression, err)
+		}
+		actual, err := io.ReadAll(resp.Body())
+		if err != nil {
+			t.Fatalf("reading response failed for compression %v: %v", tc.compression, err)
+		}
+		if !bytes.Equal(actual, content) {
+			t.Errorf("decompressed response doesn't match for compression %v", tc.compression)
+		}
+		if err = resp.Body().Close(); err != nil {
+			t.Errorf("closing response failed for compression %v: %v", tc.compression, err)
+		}
+		server.Close()
+	}
+}
+
+func TestDecompressingBodyErrors(t *testing.T) {
+	body := &responseReadCloser{data: []byte("not compressed")}
+	if _, err := newDecompressingBody(body, "gzip"); err == nil {
+		t.Errorf("expected an error for an invalid gzip body")
+	}
+	if _, err := newDecompressingBody(body, "br"); err == nil {
+		t.Errorf("expected an error for an unsupported content encoding")
+	}
+}
+
+func decompress(t *testing.T, encoding string, body io.Reader) []byte {
+	reader, err := newDecompressingBody(io.NopCloser(body), encoding)
+	if err != nil {
+		t.Fatalf("failed to decompress request body: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to decompress request body: %v", err)
+	}
+	return data
+}
+
+func TestQueryCompressesLargeBody(t *testing.T) {
+	largeQuery := "SELECT * FROM t WHERE id IN (" + strings.Repeat("1, ", 1000) + "1)"
+
+	for _, algorithm := range []string{CompressionGzip, CompressionZstd} {
+		for _, query := range []string{"SELECT 1", largeQuery} {
+			expectedEncoding := ""
+			if len(query) >= minRequestCompressionSize {
+				expectedEncoding = algorithm
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoding := r.Header.Get("Content-Encoding")
+				if encoding != expectedEncoding {
+					t.Errorf("Content-Encoding = %q; want %q", encoding, expectedEncoding)
+				}
+				if body := decompress(t, encoding, r.Body); string(body) != query {
+					t.Errorf("request body doesn't match for %s compression", algorithm)
+				}
+			}))
+			baseClient := &BaseClient{
+				HttpClient:         NewHttpClient(),
+				RequestCompression: algorithm,
+				AccessTokenGetter:  func(context.Context) (string, error) { return "token", nil },
+				ParameterGetter: func(context.Context, map[string]string) (map[string]string, error) {
+					return nil, nil
+				},
+			}
+			resp, err := baseClient.Query(context.Background(), server.URL, query, nil, ConnectionControl{})
+			if err != nil {
+				t.Fatalf("Query() failed for %s compression: %v", algorithm, err)
+			}
+			_, _ = resp.Content()
+			server.Close()
+		}
+	}
+}
+
+type bytesPayload []byte
+
+func (p bytesPayload) NewReader() (io.Reader, error) {
+	return bytes.NewReader(p), nil
+}
+
+func TestUploadBatchCompressesPayload(t *testing.T) {
+	payload := bytes.Repeat([]byte("1,text\n"), 100000)
+
+	for _, algorithm := range []string{CompressionGzip, CompressionZstd} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if encoding := r.Header.Get("Content-Encoding"); encoding != algorithm {
+				t.Errorf("Content-Encoding = %q; want %q", encoding, algorithm)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(decompress(t, algorithm, r.Body)))
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatalf("failed to parse multipart body: %v", err)
+			}
+			if sql := r.FormValue("sql"); sql != "INSERT INTO t" {
+				t.Errorf("sql field = %q; want INSERT INTO t", sql)
+			}
+			file, _, err := r.FormFile("data")
+			if err != nil {
+				t.Fatalf("failed to read file field: %v", err)
+			}
+			if data, _ := io.ReadAll(file); !bytes.Equal(data, payload) {
+				t.Errorf("uploaded payload doesn't match for %s compression", algorithm)
+			}
+		}))
+		baseClient := &BaseClient{
+			HttpClient:         NewHttpClient(),
+			RequestCompression: algorithm,
+			AccessTokenGetter:  func(context.Context) (string, error) { return "token", nil },
+			ParameterGetter: func(context.Context, map[string]string) (map[string]string, error) {
+				return nil, nil
+			},
+		}
+		resp, err := baseClient.UploadBatch(context.Background(), server.URL, "INSERT INTO t", bytesPayload(payload), "data", ".csv", nil, ConnectionControl{})
+		if err != nil {
+			t.Fatalf("UploadBatch() failed for %s compression: %v", algorithm, err)
+		}
+		_, _ = resp.Content()
+		server.Close()
+	}
+}
+
+func TestRequestCompressionUnsupportedAlgorithm(t *testing.T) {
+	resp := DoHttpRequest(nil, requestParameters{ctx: context.Background(), method: "POST", url: "http://localhost", bodyStr: strings.Repeat("x", minRequestCompressionSize), compression: "lz4"})
+	if resp.err == nil {
+		t.Errorf("expected an error for an unsupported compression algorithm")
+	}
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// SRVRecord is a target of a SRV record
+type SRVRecord struct {
+	Target   string
+	Port     uint16
+	Priority uint16
+	Weight   uint16
+}
+
+// LookupSRVFunc returns the SRV records of name, a fully qualified domain name
+// such as _http._tcp.engine.default.svc.cluster.local.
+type LookupSRVFunc func(ctx context.Context, name string) ([]SRVRecord, error)
+
+const (
+	dnsPort    = "53"
+	dnsTimeout = 5 * time.Second
+)
+
+// ParseDNSServer parses the address of a DNS server, an IP address or hostname with an optional port, 53 by default
+func ParseDNSServer(value string) (string, error) {
+	if value == "" {
+		return "", errors.New("empty DNS server address")
+	}
+	if host, port, err := net.SplitHostPort(value); err == nil {
+		if host == "" {
+			return "", fmt.Errorf("missing host in DNS server address %q", value)
+		}
+		if _, err = net.LookupPort("udp", port); err != nil {
+			return "", fmt.Errorf("invalid port in DNS server address %q", value)
+		}
+		return value, nil
+	}
+	if strings.Contains(strings.Trim(value, "[]"), ":") && net.ParseIP(strings.Trim(value, "[]")) == nil {
+		return "", fmt.Errorf("invalid DNS server address %q", value)
+	}
+	return net.JoinHostPort(strings.Trim(value, "[]"), dnsPort), nil
+}
+
+// dnsServerResolver returns a resolver sending its queries to the DNS server at address
+func dnsServerResolver(address string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{Timeout: dnsTimeout}).DialContext(ctx, network, address)
+		},
+	}
+}
+
+// dnsServerLookupHost returns a LookupHostFunc resolving the hostnames with the DNS server at address
+func dnsServerLookupHost(address string) LookupHostFunc {
+	return dnsServerResolver(address).LookupHost
+}
+
+// dnsServerLookupSRV returns a LookupSRVFunc querying the DNS server at address
+func dnsServerLookupSRV(address string) LookupSRVFunc {
+	return resolverLookupSRV(dnsServerResolver(address))
+}
+
+// systemLookupSRV looks up the SRV records with net.DefaultResolver
+var systemLookupSRV = resolverLookupSRV(net.DefaultResolver)
+
+// resolverLookupSRV returns a LookupSRVFunc looking up the records with resolver
+func resolverLookupSRV(resolver *net.Resolver) LookupSRVFunc {
+	return func(ctx context.Context, name string) ([]SRVRecord, error) {
+		_, addrs, err := resolver.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		records := make([]SRVRecord, 0, len(addrs))
+		for _, addr := range addrs {
+			records = append(records, SRVRecord{Target: addr.Target, Port: addr.Port, Priority: addr.Priority, Weight: addr.Weight})
+		}
+		return records, nil
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+const (
+	// testSRVName is fully qualified so that the search domains of the system aren't appended to it
+	testSRVName = "_firebolt._tcp.example.com."
+	dnsTypeA    = 1
+	dnsTypeSRV  = 33
+	dnsClassIN  = 1
+	dnsTestTTL  = 30
+)
+
+// dnsAnswer builds the answer to query. SRV questions are answered with the SRV records, whose targets are
+// compressed against the example.com suffix of the question, A questions with 127.0.0.1, and other questions
+// with no records.
+func dnsAnswer(query []byte, truncated bool, rcode uint16, records ...SRVRecord) []byte {
+	questionEnd := 12
+	for query[questionEnd] != 0 {
+		questionEnd += int(query[questionEnd]) + 1
+	}
+	questionEnd += 5
+	questionType := binary.BigEndian.Uint16(query[questionEnd-4:])
+
+	flags := uint16(0x8180) | rcode
+	if truncated {
+		flags |= 0x0200
+		records = nil
+	}
+	numAnswers := 0
+	switch {
+	case questionType == dnsTypeSRV:
+		numAnswers = len(records)
+	case questionType == dnsTypeA && rcode == 0:
+		numAnswers = 1
+	}
+	answer := append([]byte{}, query[:2]...)
+	answer = binary.BigEndian.AppendUint16(answer, flags)
+	answer = binary.BigEndian.AppendUint16(answer, 1)
+	answer = binary.BigEndian.AppendUint16(answer, uint16(numAnswers))
+	answer = append(answer, 0, 0, 0, 0)
+	answer = append(answer, query[12:questionEnd]...)
+	if questionType == dnsTypeA && numAnswers > 0 {
+		answer = append(answer, 0xc0, 12)
+		answer = binary.BigEndian.AppendUint16(answer, dnsTypeA)
+		answer = binary.BigEndian.AppendUint16(answer, dnsClassIN)
+		answer = binary.BigEndian.AppendUint32(answer, dnsTestTTL)
+		answer = binary.BigEndian.AppendUint16(answer, 4)
+		return append(answer, 127, 0, 0, 1)
+	}
+	if questionType != dnsTypeSRV {
+		return answer
+	}
+	for _, record := range records {
+		// the name of the record points to the question, and the target to its example.com suffix
+		answer = append(answer, 0xc0, 12)
+		answer = binary.BigEndian.AppendUint16(answer, dnsTypeSRV)
+		answer = binary.BigEndian.AppendUint16(answer, dnsClassIN)
+		answer = binary.BigEndian.AppendUint32(answer, dnsTestTTL)
+		answer = binary.BigEndian.AppendUint16(answer, uint16(6+1+len(record.Target)+2))
+		answer = binary.BigEndian.AppendUint16(answer, record.Priority)
+		answer = binary.BigEndian.AppendUint16(answer, record.Weight)
+		answer = binary.BigEndian.AppendUint16(answer, record.Port)
+		answer = append(answer, byte(len(record.Target)))
+		answer = append(answer, record.Target...)
+		answer = append(answer, 0xc0, byte(12+len("_firebolt")+1+len("_tcp")+1))
+	}
+	return answer
+}
+
+// startDNSServer serves the SRV records and the 127.0.0.1 address of every host over UDP and TCP,
+// the UDP answers are truncated if truncated is set
+func startDNSServer(t *testing.T, truncated bool, rcode uint16, records ...SRVRecord) string {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	utils.RaiseIfError(t, err)
+	t.Cleanup(func() { _ = udp.Close() })
+	tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+	if err != nil {
+		t.Skipf("unable to listen on %s over TCP: %v", udp.LocalAddr(), err)
+	}
+	t.Cleanup(func() { _ = tcp.Close() })
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, addr, err := udp.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			_, _ = udp.WriteTo(dnsAnswer(buffer[:n], truncated, rcode, records...), addr)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcp.Accept()
+			if err != nil {
+				return
+			}
+			length := make([]byte, 2)
+			if _, err = io.ReadFull(conn, length); err == nil {
+				query := make([]byte, binary.BigEndian.Uint16(length))
+				if _, err = io.ReadFull(conn, query); err == nil {
+					answer := dnsAnswer(query, false, rcode, records...)
+					_, _ = conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(answer))), answer...))
+				}
+			}
+			_ = conn.Close()
+		}
+	}()
+	return udp.LocalAddr().String()
+}
+
+func TestParseDNSServer(t *testing.T) {
+	for value, expected := range map[string]string{
+		"10.0.0.10":            "10.0.0.10:53",
+		"10.0.0.10:5353":       "10.0.0.10:5353",
+		"dns.example.com":      "dns.example.com:53",
+		"fd00::10":             "[fd00::10]:53",
+		"[fd00::10]":           "[fd00::10]:53",
+		"[fd00::10]:5353":      "[fd00::10]:5353",
+		"dns.example.com:5353": "dns.example.com:5353",
+	} {
+		server, err := ParseDNSServer(value)
+		utils.RaiseIfError(t, err)
+		utils.AssertEqual(server, expected, t, "wrong DNS server address for "+value)
+	}
+	for _, value := range []string{"", ":53", "10.0.0.10:port", "a:b:c"} {
+		if _, err := ParseDNSServer(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestDNSServerLookupSRV(t *testing.T) {
+	expected := []SRVRecord{
+		{Target: "node-1.example.com.", Port: 3473, Priority: 10, Weight: 60},
+		{Target: "node-2.example.com.", Port: 3474, Priority: 20, Weight: 0},
+	}
+	for _, truncated := range []bool{false, true} {
+		server := startDNSServer(t, truncated, 0,
+			SRVRecord{Target: "node-1", Port: 3473, Priority: 10, Weight: 60},
+			SRVRecord{Target: "node-2", Port: 3474, Priority: 20, Weight: 0})
+
+		records, err := dnsServerLookupSRV(server)(context.Background(), testSRVName)
+		utils.RaiseIfError(t, err)
+		utils.AssertEqual(len(records), len(expected), t, "wrong number of records")
+		for i := range expected {
+			utils.AssertEqual(records[i], expected[i], t, "wrong record")
+		}
+	}
+}
+
+func TestDNSServerLookupSRVErrors(t *testing.T) {
+	server := startDNSServer(t, false, 3)
+	_, err := dnsServerLookupSRV(server)(context.Background(), testSRVName)
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	server = startDNSServer(t, false, 2)
+	if _, err = dnsServerLookupSRV(server)(context.Background(), testSRVName); err == nil {
+		t.Error("expected an error for a server failure")
+	}
+
+	if _, err = dnsServerLookupSRV(server)(context.Background(), "invalid..name"); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+}
+
+// TestSRVResolverWithDNSServer checks that an engine client looks up the SRV records and resolves their targets
+// with the DNS server of the settings, without lookup functions injected by the test
+func TestSRVResolverWithDNSServer(t *testing.T) {
+	server := startDNSServer(t, false, 0,
+		SRVRecord{Target: "node-1", Port: 3473, Priority: 10, Weight: 1},
+		SRVRecord{Target: "node-2", Port: 3474, Priority: 10, Weight: 1})
+
+	client, err := MakeClientEngine(&types.FireboltSettings{
+		Url: "http://engine.example.com", SRVName: testSRVName, DNSServer: server, ClientSideLB: true, NewVersion: true})
+	utils.RaiseIfError(t, err)
+	seen := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		resolvedURL, host, err := client.URLResolver.Next(context.Background())
+		utils.RaiseIfError(t, err)
+		utils.AssertEqual(host, "engine.example.com", t, "the requests should keep the original host")
+		seen[resolvedURL]++
+	}
+	for _, expectedURL := range []string{"http://127.0.0.1:3473", "http://127.0.0.1:3474"} {
+		utils.AssertEqual(seen[expectedURL], 2, t, "the requests should be balanced across the targets of the records")
+	}
+}
@@ -1,30 +1,61 @@
 package client
 
 import (
+	"context"
+	stdErrors "errors"
+
 	"github.com/firebolt-db/firebolt-go-sdk/errors"
 	"github.com/firebolt-db/firebolt-go-sdk/types"
 )
 
-// ClientFactory sends an authentication request, and returns a newly constructed client object
-func ClientFactory(settings *types.FireboltSettings, apiEndpoint string) (Client, error) {
-	userAgent := ConstructUserAgentString()
-
+// ClientFactory sends an authentication request, and returns a newly constructed client object.
+// The authentication and discovery requests are sent with the configured transport and are bound by ctx.
+func ClientFactory(ctx context.Context, settings *types.FireboltSettings, apiEndpoint string) (Client, error) {
 	if settings.NewVersion {
 		if settings.Url != "" {
 			return MakeClientEngine(settings)
 		}
-		_, err := getAccessTokenServiceAccount(settings.ClientID, settings.ClientSecret, apiEndpoint, userAgent)
+		client, err := MakeClient(settings, apiEndpoint)
 		if err != nil {
+			return nil, err
+		}
+		if _, err = client.AccessTokenGetter(ctx); err != nil {
 			return nil, errors.ConstructNestedError("error while getting access token", err)
-		} else {
-			return MakeClient(settings, apiEndpoint)
 		}
+		return client, nil
+	} else {
+		return MakeClientV0(ctx, settings, apiEndpoint)
+	}
+}
+
+// ClientFactoryWithTokenSource returns a newly constructed client object that gets its access tokens
+// from source instead of authenticating with the credentials in settings.
+// The first token is fetched eagerly, so that invalid token sources are reported right away.
+// Token sources are not supported with username and password authentication.
+func ClientFactoryWithTokenSource(ctx context.Context, settings *types.FireboltSettings, apiEndpoint string, source TokenSource) (Client, error) {
+	if !settings.NewVersion {
+		return nil, stdErrors.New("token sources are not supported with username and password authentication")
+	}
+
+	var client Client
+	var baseClient *BaseClient
+	if settings.Url != "" {
+		engineClient, err := MakeClientEngine(settings)
+		if err != nil {
+			return nil, err
+		}
+		client, baseClient = engineClient, &engineClient.BaseClient
 	} else {
-		_, err := getAccessTokenUsernamePassword(settings.ClientID, settings.ClientSecret, apiEndpoint, userAgent)
+		clientImpl, err := MakeClient(settings, apiEndpoint)
 		if err != nil {
-			return nil, errors.ConstructNestedError("error while getting access token", err)
-		} else {
-			return MakeClientV0(settings, apiEndpoint)
+			return nil, err
 		}
+		client, baseClient = clientImpl, &clientImpl.BaseClient
+	}
+
+	baseClient.SetTokenSource(source)
+	if _, err := baseClient.AccessTokenGetter(ctx); err != nil {
+		return nil, errors.ConstructNestedError("error while getting access token", err)
 	}
+	return client, nil
 }
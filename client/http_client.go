@@ -22,6 +22,11 @@ import (
 	"github.com/firebolt-db/firebolt-go-sdk/logging"
 )
 
+const (
+	defaultDialTimeout = 30 * time.Second
+	defaultKeepAlive   = 30 * time.Second
+)
+
 // DefaultTransport returns a new *http.Transport with the SDK's default
 // settings. Callers can customize the returned transport and pass it via
 // WithTransport when creating a connector.
@@ -36,8 +41,8 @@ func DefaultTransport() *http.Transport {
 	return &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
+			Timeout:   defaultDialTimeout,
+			KeepAlive: defaultKeepAlive,
 		}).DialContext,
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          100,
@@ -72,8 +77,9 @@ func NewHttpClientForLB(tlsServerName string) *http.Client {
 
 // NewHttpClientForLBWithTransport is like NewHttpClientForLB but uses the
 // given RoundTripper instead of DefaultTransport(). When rt is an
-// *http.Transport, TLSClientConfig.ServerName is set directly; otherwise
-// the caller is responsible for TLS configuration in their custom
+// *http.Transport, it is cloned and the ServerName of a clone of its
+// TLSClientConfig is set, so that CA bundles and client certificates are kept;
+// otherwise the caller is responsible for TLS configuration in their custom
 // RoundTripper. If rt is nil, DefaultTransport() is used.
 func NewHttpClientForLBWithTransport(rt http.RoundTripper, tlsServerName string) *http.Client {
 	if rt == nil {
@@ -81,7 +87,12 @@ func NewHttpClientForLBWithTransport(rt http.RoundTripper, tlsServerName string)
 	}
 	if tlsServerName != "" {
 		if t, ok := rt.(*http.Transport); ok {
-			t.TLSClientConfig = &tls.Config{ServerName: tlsServerName}
+			t = t.Clone()
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.ServerName = tlsServerName
+			rt = t
 		} else {
 			logging.Infolog.Printf("custom RoundTripper is not *http.Transport; skipping TLS ServerName override for %q", tlsServerName)
 		}
@@ -142,29 +153,33 @@ func (r *Response) IsAsyncResponse() bool {
 
 // Collect arguments for request function
 type requestParameters struct {
-	ctx          context.Context
-	accessToken  string
-	method       string
-	url          string
-	userAgent    string
-	params       map[string]string
-	bodyStr      string
-	contentType  string
-	hostOverride string // when non-empty, sent as the Host header (used by client-side LB)
+	ctx            context.Context
+	accessToken    string
+	method         string
+	url            string
+	userAgent      string
+	params         map[string]string
+	bodyStr        string
+	contentType    string
+	hostOverride   string // when non-empty, sent as the Host header (used by client-side LB)
+	acceptEncoding string // when non-empty, sent as the Accept-Encoding header and the response is decompressed
+	compression    string // when non-empty, bodies larger than minRequestCompressionSize are compressed with it
 }
 
 // requestParametersMultipart collects arguments for a multipart form upload.
 type requestParametersMultipart struct {
-	ctx          context.Context
-	accessToken  string
-	url          string
-	userAgent    string
-	params       map[string]string
-	sql          string
-	payload      io.Reader
-	fileName     string
-	fileExt      string // e.g. ".parquet"
-	hostOverride string // when non-empty, sent as the Host header (used by client-side LB)
+	ctx            context.Context
+	accessToken    string
+	url            string
+	userAgent      string
+	params         map[string]string
+	sql            string
+	payload        io.Reader
+	fileName       string
+	fileExt        string // e.g. ".parquet"
+	hostOverride   string // when non-empty, sent as the Host header (used by client-side LB)
+	acceptEncoding string // when non-empty, sent as the Accept-Encoding header and the response is decompressed
+	compression    string // when non-empty, the whole multipart body is compressed with it
 }
 
 // checkErrorResponse, checks whether error Response is returned instead of a desired Response.
@@ -207,6 +222,19 @@ func extractAdditionalHeaders(ctx context.Context) map[string]string {
 	return map[string]string{}
 }
 
+// makeHttpResponse wraps the HTTP response. If the compression was negotiated explicitly,
+// the transport doesn't decompress the body, so it is decompressed while being read.
+func makeHttpResponse(resp *http.Response, acceptEncoding string) *Response {
+	body := resp.Body
+	if len(acceptEncoding) > 0 {
+		var err error
+		if body, err = newDecompressingBody(resp.Body, resp.Header.Get("Content-Encoding")); err != nil {
+			return MakeResponse(nil, 0, nil, errors.Join(errorUtils.ConstructNestedError("error decompressing response", err), resp.Body.Close()))
+		}
+	}
+	return MakeResponse(body, resp.StatusCode, resp.Header, nil)
+}
+
 func resolveHttpClient(c *http.Client) *http.Client {
 	if c != nil {
 		return c
@@ -220,13 +248,28 @@ func resolveHttpClient(c *http.Client) *http.Client {
 // returns Response struct.
 // httpClient may be nil, in which case http.DefaultClient is used.
 func DoHttpRequest(httpClient *http.Client, reqParams requestParameters) *Response {
+	var body io.Reader = strings.NewReader(reqParams.bodyStr)
+	contentEncoding := ""
+	if len(reqParams.compression) > 0 && len(reqParams.bodyStr) >= minRequestCompressionSize {
+		compressed, err := compressBody(reqParams.bodyStr, reqParams.compression)
+		if err != nil {
+			return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error compressing request body", err))
+		}
+		body = bytes.NewReader(compressed)
+		contentEncoding = reqParams.compression
+	}
+
 	canonicalUrl := MakeCanonicalUrl(reqParams.url)
-	req, err := http.NewRequestWithContext(reqParams.ctx, reqParams.method, canonicalUrl, strings.NewReader(reqParams.bodyStr))
+	req, err := http.NewRequestWithContext(reqParams.ctx, reqParams.method, canonicalUrl, body)
 	if err != nil {
 		return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError(
 			fmt.Sprintf("error creating HTTP request: method=%s, url=%s", reqParams.method, canonicalUrl), err))
 	}
 
+	if len(contentEncoding) > 0 {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+
 	if reqParams.hostOverride != "" {
 		req.Host = reqParams.hostOverride
 	}
@@ -243,6 +286,10 @@ func DoHttpRequest(httpClient *http.Client, reqParams requestParameters) *Respon
 		req.Header.Set("Content-Type", reqParams.contentType)
 	}
 
+	if len(reqParams.acceptEncoding) > 0 {
+		req.Header.Set("Accept-Encoding", reqParams.acceptEncoding)
+	}
+
 	for key, value := range extractAdditionalHeaders(reqParams.ctx) {
 		req.Header.Set(key, value)
 	}
@@ -259,7 +306,7 @@ func DoHttpRequest(httpClient *http.Client, reqParams requestParameters) *Respon
 		return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error during a request execution", err))
 	}
 
-	return MakeResponse(resp.Body, resp.StatusCode, resp.Header, nil)
+	return makeHttpResponse(resp, reqParams.acceptEncoding)
 }
 
 // DoHttpRequestMultipart sends a multipart form POST with an "sql" text field
@@ -269,6 +316,8 @@ func DoHttpRequest(httpClient *http.Client, reqParams requestParameters) *Respon
 // byte slices and concatenated with the payload via io.MultiReader.  This
 // means the HTTP body is streamed directly from the blockReader — no pipe, no
 // goroutine, no extra copy.  Content-Length is not set (chunked transfer).
+// When compression is requested, the body is compressed chunk by chunk as the
+// transport reads it.
 // httpClient may be nil, in which case http.DefaultClient is used.
 func DoHttpRequestMultipart(httpClient *http.Client, reqParams requestParametersMultipart) *Response {
 	var prefix bytes.Buffer
@@ -289,7 +338,14 @@ func DoHttpRequestMultipart(httpClient *http.Client, reqParams requestParameters
 
 	suffix := []byte(fmt.Sprintf("\r\n--%s--\r\n", boundary))
 
-	body := io.MultiReader(&prefix, reqParams.payload, bytes.NewReader(suffix))
+	var body io.Reader = io.MultiReader(&prefix, reqParams.payload, bytes.NewReader(suffix))
+	if len(reqParams.compression) > 0 {
+		compressed, err := newCompressingReader(body, reqParams.compression)
+		if err != nil {
+			return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error compressing request body", err))
+		}
+		body = compressed
+	}
 
 	canonicalUrl := MakeCanonicalUrl(reqParams.url)
 	req, err := http.NewRequestWithContext(reqParams.ctx, "POST", canonicalUrl, body)
@@ -305,11 +361,18 @@ func DoHttpRequestMultipart(httpClient *http.Client, reqParams requestParameters
 	req.Header.Set("User-Agent", reqParams.userAgent)
 	req.Header.Set(protocolVersionHeader, protocolVersion)
 	req.Header.Set("Content-Type", fmt.Sprintf("multipart/form-data; boundary=%s", boundary))
+	if len(reqParams.compression) > 0 {
+		req.Header.Set("Content-Encoding", reqParams.compression)
+	}
 
 	if len(reqParams.accessToken) > 0 {
 		req.Header.Add("Authorization", "Bearer "+reqParams.accessToken)
 	}
 
+	if len(reqParams.acceptEncoding) > 0 {
+		req.Header.Set("Accept-Encoding", reqParams.acceptEncoding)
+	}
+
 	for key, value := range extractAdditionalHeaders(reqParams.ctx) {
 		req.Header.Set(key, value)
 	}
@@ -326,7 +389,7 @@ func DoHttpRequestMultipart(httpClient *http.Client, reqParams requestParameters
 		return MakeResponse(nil, 0, nil, errorUtils.ConstructNestedError("error during a request execution", err))
 	}
 
-	return MakeResponse(resp.Body, resp.StatusCode, resp.Header, nil)
+	return makeHttpResponse(resp, reqParams.acceptEncoding)
 }
 
 // MakeCanonicalUrl checks whether url starts with https:// and if not prepends it
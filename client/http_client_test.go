@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"io"
 	"net/http"
@@ -76,7 +78,7 @@ func TestQueryClosesBodyWhenResponseHeadersAreInvalid(t *testing.T) {
 	baseClient := &BaseClient{
 		HttpClient:        httpClient,
 		UserAgent:         "test-agent",
-		AccessTokenGetter: func() (string, error) { return "token", nil },
+		AccessTokenGetter: func(context.Context) (string, error) { return "token", nil },
 		ParameterGetter: func(context.Context, map[string]string) (map[string]string, error) {
 			return nil, nil
 		},
@@ -154,3 +156,21 @@ func TestDoHttpRequestMalformedURLWithPercent2(t *testing.T) {
 		t.Errorf("Expected error message to contain method 'POST', got: %s", errorMsg)
 	}
 }
+
+func TestHttpClientForLBKeepsTLSConfig(t *testing.T) {
+	roots := x509.NewCertPool()
+	transport := DefaultTransport()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+
+	httpClient := NewHttpClientForLBWithTransport(transport, "engine.example.com")
+	config := httpClient.Transport.(*http.Transport).TLSClientConfig
+	if config.ServerName != "engine.example.com" {
+		t.Errorf("expected ServerName engine.example.com, got %q", config.ServerName)
+	}
+	if config.RootCAs != roots {
+		t.Error("expected the root CAs of the transport to be kept")
+	}
+	if transport.TLSClientConfig.ServerName != "" {
+		t.Error("expected the transport passed in not to be modified")
+	}
+}
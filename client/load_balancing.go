@@ -0,0 +1,427 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/logging"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+)
+
+// LoadBalancingPolicy selects the IP address a request is sent to among the healthy ones
+type LoadBalancingPolicy string
+
+const (
+	// RoundRobin cycles through the IP addresses
+	RoundRobin LoadBalancingPolicy = "round_robin"
+	// LeastOutstanding picks the IP address with the fewest requests in flight
+	LeastOutstanding LoadBalancingPolicy = "least_outstanding"
+	// PowerOfTwoChoices picks the IP address with the fewest requests in flight among two random ones
+	PowerOfTwoChoices LoadBalancingPolicy = "power_of_two"
+)
+
+// ParseLoadBalancingPolicy parses the name of a load balancing policy: round_robin, least_outstanding or power_of_two
+func ParseLoadBalancingPolicy(value string) (LoadBalancingPolicy, error) {
+	switch policy := LoadBalancingPolicy(value); policy {
+	case RoundRobin, LeastOutstanding, PowerOfTwoChoices:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported load balancing policy %q, expected one of %s, %s, %s", value, RoundRobin, LeastOutstanding, PowerOfTwoChoices)
+	}
+}
+
+const (
+	defaultEjectionDuration    = time.Second
+	defaultMaxEjectionDuration = time.Minute
+	probeTimeout               = 5 * time.Second
+	// maxLBAttempts bounds the number of IP addresses a request that didn't reach the server is sent to
+	maxLBAttempts = 3
+)
+
+// ProbeFunc checks whether the engine at address, an IP address and port, accepts requests again
+type ProbeFunc func(ctx context.Context, address string) error
+
+// tcpProbe checks that a TCP connection can be established with address
+func tcpProbe(ctx context.Context, address string) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// endpointHealth is the health of an IP address, guarded by the healthMu of the resolver
+type endpointHealth struct {
+	// address is the IP address, or the host and port of the URL of a static resolver
+	address string
+	// outstanding is the number of requests in flight
+	outstanding int
+	// failures is the number of consecutive ejections, it drives the exponential cool-down
+	failures int
+	// ejectedUntil is the end of the cool-down of an ejected IP address, zero for healthy ones
+	ejectedUntil time.Time
+	// probing is set while the IP address is probed at the end of its cool-down
+	probing bool
+	// priority and weight are the ones of the SRV record of the IP address, 0 and 1 without SRV records
+	priority uint16
+	weight   int
+}
+
+func (h *endpointHealth) isEjected() bool {
+	return !h.ejectedUntil.IsZero()
+}
+
+// requestOutcome is what a response tells about the health of the IP address it was received from
+type requestOutcome int
+
+const (
+	// outcomeUnknown is for requests that failed before reaching the network or were cancelled by the caller
+	outcomeUnknown requestOutcome = iota
+	outcomeHealthy
+	outcomeUnhealthy
+)
+
+// endpoint is the IP address a request is sent to, done must be called once its response is received
+type endpoint struct {
+	resolver *RoundRobinResolver
+	health   *endpointHealth
+	url      string
+	host     string
+}
+
+func (e *endpoint) done(outcome requestOutcome) {
+	e.resolver.release(e.health, outcome)
+}
+
+// classifyResponse returns the outcome of a request sent to an IP address, and whether it can be safely
+// sent to another IP address because it didn't reach the server: only when the connection couldn't be
+// established, since a statement that reached the engine may have run even if it answered an error.
+// A 5xx status but 500, which is returned for failing queries, marks the IP address as unhealthy as well.
+func classifyResponse(ctx context.Context, resp *Response) (requestOutcome, bool) {
+	if ctx.Err() != nil {
+		return outcomeUnknown, false
+	}
+	if resp.statusCode == 0 {
+		var urlErr *url.Error
+		if resp.err == nil || !errors.As(resp.err, &urlErr) {
+			return outcomeUnknown, false
+		}
+		var opErr *net.OpError
+		return outcomeUnhealthy, errors.As(resp.err, &opErr) && opErr.Op == "dial"
+	}
+	if resp.statusCode > http.StatusInternalServerError {
+		return outcomeUnhealthy, false
+	}
+	return outcomeHealthy, false
+}
+
+// acquire selects the IP address of the next request among the healthy ones not in tried, with the policy
+// of the resolver. When all of them are ejected, the ejected ones are used rather than failing the request.
+func (r *RoundRobinResolver) acquire(ctx context.Context, tried map[string]bool) (*endpoint, error) {
+	ips, err := r.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	now := time.Now()
+	var healthy, ejected []*endpointHealth
+	for _, ip := range ips {
+		if tried[ip] {
+			continue
+		}
+		health := r.healthOf(ip)
+		if !health.isEjected() {
+			healthy = append(healthy, health)
+			continue
+		}
+		if !health.probing && now.After(health.ejectedUntil) {
+			health.probing = true
+			go r.probeEndpoint(health)
+		}
+		ejected = append(ejected, health)
+	}
+	candidates := healthy
+	if len(candidates) == 0 {
+		if len(ejected) == 0 {
+			return nil, fmt.Errorf("no IP address of %s left to try", r.originalHost)
+		}
+		logging.Infolog.Printf("all the IP addresses of %s are ejected, sending the request to an ejected one", r.originalHost)
+		candidates = ejected
+	}
+
+	health := r.pick(preferred(candidates))
+	health.outstanding++
+	resolvedURL, host := r.endpointURL(health.address)
+	return &endpoint{resolver: r, health: health, url: resolvedURL, host: host}, nil
+}
+
+// preferred returns the candidates of the lowest SRV priority, without the ones with a zero weight
+// unless they all have a zero weight
+func preferred(candidates []*endpointHealth) []*endpointHealth {
+	lowest := candidates[0].priority
+	for _, candidate := range candidates {
+		lowest = min(lowest, candidate.priority)
+	}
+	weighted := false
+	for _, candidate := range candidates {
+		weighted = weighted || (candidate.priority == lowest && candidate.weight > 0)
+	}
+	result := make([]*endpointHealth, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.priority == lowest && (candidate.weight > 0 || !weighted) {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// weightOf returns the weight of a preferred candidate, which all have a zero weight or none
+func weightOf(health *endpointHealth) int {
+	return max(health.weight, 1)
+}
+
+// lessLoaded returns whether a has fewer requests in flight than b relative to their weights
+func lessLoaded(a, b *endpointHealth) bool {
+	return (a.outstanding+1)*weightOf(b) < (b.outstanding+1)*weightOf(a)
+}
+
+// pick selects an IP address among candidates with the policy of the resolver, the caller must hold healthMu
+func (r *RoundRobinResolver) pick(candidates []*endpointHealth) *endpointHealth {
+	switch r.Policy {
+	case LeastOutstanding:
+		// start at a rotating offset, so that ties are broken in round-robin order
+		offset := int(r.counter.Add(1) - 1)
+		best := candidates[offset%len(candidates)]
+		for i := 1; i < len(candidates); i++ {
+			if candidate := candidates[(offset+i)%len(candidates)]; lessLoaded(candidate, best) {
+				best = candidate
+			}
+		}
+		return best
+	case PowerOfTwoChoices:
+		if len(candidates) == 1 {
+			return candidates[0]
+		}
+		first := rand.IntN(len(candidates))
+		second := rand.IntN(len(candidates) - 1)
+		if second >= first {
+			second++
+		}
+		if lessLoaded(candidates[second], candidates[first]) {
+			return candidates[second]
+		}
+		return candidates[first]
+	default:
+		if total, equal := totalWeight(candidates); !equal {
+			// weighted random selection, as described by RFC 2782
+			n := rand.IntN(total)
+			for _, candidate := range candidates {
+				if n -= weightOf(candidate); n < 0 {
+					return candidate
+				}
+			}
+		}
+		idx := r.counter.Add(1) - 1
+		return candidates[idx%uint64(len(candidates))]
+	}
+}
+
+// totalWeight returns the sum of the weights of candidates, and whether they are all equal
+func totalWeight(candidates []*endpointHealth) (int, bool) {
+	total, equal := 0, true
+	for _, candidate := range candidates {
+		total += weightOf(candidate)
+		equal = equal && weightOf(candidate) == weightOf(candidates[0])
+	}
+	return total, equal
+}
+
+// healthOf returns the health of ip, the caller must hold healthMu
+func (r *RoundRobinResolver) healthOf(ip string) *endpointHealth {
+	if r.health == nil {
+		r.health = make(map[string]*endpointHealth)
+	}
+	health, ok := r.health[ip]
+	if !ok {
+		health = &endpointHealth{address: ip, weight: 1}
+		r.health[ip] = health
+	}
+	return health
+}
+
+// pruneHealth forgets the health of the IP addresses no longer resolved
+func (r *RoundRobinResolver) pruneHealth(ips []string) {
+	resolved := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		resolved[ip] = true
+	}
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	for ip := range r.health {
+		if !resolved[ip] {
+			delete(r.health, ip)
+		}
+	}
+}
+
+// setTargets records the priority and weight of the SRV record of each address
+func (r *RoundRobinResolver) setTargets(targets map[string]SRVRecord) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	for address, record := range targets {
+		health := r.healthOf(address)
+		health.priority, health.weight = record.Priority, int(record.Weight)
+	}
+}
+
+// release records the outcome of a request sent to the IP address of health
+func (r *RoundRobinResolver) release(health *endpointHealth, outcome requestOutcome) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	health.outstanding--
+	switch outcome {
+	case outcomeHealthy:
+		if !health.isEjected() {
+			health.failures = 0
+		}
+	case outcomeUnhealthy:
+		if !health.isEjected() {
+			r.eject(health)
+		}
+	}
+}
+
+// eject stops sending requests to the IP address of health for a cool-down doubling with each
+// consecutive ejection, the caller must hold healthMu
+func (r *RoundRobinResolver) eject(health *endpointHealth) {
+	health.failures++
+	maxCoolDown := r.MaxEjectionDuration
+	if maxCoolDown <= 0 {
+		maxCoolDown = defaultMaxEjectionDuration
+	}
+	coolDown := r.EjectionDuration
+	if coolDown <= 0 {
+		coolDown = defaultEjectionDuration
+	}
+	for i := 1; i < health.failures && coolDown < maxCoolDown; i++ {
+		coolDown *= 2
+	}
+	coolDown = min(coolDown, maxCoolDown)
+	health.ejectedUntil = time.Now().Add(coolDown)
+	logging.Infolog.Printf("ejecting %s of %s for %s after %d consecutive failures", health.address, r.originalHost, coolDown, health.failures)
+}
+
+// probeEndpoint re-admits the IP address of health once it passes the probe, or ejects it again otherwise
+func (r *RoundRobinResolver) probeEndpoint(health *endpointHealth) {
+	probe := r.Probe
+	if probe == nil {
+		probe = tcpProbe
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	err := probe(ctx, r.probeAddress(health.address))
+
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	health.probing = false
+	if err != nil {
+		logging.Infolog.Printf("probe of %s of %s failed: %v", health.address, r.originalHost, err)
+		r.eject(health)
+		return
+	}
+	logging.Infolog.Printf("re-admitting %s of %s", health.address, r.originalHost)
+	health.ejectedUntil = time.Time{}
+}
+
+// LoadBalancingConfig configures the resolvers a client builds for the engine URLs it is connected to,
+// e.g. the system engine URL or the engine URL of a USE ENGINE endpoint update
+type LoadBalancingConfig struct {
+	// DNSTTL is how often the hostnames are resolved again, 30 seconds if zero
+	DNSTTL time.Duration
+	// Policy selects the IP address of each request, RoundRobin if empty
+	Policy LoadBalancingPolicy
+	// DNSServer is the address of the DNS server resolving the hostnames, the system resolver if empty
+	DNSServer string
+}
+
+// lookupHost returns the function resolving the hostnames, nil for the system resolver
+func (c LoadBalancingConfig) lookupHost() LookupHostFunc {
+	if c.DNSServer == "" {
+		return nil
+	}
+	return dnsServerLookupHost(c.DNSServer)
+}
+
+// loadBalancingConfig returns the load balancing configuration of settings
+func loadBalancingConfig(settings *types.FireboltSettings) (LoadBalancingConfig, error) {
+	config := LoadBalancingConfig{DNSTTL: settings.DNSTTL, Policy: RoundRobin, DNSServer: settings.DNSServer}
+	if settings.LBPolicy != "" {
+		var err error
+		if config.Policy, err = ParseLoadBalancingPolicy(settings.LBPolicy); err != nil {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+// maxResolvers bounds the number of engine URLs a client keeps resolvers for
+const maxResolvers = 32
+
+// resolverCache holds the resolvers built for the engine URLs of a client
+type resolverCache struct {
+	config    LoadBalancingConfig
+	mu        sync.Mutex
+	resolvers map[string]*RoundRobinResolver
+}
+
+// add builds a resolver for the engine URL, unless there is one already
+func (rc *resolverCache) add(canonicalURL string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, ok := rc.resolvers[canonicalURL]; ok {
+		return
+	}
+	resolver, err := NewRoundRobinResolver(canonicalURL, rc.config.lookupHost())
+	if err != nil {
+		logging.Infolog.Printf("client-side load balancing disabled for %s: %v", canonicalURL, err)
+		return
+	}
+	if rc.config.DNSTTL > 0 {
+		resolver.TTL = rc.config.DNSTTL
+	}
+	if rc.config.Policy != "" {
+		resolver.Policy = rc.config.Policy
+	}
+	if len(rc.resolvers) >= maxResolvers {
+		// the engine URLs of a client rarely change, start over rather than tracking their use
+		rc.resolvers = nil
+	}
+	if rc.resolvers == nil {
+		rc.resolvers = make(map[string]*RoundRobinResolver)
+	}
+	rc.resolvers[canonicalURL] = resolver
+	logging.Infolog.Printf("client-side load balancing enabled for %s (DNS TTL: %s, policy: %s)", canonicalURL, resolver.TTL, resolver.Policy)
+}
+
+func (rc *resolverCache) get(canonicalURL string) *RoundRobinResolver {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.resolvers[canonicalURL]
+}
+
+func (rc *resolverCache) closeIdleConnections() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, resolver := range rc.resolvers {
+		resolver.closeIdleConnections()
+	}
+}
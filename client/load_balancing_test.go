@@ -0,0 +1,341 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+// healthSnapshot returns the number of ejections and whether ip is ejected
+func healthSnapshot(r *RoundRobinResolver, ip string) (int, bool) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	health := r.healthOf(ip)
+	return health.failures, health.isEjected()
+}
+
+func acquireIPs(t *testing.T, r *RoundRobinResolver, n int) map[string]int {
+	seen := make(map[string]int)
+	for i := 0; i < n; i++ {
+		ep, err := r.acquire(context.Background(), nil)
+		utils.RaiseIfError(t, err)
+		seen[ep.health.address]++
+		ep.done(outcomeHealthy)
+	}
+	return seen
+}
+
+func TestParseLoadBalancingPolicy(t *testing.T) {
+	for _, policy := range []LoadBalancingPolicy{RoundRobin, LeastOutstanding, PowerOfTwoChoices} {
+		parsed, err := ParseLoadBalancingPolicy(string(policy))
+		utils.RaiseIfError(t, err)
+		utils.AssertEqual(parsed, policy, t, "wrong policy parsed")
+	}
+	if _, err := ParseLoadBalancingPolicy("random"); err == nil {
+		t.Error("expected an error for an unsupported policy")
+	}
+}
+
+func TestLoadBalancingEjectionAndProbe(t *testing.T) {
+	r, err := NewRoundRobinResolver("http://my-service:8080", staticLookup("10.0.0.1", "10.0.0.2"))
+	utils.RaiseIfError(t, err)
+	r.EjectionDuration = 20 * time.Millisecond
+	var probeSucceeds atomic.Bool
+	probed := make(chan string, 10)
+	r.Probe = func(_ context.Context, address string) error {
+		probed <- address
+		if !probeSucceeds.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	ep, err := r.acquire(context.Background(), map[string]bool{"10.0.0.2": true})
+	utils.RaiseIfError(t, err)
+	ep.done(outcomeUnhealthy)
+	failures, ejected := healthSnapshot(r, "10.0.0.1")
+	utils.AssertEqual(ejected, true, t, "IP should be ejected after a failure")
+	utils.AssertEqual(failures, 1, t, "wrong number of failures")
+
+	seen := acquireIPs(t, r, 4)
+	utils.AssertEqual(seen["10.0.0.2"], 4, t, "requests should only be sent to the healthy IP during the cool-down")
+
+	// at the end of the cool-down, the ejected IP is probed, and ejected again with a doubled cool-down if the probe fails
+	time.Sleep(30 * time.Millisecond)
+	acquireIPs(t, r, 1)
+	utils.AssertEqual(<-probed, "10.0.0.1:8080", t, "wrong probed address")
+	deadline := time.Now().Add(time.Second)
+	for failures, _ = healthSnapshot(r, "10.0.0.1"); failures < 2 && time.Now().Before(deadline); failures, _ = healthSnapshot(r, "10.0.0.1") {
+		time.Sleep(5 * time.Millisecond)
+	}
+	utils.AssertEqual(failures, 2, t, "IP should be ejected again after a failed probe")
+	r.healthMu.Lock()
+	coolDown := time.Until(r.healthOf("10.0.0.1").ejectedUntil)
+	r.healthMu.Unlock()
+	utils.AssertEqual(coolDown > 20*time.Millisecond, true, t, "cool-down should double after consecutive failures")
+
+	// the IP is re-admitted once it passes the probe
+	probeSucceeds.Store(true)
+	time.Sleep(50 * time.Millisecond)
+	acquireIPs(t, r, 1)
+	<-probed
+	for _, ejected = healthSnapshot(r, "10.0.0.1"); ejected && time.Now().Before(deadline); _, ejected = healthSnapshot(r, "10.0.0.1") {
+		time.Sleep(5 * time.Millisecond)
+	}
+	utils.AssertEqual(ejected, false, t, "IP should be re-admitted after a successful probe")
+	seen = acquireIPs(t, r, 4)
+	utils.AssertEqual(seen["10.0.0.1"], 2, t, "requests should be sent to the re-admitted IP")
+}
+
+func TestLoadBalancingAllEjected(t *testing.T) {
+	r, err := NewRoundRobinResolver("http://my-service:8080", staticLookup("10.0.0.1"))
+	utils.RaiseIfError(t, err)
+	ep, err := r.acquire(context.Background(), nil)
+	utils.RaiseIfError(t, err)
+	ep.done(outcomeUnhealthy)
+
+	// the ejected IP is used rather than failing the request
+	seen := acquireIPs(t, r, 1)
+	utils.AssertEqual(seen["10.0.0.1"], 1, t, "ejected IP should be used when all IPs are ejected")
+
+	_, err = r.acquire(context.Background(), map[string]bool{"10.0.0.1": true})
+	if err == nil {
+		t.Error("expected an error when all IPs were tried")
+	}
+}
+
+func TestLoadBalancingPolicies(t *testing.T) {
+	for _, policy := range []LoadBalancingPolicy{LeastOutstanding, PowerOfTwoChoices} {
+		t.Run(string(policy), func(t *testing.T) {
+			r, err := NewRoundRobinResolver("http://my-service:8080", staticLookup("10.0.0.1", "10.0.0.2"))
+			utils.RaiseIfError(t, err)
+			r.Policy = policy
+
+			// keep requests in flight on 10.0.0.1
+			for i := 0; i < 3; i++ {
+				_, err = r.acquire(context.Background(), map[string]bool{"10.0.0.2": true})
+				utils.RaiseIfError(t, err)
+			}
+			seen := acquireIPs(t, r, 10)
+			utils.AssertEqual(seen["10.0.0.2"], 10, t, "requests should be sent to the IP with fewer requests in flight")
+		})
+	}
+
+	r, err := NewRoundRobinResolver("http://my-service:8080", staticLookup("10.0.0.1", "10.0.0.2", "10.0.0.3"))
+	utils.RaiseIfError(t, err)
+	r.Policy = LeastOutstanding
+	seen := acquireIPs(t, r, 9)
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		utils.AssertEqual(seen[ip], 3, t, "ties should be broken in round-robin order")
+	}
+}
+
+func TestClassifyResponse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	utils.RaiseIfError(t, err)
+	closedAddress := listener.Addr().String()
+	utils.RaiseIfError(t, listener.Close())
+	dialResp := DoHttpRequest(NewHttpClient(), requestParameters{ctx: context.Background(), method: "POST", url: "http://" + closedAddress})
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, test := range map[string]struct {
+		ctx       context.Context
+		resp      *Response
+		outcome   requestOutcome
+		retriable bool
+	}{
+		"connection refused":  {context.Background(), dialResp, outcomeUnhealthy, true},
+		"service unavailable": {context.Background(), MakeResponse(nil, http.StatusServiceUnavailable, nil, nil), outcomeUnhealthy, false},
+		"bad gateway":         {context.Background(), MakeResponse(nil, http.StatusBadGateway, nil, nil), outcomeUnhealthy, false},
+		"query error":         {context.Background(), MakeResponse(nil, http.StatusInternalServerError, nil, nil), outcomeHealthy, false},
+		"bad request":         {context.Background(), MakeResponse(nil, http.StatusBadRequest, nil, nil), outcomeHealthy, false},
+		"success":             {context.Background(), MakeResponse(nil, http.StatusOK, nil, nil), outcomeHealthy, false},
+		"local error":         {context.Background(), MakeResponse(nil, 0, nil, errors.New("error creating batch reader")), outcomeUnknown, false},
+		"cancelled":           {cancelled, dialResp, outcomeUnknown, false},
+	} {
+		outcome, retriable := classifyResponse(test.ctx, test.resp)
+		utils.AssertEqual(outcome, test.outcome, t, "wrong outcome for "+name)
+		utils.AssertEqual(retriable, test.retriable, t, "wrong retriability for "+name)
+	}
+}
+
+// TestLoadBalancingFailover tests that requests failing to connect are sent to another IP, that requests
+// getting a 503 are not sent again since they reached the engine, and that the failing IPs are ejected
+func TestLoadBalancingFailover(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	utils.RaiseIfError(t, err)
+
+	// 127.0.0.2 answers with 503 on the same port, nothing listens on 127.0.0.3
+	unavailableListener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.2", port))
+	if err != nil {
+		t.Skipf("unable to listen on 127.0.0.2: %v", err)
+	}
+	var unavailableHits atomic.Int32
+	unavailable := &httptest.Server{
+		Listener: unavailableListener,
+		Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			unavailableHits.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})},
+	}
+	unavailable.Start()
+	defer unavailable.Close()
+
+	serviceURL := fmt.Sprintf("http://my-service:%s", port)
+	// in round-robin order, the first request is sent to 127.0.0.3, then to 127.0.0.2 and then to 127.0.0.1
+	resolver, err := NewRoundRobinResolver(serviceURL, staticLookup("127.0.0.3", "127.0.0.1", "127.0.0.2"))
+	utils.RaiseIfError(t, err)
+	resolver.Probe = func(context.Context, string) error { return errors.New("still down") }
+	engineClient := &ClientImplEngine{
+		BaseClient: BaseClient{ApiEndpoint: serviceURL, UserAgent: "test", HttpClient: NewHttpClient(), URLResolver: resolver},
+	}
+	engineClient.AccessTokenGetter = engineClient.getAccessToken
+	engineClient.ParameterGetter = engineClient.GetQueryParams
+
+	if _, err = engineClient.Query(context.Background(), serviceURL, selectOne, map[string]string{}, ConnectionControl{}); err == nil {
+		t.Errorf("expected the 503 to be returned")
+	}
+	utils.AssertEqual(hits.Load(), int32(0), t, "request that reached the unavailable IP should not be sent again")
+	utils.AssertEqual(unavailableHits.Load(), int32(1), t, "request should be retried on the unavailable IP after failing to connect")
+	for _, ip := range []string{"127.0.0.3", "127.0.0.2"} {
+		_, ejected := healthSnapshot(resolver, ip)
+		utils.AssertEqual(ejected, true, t, ip+" should be ejected")
+	}
+
+	for i := 0; i < 5; i++ {
+		_, err = engineClient.Query(context.Background(), serviceURL, selectOne, map[string]string{}, ConnectionControl{})
+		utils.RaiseIfError(t, err)
+	}
+	utils.AssertEqual(hits.Load(), int32(5), t, "requests should be sent to the healthy IP")
+	utils.AssertEqual(unavailableHits.Load(), int32(1), t, "requests should not be sent to the ejected IP")
+}
+
+// srvLookup returns the records, counting the lookups
+func srvLookup(lookups *atomic.Int32, records ...SRVRecord) LookupSRVFunc {
+	return func(_ context.Context, _ string) ([]SRVRecord, error) {
+		lookups.Add(1)
+		return records, nil
+	}
+}
+
+// hostLookup resolves the hostnames with the addresses of hosts
+func hostLookup(hosts map[string][]string) LookupHostFunc {
+	return func(_ context.Context, host string) ([]string, error) {
+		if ips, ok := hosts[host]; ok {
+			return ips, nil
+		}
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+}
+
+func TestSRVResolver(t *testing.T) {
+	var lookups atomic.Int32
+	r, err := NewSRVResolver("https://engine.example.com", "_firebolt._tcp.engine.example.com.", srvLookup(&lookups,
+		SRVRecord{Target: "node-1.example.com.", Port: 3473, Weight: 1},
+		SRVRecord{Target: "node-2.example.com.", Port: 3474, Weight: 1},
+		SRVRecord{Target: "unknown.example.com.", Port: 3475, Weight: 1},
+		SRVRecord{Target: ".", Port: 3476, Weight: 1},
+	), hostLookup(map[string][]string{
+		"node-1.example.com.": {"10.0.0.1", "10.0.0.2"},
+		"node-2.example.com.": {"10.0.0.3"},
+	}))
+	utils.RaiseIfError(t, err)
+
+	seen := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		resolvedURL, host, err := r.Next(context.Background())
+		utils.RaiseIfError(t, err)
+		utils.AssertEqual(host, "engine.example.com", t, "the requests should keep the original host")
+		seen[resolvedURL]++
+	}
+	for _, expectedURL := range []string{"https://10.0.0.1:3473", "https://10.0.0.2:3473", "https://10.0.0.3:3474"} {
+		utils.AssertEqual(seen[expectedURL], 2, t, "the requests should be balanced across the targets with their ports")
+	}
+	utils.AssertEqual(lookups.Load(), int32(1), t, "the records should be cached for the resolver TTL")
+	utils.AssertEqual(r.probeAddress("10.0.0.3:3474"), "10.0.0.3:3474", t, "wrong probe address")
+
+	if _, err = NewSRVResolver("https://engine.example.com", "", nil, nil); err == nil {
+		t.Error("expected an error for an empty SRV record name")
+	}
+}
+
+func TestSRVResolverTTL(t *testing.T) {
+	var lookups atomic.Int32
+	r, err := NewSRVResolver("http://engine.example.com", testSRVName, srvLookup(&lookups,
+		SRVRecord{Target: "node-1.example.com.", Port: 3473},
+	), hostLookup(map[string][]string{"node-1.example.com.": {"10.0.0.1"}}))
+	utils.RaiseIfError(t, err)
+	r.TTL = time.Hour
+	acquireIPs(t, r, 3)
+	utils.AssertEqual(lookups.Load(), int32(1), t, "the records should be cached for the resolver TTL")
+
+	r.TTL = 0
+	acquireIPs(t, r, 3)
+	utils.AssertEqual(lookups.Load(), int32(4), t, "the records should be looked up again after the resolver TTL")
+}
+
+func TestSRVPriorityAndWeight(t *testing.T) {
+	var lookups atomic.Int32
+	r, err := NewSRVResolver("http://engine.example.com", testSRVName, srvLookup(&lookups,
+		SRVRecord{Target: "heavy.example.com.", Port: 3473, Priority: 10, Weight: 90},
+		SRVRecord{Target: "light.example.com.", Port: 3473, Priority: 10, Weight: 10},
+		SRVRecord{Target: "unweighted.example.com.", Port: 3473, Priority: 10, Weight: 0},
+		SRVRecord{Target: "backup.example.com.", Port: 3473, Priority: 20, Weight: 100},
+	), hostLookup(map[string][]string{
+		"heavy.example.com.":      {"10.0.0.1"},
+		"light.example.com.":      {"10.0.0.2"},
+		"unweighted.example.com.": {"10.0.0.3"},
+		"backup.example.com.":     {"10.0.0.4"},
+	}))
+	utils.RaiseIfError(t, err)
+
+	seen := acquireIPs(t, r, 1000)
+	if seen["10.0.0.1:3473"] < 800 || seen["10.0.0.2:3473"] < 50 {
+		t.Errorf("the requests should be balanced in proportion to the weights, got %v", seen)
+	}
+	utils.AssertEqual(seen["10.0.0.3:3473"]+seen["10.0.0.4:3473"], 0, t, "the zero-weight and backup targets should not be used")
+
+	// the zero-weight target is used once the weighted ones are ejected, then the next priority
+	r.healthMu.Lock()
+	r.eject(r.healthOf("10.0.0.1:3473"))
+	r.eject(r.healthOf("10.0.0.2:3473"))
+	r.healthMu.Unlock()
+	r.Probe = func(context.Context, string) error { return errors.New("still down") }
+	utils.AssertEqual(acquireIPs(t, r, 2)["10.0.0.3:3473"], 2, t, "the zero-weight target should be used")
+	r.healthMu.Lock()
+	r.eject(r.healthOf("10.0.0.3:3473"))
+	r.healthMu.Unlock()
+	utils.AssertEqual(acquireIPs(t, r, 2)["10.0.0.4:3473"], 2, t, "the backup target should be used")
+
+	// the requests in flight are relative to the weights with the least outstanding policy
+	r, err = NewSRVResolver("http://engine.example.com", testSRVName, srvLookup(&lookups,
+		SRVRecord{Target: "heavy.example.com.", Port: 3473, Weight: 3},
+		SRVRecord{Target: "light.example.com.", Port: 3473, Weight: 1},
+	), hostLookup(map[string][]string{"heavy.example.com.": {"10.0.0.1"}, "light.example.com.": {"10.0.0.2"}}))
+	utils.RaiseIfError(t, err)
+	r.Policy = LeastOutstanding
+	inFlight := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		ep, err := r.acquire(context.Background(), nil)
+		utils.RaiseIfError(t, err)
+		inFlight[ep.health.address]++
+	}
+	utils.AssertEqual(inFlight["10.0.0.1:3473"], 6, t, "the heavy target should get 3 times more requests")
+	utils.AssertEqual(inFlight["10.0.0.2:3473"], 2, t, "the light target should get 3 times fewer requests")
+}
@@ -2,9 +2,13 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,19 +27,54 @@ type LookupHostFunc func(ctx context.Context, host string) ([]string, error)
 //
 // DNS results are cached for a configurable TTL and refreshed lazily.
 // If a refresh fails, the previously cached addresses are kept.
+//
+// The health of the IP addresses is tracked passively: an IP address is
+// ejected when a request sent to it fails to connect or gets a 5xx status
+// other than 500, for a cool-down doubling with each consecutive ejection,
+// and is re-admitted once it passes an active probe. Policy selects the
+// IP address among the healthy ones.
+//
+// A resolver created with NewStaticResolver balances the requests across
+// a static list of engine URLs instead, which are used without resolving
+// their hostnames. A resolver created with NewSRVResolver balances them
+// across the targets of SRV records, with their ports, priorities and weights.
 type RoundRobinResolver struct {
 	originalURL  *url.URL
 	originalHost string // hostname without port
 	port         string
 
+	// staticURLs are the engine URLs of a static resolver by address (host and port), nil otherwise
+	staticURLs      map[string]*url.URL
+	staticAddresses []string
+
+	// srvName is the name of the SRV records of a SRV resolver, empty otherwise
+	srvName   string
+	lookupSRV LookupSRVFunc
+
 	lookupHost LookupHostFunc
-	TTL        time.Duration
+	// TTL is how long the resolved addresses are used before resolving them again
+	TTL time.Duration
 
 	mu           sync.RWMutex
 	ips          []string
 	lastResolved time.Time
 
 	counter atomic.Uint64
+
+	// Policy selects the IP address of each request, RoundRobin if empty
+	Policy LoadBalancingPolicy
+	// EjectionDuration is the cool-down of the first ejection of an IP address, 1 second if zero.
+	// It doubles with each consecutive ejection, up to MaxEjectionDuration, 1 minute if zero.
+	EjectionDuration    time.Duration
+	MaxEjectionDuration time.Duration
+	// Probe checks whether an ejected IP address can be re-admitted, a TCP connection attempt if nil
+	Probe ProbeFunc
+
+	healthMu sync.Mutex
+	health   map[string]*endpointHealth
+
+	httpClientMu sync.Mutex
+	httpClient   *http.Client
 }
 
 const defaultDNSTTL = 30 * time.Second
@@ -63,13 +102,131 @@ func NewRoundRobinResolver(rawURL string, lookupHost LookupHostFunc) (*RoundRobi
 		port:         port,
 		lookupHost:   lookupHost,
 		TTL:          defaultDNSTTL,
+		Policy:       RoundRobin,
 	}, nil
 }
 
+// NewSRVResolver creates a resolver that balances the requests sent to rawURL
+// across the targets of the SRV records of srvName, a fully qualified name such
+// as _http._tcp.engine.default.svc.cluster.local, with the ports of the records.
+// The targets of the lowest priority are preferred, and selected in proportion
+// to their weights. The requests keep the hostname of rawURL in their Host
+// header and TLS server name. The records are looked up again after TTL.
+// If lookupSRV is nil, net.DefaultResolver looks up the records.
+// If lookupHost is nil, net.DefaultResolver.LookupHost resolves the targets.
+func NewSRVResolver(rawURL, srvName string, lookupSRV LookupSRVFunc, lookupHost LookupHostFunc) (*RoundRobinResolver, error) {
+	if srvName == "" {
+		return nil, errors.New("empty SRV record name")
+	}
+	r, err := NewRoundRobinResolver(rawURL, lookupHost)
+	if err != nil {
+		return nil, err
+	}
+	if lookupSRV == nil {
+		lookupSRV = systemLookupSRV
+	}
+	r.srvName, r.lookupSRV = srvName, lookupSRV
+	return r, nil
+}
+
+// NewStaticResolver creates a resolver that balances the requests sent to
+// the first of rawURLs across all of them, e.g. the URLs of the nodes of
+// an engine. The URLs are used as they are, their hostnames aren't resolved.
+func NewStaticResolver(rawURLs []string) (*RoundRobinResolver, error) {
+	if len(rawURLs) == 0 {
+		return nil, errors.New("no engine URL to balance the requests across")
+	}
+	r := &RoundRobinResolver{staticURLs: make(map[string]*url.URL), TTL: defaultDNSTTL, Policy: RoundRobin}
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(MakeCanonicalUrl(strings.TrimSpace(rawURL)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+		}
+		if parsed.Hostname() == "" {
+			return nil, fmt.Errorf("invalid URL %q: missing host", rawURL)
+		}
+		address := addressOf(parsed, parsed.Hostname())
+		if _, ok := r.staticURLs[address]; ok {
+			continue
+		}
+		if r.originalURL == nil {
+			r.originalURL, r.originalHost, r.port = parsed, parsed.Hostname(), parsed.Port()
+		}
+		r.staticURLs[address] = parsed
+		r.staticAddresses = append(r.staticAddresses, address)
+	}
+	return r, nil
+}
+
+// addressOf returns the address of host with the port of u, or the default port of its scheme
+func addressOf(u *url.URL, host string) string {
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// endpointURL returns the URL of the requests sent to address, along with the Host header to send them with
+func (r *RoundRobinResolver) endpointURL(address string) (string, string) {
+	if r.staticURLs != nil {
+		return r.staticURLs[address].String(), ""
+	}
+	resolved := *r.originalURL
+	if r.srvName != "" {
+		resolved.Host = address
+	} else if r.port != "" {
+		resolved.Host = net.JoinHostPort(address, r.port)
+	} else {
+		resolved.Host = address
+	}
+	return resolved.String(), r.originalURL.Host
+}
+
+// probeAddress returns the address, with a port, an ejected address is probed at
+func (r *RoundRobinResolver) probeAddress(address string) string {
+	if r.staticURLs != nil || r.srvName != "" {
+		return address
+	}
+	return addressOf(r.originalURL, address)
+}
+
+// client returns the HTTP client to send the requests with. When the requests
+// are sent to IP addresses over HTTPS, it is a copy of base verifying the
+// certificates against the original hostname.
+func (r *RoundRobinResolver) client(base *http.Client) *http.Client {
+	r.httpClientMu.Lock()
+	defer r.httpClientMu.Unlock()
+	if r.httpClient != nil {
+		return r.httpClient
+	}
+	if r.staticURLs != nil || r.originalURL.Scheme != "https" {
+		return base
+	}
+	var transport http.RoundTripper
+	if base != nil {
+		transport = base.Transport
+	}
+	r.httpClient = NewHttpClientForLBWithTransport(transport, r.originalHost)
+	return r.httpClient
+}
+
+// closeIdleConnections closes the idle connections of the HTTP client of the resolver, if it has its own
+func (r *RoundRobinResolver) closeIdleConnections() {
+	r.httpClientMu.Lock()
+	defer r.httpClientMu.Unlock()
+	if r.httpClient != nil {
+		r.httpClient.CloseIdleConnections()
+	}
+}
+
 // resolve refreshes the cached IP list when the TTL has expired.
 func (r *RoundRobinResolver) resolve(ctx context.Context) ([]string, error) {
+	if r.staticURLs != nil {
+		return r.staticAddresses, nil
+	}
 	r.mu.RLock()
-	if len(r.ips) > 0 && time.Since(r.lastResolved) < r.TTL {
+	if r.isFresh() {
 		ips := r.ips
 		r.mu.RUnlock()
 		return ips, nil
@@ -80,49 +237,87 @@ func (r *RoundRobinResolver) resolve(ctx context.Context) ([]string, error) {
 	defer r.mu.Unlock()
 
 	// Double-check after acquiring write lock.
-	if len(r.ips) > 0 && time.Since(r.lastResolved) < r.TTL {
+	if r.isFresh() {
 		return r.ips, nil
 	}
 
-	ips, err := r.lookupHost(ctx, r.originalHost)
+	name := r.originalHost
+	var ips []string
+	var targets map[string]SRVRecord
+	var err error
+	if r.srvName != "" {
+		name = r.srvName
+		ips, targets, err = r.lookupTargets(ctx)
+	} else {
+		ips, err = r.lookupHost(ctx, r.originalHost)
+	}
 	if err != nil {
 		if len(r.ips) > 0 {
-			logging.Infolog.Printf("DNS refresh failed for %s, using cached addresses: %v", r.originalHost, err)
+			logging.Infolog.Printf("DNS refresh failed for %s, using cached addresses: %v", name, err)
 			return r.ips, nil
 		}
-		return nil, fmt.Errorf("DNS lookup failed for %s: %w", r.originalHost, err)
+		return nil, fmt.Errorf("DNS lookup failed for %s: %w", name, err)
 	}
 	if len(ips) == 0 {
 		if len(r.ips) > 0 {
-			logging.Infolog.Printf("DNS returned no addresses for %s, using cached addresses", r.originalHost)
+			logging.Infolog.Printf("DNS returned no addresses for %s, using cached addresses", name)
 			return r.ips, nil
 		}
-		return nil, fmt.Errorf("DNS lookup returned no addresses for %s", r.originalHost)
+		return nil, fmt.Errorf("DNS lookup returned no addresses for %s", name)
 	}
 
 	r.ips = ips
 	r.lastResolved = time.Now()
+	r.pruneHealth(ips)
+	if targets != nil {
+		r.setTargets(targets)
+	}
 	return ips, nil
 }
 
-// Next returns the URL rewritten with the next IP in round-robin
-// rotation, along with the original host (with port) for use as the
-// HTTP Host header. Each successive call advances to the next IP.
-func (r *RoundRobinResolver) Next(ctx context.Context) (resolvedURL string, originalHostWithPort string, err error) {
-	ips, err := r.resolve(ctx)
+// isFresh returns whether the cached addresses can be used without resolving them again, the caller must hold mu
+func (r *RoundRobinResolver) isFresh() bool {
+	return len(r.ips) > 0 && time.Since(r.lastResolved) < r.TTL
+}
+
+// lookupTargets resolves the targets of the SRV records to their IP addresses, it returns
+// the addresses, with the ports of the records, along with the record of each of them
+func (r *RoundRobinResolver) lookupTargets(ctx context.Context) ([]string, map[string]SRVRecord, error) {
+	records, err := r.lookupSRV(ctx, r.srvName)
 	if err != nil {
-		return "", "", err
+		return nil, nil, err
 	}
-
-	idx := r.counter.Add(1) - 1
-	ip := ips[idx%uint64(len(ips))]
-
-	resolved := *r.originalURL
-	if r.port != "" {
-		resolved.Host = net.JoinHostPort(ip, r.port)
-	} else {
-		resolved.Host = ip
+	var addresses []string
+	targets := make(map[string]SRVRecord)
+	for _, record := range records {
+		// a "." target means that the service is decidedly not available
+		if record.Target == "." || record.Target == "" {
+			continue
+		}
+		ips, err := r.lookupHost(ctx, record.Target)
+		if err != nil {
+			logging.Infolog.Printf("DNS lookup failed for %s, a target of %s: %v", record.Target, r.srvName, err)
+			continue
+		}
+		for _, ip := range ips {
+			address := net.JoinHostPort(ip, strconv.Itoa(int(record.Port)))
+			if _, ok := targets[address]; !ok {
+				targets[address] = record
+				addresses = append(addresses, address)
+			}
+		}
 	}
+	return addresses, targets, nil
+}
 
-	return resolved.String(), r.originalURL.Host, nil
+// Next returns the URL rewritten with the next healthy IP selected by
+// the policy, along with the original host (with port) for use as the
+// HTTP Host header. The outcome of the request isn't tracked.
+func (r *RoundRobinResolver) Next(ctx context.Context) (resolvedURL string, originalHostWithPort string, err error) {
+	ep, err := r.acquire(ctx, nil)
+	if err != nil {
+		return "", "", err
+	}
+	ep.done(outcomeUnknown)
+	return ep.url, ep.host, nil
 }
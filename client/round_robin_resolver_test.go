@@ -10,6 +10,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/types"
 )
 
 func staticLookup(ips ...string) LookupHostFunc {
@@ -431,3 +433,141 @@ func TestRoundRobinResolver_BypassedAfterEngineURLChange(t *testing.T) {
 		t.Errorf("expected resolver to still have 1 call (bypassed for new URL), got %d", resolverHitCount.Load())
 	}
 }
+
+func TestStaticResolver(t *testing.T) {
+	r, err := NewStaticResolver([]string{"http://node-1:3473", "node-2:3473", "http://node-1:3473", "https://node-3"})
+	if err != nil {
+		t.Fatalf("NewStaticResolver: %v", err)
+	}
+	if r.originalURL.String() != "http://node-1:3473" {
+		t.Errorf("expected the first URL to be the original one, got %s", r.originalURL)
+	}
+
+	expected := map[string]string{
+		"node-1:3473": "http://node-1:3473",
+		"node-2:3473": "https://node-2:3473",
+		"node-3:443":  "https://node-3",
+	}
+	if len(r.staticAddresses) != len(expected) {
+		t.Fatalf("expected duplicated URLs to be dropped, got %v", r.staticAddresses)
+	}
+	for address, expectedURL := range expected {
+		resolvedURL, hostOverride := r.endpointURL(address)
+		if resolvedURL != expectedURL || hostOverride != "" {
+			t.Errorf("for %s expected %s without host override, got %s and %q", address, expectedURL, resolvedURL, hostOverride)
+		}
+	}
+
+	seen := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		resolvedURL, _, err := r.Next(context.Background())
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		seen[resolvedURL]++
+	}
+	for _, expectedURL := range expected {
+		if seen[expectedURL] != 2 {
+			t.Errorf("expected 2 requests to %s, got %d", expectedURL, seen[expectedURL])
+		}
+	}
+
+	if _, err = NewStaticResolver(nil); err == nil {
+		t.Error("expected an error without URLs")
+	}
+}
+
+// TestStaticEngineURLs tests that the requests of an engine client are balanced across the engine URLs
+// of the settings, and that a node that doesn't accept connections is skipped
+func TestStaticEngineURLs(t *testing.T) {
+	var firstHits, secondHits atomic.Int32
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		firstHits.Add(1)
+	}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secondHits.Add(1)
+	}))
+	defer second.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downURL := down.URL
+	down.Close()
+
+	engineClient, err := MakeClientEngine(&types.FireboltSettings{
+		Url:          first.URL,
+		EngineURLs:   []string{first.URL, downURL, second.URL},
+		NewVersion:   true,
+		ClientSideLB: true,
+	})
+	if err != nil {
+		t.Fatalf("MakeClientEngine: %v", err)
+	}
+	engineClient.URLResolver.Probe = func(context.Context, string) error { return fmt.Errorf("still down") }
+
+	for i := 0; i < 6; i++ {
+		if _, err = engineClient.Query(context.Background(), first.URL, "SELECT 1", map[string]string{}, ConnectionControl{}); err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+	}
+	if firstHits.Load()+secondHits.Load() != 6 {
+		t.Errorf("expected all the requests to succeed on the healthy nodes, got %d and %d", firstHits.Load(), secondHits.Load())
+	}
+	if firstHits.Load() == 0 || secondHits.Load() == 0 {
+		t.Errorf("expected the requests to be balanced across the healthy nodes, got %d and %d", firstHits.Load(), secondHits.Load())
+	}
+
+	if _, err = MakeClientEngine(&types.FireboltSettings{Url: first.URL, EngineURLs: []string{first.URL, second.URL}, NewVersion: true}); err == nil {
+		t.Error("expected an error for several engine URLs without client-side load balancing")
+	}
+}
+
+// TestLoadBalancingFollowsEndpointUpdate tests that a client with load balancing enabled builds a resolver
+// for the engine URL of an endpoint update, and balances the following requests to it
+func TestLoadBalancingFollowsEndpointUpdate(t *testing.T) {
+	var newServerHits atomic.Int32
+	// the engine URLs of endpoint updates have no scheme, they are reached over HTTPS
+	newServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		newServerHits.Add(1)
+	}))
+	defer newServer.Close()
+	oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(updateEndpointHeader, newServer.URL+"?engine=new_engine")
+	}))
+	defer oldServer.Close()
+
+	client := &ClientImpl{BaseClient: BaseClient{ApiEndpoint: oldServer.URL, UserAgent: "test", HttpClient: newServer.Client()}}
+	client.AccessTokenGetter = func(context.Context) (string, error) { return "token", nil }
+	client.ParameterGetter = func(context.Context, map[string]string) (map[string]string, error) {
+		return map[string]string{}, nil
+	}
+	client.EnableLoadBalancing(LoadBalancingConfig{Policy: LeastOutstanding})
+
+	engineURL := oldServer.URL
+	control := ConnectionControl{
+		UpdateParameters: func(string, string) {},
+		SetEngineURL:     func(value string) { engineURL = value },
+	}
+	if _, err := client.Query(context.Background(), engineURL, "USE ENGINE new_engine", map[string]string{}, control); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	resolver := client.resolverFor(engineURL)
+	if resolver == nil {
+		t.Fatalf("expected a resolver for the updated engine URL %s", engineURL)
+	}
+	if resolver.Policy != LeastOutstanding {
+		t.Errorf("expected the resolver to use the configured policy, got %s", resolver.Policy)
+	}
+
+	if _, err := client.Query(context.Background(), engineURL, "SELECT 1", map[string]string{}, control); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if newServerHits.Load() != 1 {
+		t.Errorf("expected the new engine to receive 1 request, got %d", newServerHits.Load())
+	}
+	resolver.healthMu.Lock()
+	_, tracked := resolver.health["127.0.0.1"]
+	resolver.healthMu.Unlock()
+	if !tracked {
+		t.Error("expected the request to the new engine to go through its resolver")
+	}
+}
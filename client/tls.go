@@ -0,0 +1,68 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+)
+
+// ParseTLSVersion parses a TLS version such as "1.2" or "1.3" into its tls.VersionTLS* value
+func ParseTLSVersion(value string) (uint16, error) {
+	switch strings.TrimSpace(value) {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q, expected one of 1.0, 1.1, 1.2, 1.3", value)
+	}
+}
+
+// NewTLSConfig returns a TLS configuration built from settings: a clone of settings.Config, or of base if
+// settings.Config is nil, with the CA bundle, client certificate and minimum version of settings applied on top.
+func NewTLSConfig(settings types.TLSSettings, base *tls.Config) (*tls.Config, error) {
+	if settings.Config != nil {
+		base = settings.Config
+	}
+	config := &tls.Config{}
+	if base != nil {
+		config = base.Clone()
+	}
+
+	if settings.CAFile != "" {
+		pem, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in the CA bundle %s", settings.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if settings.CertFile != "" || settings.KeyFile != "" {
+		if settings.CertFile == "" || settings.KeyFile == "" {
+			return nil, errors.New("both the client certificate and key files must be set")
+		}
+		certificate, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %w", err)
+		}
+		config.Certificates = append(config.Certificates, certificate)
+	}
+
+	if settings.MinVersion != 0 {
+		config.MinVersion = settings.MinVersion
+	}
+	return config, nil
+}
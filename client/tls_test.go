@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+// testPKI is a CA along with a server certificate for localhost and a client certificate signed by it
+type testPKI struct {
+	caPool         *x509.CertPool
+	caFile         string
+	server         tls.Certificate
+	clientCertFile string
+	clientKeyFile  string
+}
+
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	utils.RaiseIfError(t, err)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	utils.RaiseIfError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	utils.RaiseIfError(t, err)
+	return certificate, key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	utils.RaiseIfError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	dir := t.TempDir()
+	notAfter := time.Now().Add(time.Hour)
+	ca, caKey := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	server, serverKey := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	client, clientKey := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	pki := &testPKI{
+		caPool:         x509.NewCertPool(),
+		caFile:         filepath.Join(dir, "ca.pem"),
+		server:         tls.Certificate{Certificate: [][]byte{server.Raw}, PrivateKey: serverKey},
+		clientCertFile: filepath.Join(dir, "client.pem"),
+		clientKeyFile:  filepath.Join(dir, "client-key.pem"),
+	}
+	pki.caPool.AddCert(ca)
+	writePEM(t, pki.caFile, "CERTIFICATE", ca.Raw)
+	writePEM(t, pki.clientCertFile, "CERTIFICATE", client.Raw)
+	clientKeyDER, err := x509.MarshalECPrivateKey(clientKey)
+	utils.RaiseIfError(t, err)
+	writePEM(t, pki.clientKeyFile, "EC PRIVATE KEY", clientKeyDER)
+	return pki
+}
+
+// newMutualTLSServer starts a server requiring a client certificate signed by the CA of pki
+func newMutualTLSServer(t *testing.T, pki *testPKI) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.caPool,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	version, err := ParseTLSVersion("1.3")
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(version, uint16(tls.VersionTLS13), t, "wrong TLS version")
+	version, err = ParseTLSVersion("1.2")
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(version, uint16(tls.VersionTLS12), t, "wrong TLS version")
+
+	_, err = ParseTLSVersion("1.4")
+	if err == nil {
+		t.Error("expected an error for an unsupported TLS version")
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	base := &tls.Config{ServerName: "base"}
+	config, err := NewTLSConfig(types.TLSSettings{
+		CAFile:     pki.caFile,
+		CertFile:   pki.clientCertFile,
+		KeyFile:    pki.clientKeyFile,
+		MinVersion: tls.VersionTLS13,
+	}, base)
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(config.ServerName, "base", t, "base config should be kept")
+	utils.AssertEqual(config.MinVersion, uint16(tls.VersionTLS13), t, "wrong minimum TLS version")
+	utils.AssertEqual(len(config.Certificates), 1, t, "client certificate should be loaded")
+	utils.AssertEqual(config.RootCAs != nil, true, t, "CA bundle should be loaded")
+	utils.AssertEqual(len(base.Certificates), 0, t, "base config should not be modified")
+
+	// the config of the settings takes precedence over the base
+	config, err = NewTLSConfig(types.TLSSettings{Config: &tls.Config{ServerName: "settings"}}, base)
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(config.ServerName, "settings", t, "config of the settings should be used")
+
+	for name, settings := range map[string]types.TLSSettings{
+		"missing CA bundle":   {CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"CA bundle not a PEM": {CAFile: pki.clientKeyFile},
+		"missing key":         {CertFile: pki.clientCertFile},
+		"mismatching key":     {CertFile: pki.caFile, KeyFile: pki.clientKeyFile},
+	} {
+		if _, err = NewTLSConfig(settings, nil); err == nil {
+			t.Errorf("expected an error for %s", name)
+		}
+	}
+}
+
+// TestMutualTLSWithClientSideLB tests that the CA bundle and client certificate are used for the requests
+// rewritten to IP addresses by client-side load balancing, which verify the certificate of the original hostname
+func TestMutualTLSWithClientSideLB(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newMutualTLSServer(t, pki)
+	engineUrl := fmt.Sprintf("https://localhost:%d", server.Listener.Addr().(*net.TCPAddr).Port)
+
+	query := func(tlsSettings types.TLSSettings) error {
+		engineClient, err := MakeClientEngine(&types.FireboltSettings{Url: engineUrl, NewVersion: true, ClientSideLB: true, TLS: tlsSettings})
+		if err != nil {
+			return err
+		}
+		// the server only listens on IPv4
+		engineClient.URLResolver, err = NewRoundRobinResolver(engineUrl, func(context.Context, string) ([]string, error) {
+			return []string{"127.0.0.1"}, nil
+		})
+		utils.RaiseIfError(t, err)
+		_, err = engineClient.Query(context.Background(), engineUrl, selectOne, map[string]string{}, ConnectionControl{})
+		return err
+	}
+
+	err := query(types.TLSSettings{CAFile: pki.caFile, CertFile: pki.clientCertFile, KeyFile: pki.clientKeyFile, MinVersion: tls.VersionTLS13})
+	utils.RaiseIfError(t, err)
+
+	if err = query(types.TLSSettings{CAFile: pki.caFile}); err == nil {
+		t.Error("expected an error without a client certificate")
+	}
+	if err = query(types.TLSSettings{CertFile: pki.clientCertFile, KeyFile: pki.clientKeyFile}); err == nil {
+		t.Error("expected an error without the CA bundle")
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/firebolt-db/firebolt-go-sdk/cache"
+	"github.com/firebolt-db/firebolt-go-sdk/logging"
+)
+
+// tokenExpiryDelta is how long before its expiry a token stops being used, so that it doesn't
+// expire while a request is in flight. Short-lived tokens use a tenth of their lifetime instead.
+const tokenExpiryDelta = 10 * time.Second
+
+// tokenRefreshAheadRatio is the part of its lifetime after which a token is refreshed in the background
+const tokenRefreshAheadRatio = 0.8
+
+// tokenFetchTimeout bounds a token fetch, which isn't cancelled along with the callers waiting for it
+const tokenFetchTimeout = time.Minute
+
+// tokenFetches dedupes concurrent fetches of the tokens shared through tokenCache
+var tokenFetches singleflight.Group
+
+// cachedToken is a token along with the time its background refresh starts at.
+// It is stored as JSON by the cache backends.
+type cachedToken struct {
+	Token *Token `json:"token"`
+	// RefreshAt is zero for tokens that don't expire
+	RefreshAt time.Time `json:"refresh_at"`
+}
+
+func (c *cachedToken) needsRefresh() bool {
+	return !c.RefreshAt.IsZero() && time.Now().After(c.RefreshAt)
+}
+
+// tokenManager caches the tokens of a TokenSource. Tokens are refreshed in the background once most of
+// their lifetime has passed, and fetched synchronously once they are about to expire or are invalidated.
+// Concurrent fetches of the same token are deduplicated, so that bursts of new connections
+// don't stampede the identity service.
+type tokenManager struct {
+	source TokenSource
+	cache  *cache.Cache
+	// key is the key of the token in the cache and of its in-flight fetches
+	key     string
+	fetches *singleflight.Group
+}
+
+// newTokenManager returns a manager that keeps the tokens of source to itself
+func newTokenManager(source TokenSource) *tokenManager {
+	return &tokenManager{source: source, cache: cache.New(), fetches: &singleflight.Group{}}
+}
+
+// newSharedTokenManager returns a manager that shares the tokens of source through tokens under key,
+// tokens is tokenCache or a view of it storing its entries in another backend
+func newSharedTokenManager(source TokenSource, tokens *cache.Cache, key string) *tokenManager {
+	return &tokenManager{source: source, cache: tokens, key: key, fetches: &tokenFetches}
+}
+
+// token returns the cached token, or fetches a new one if it is missing or about to expire
+func (m *tokenManager) token(ctx context.Context) (*Token, error) {
+	if cached := m.cached(); cached != nil {
+		if cached.needsRefresh() {
+			m.refreshInBackground(ctx)
+		}
+		return cached.Token, nil
+	}
+	return m.fetch(ctx)
+}
+
+// accessToken returns the cached access token, or fetches a new one if it is missing or about to expire
+func (m *tokenManager) accessToken(ctx context.Context) (string, error) {
+	token, err := m.token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// invalidate drops the cached token, so that the next call fetches a new one
+func (m *tokenManager) invalidate() {
+	m.cache.Delete(m.key)
+}
+
+func (m *tokenManager) cached() *cachedToken {
+	if cached, ok := cache.Load[*cachedToken](m.cache, m.key); ok && cached != nil && cached.Token != nil {
+		return cached
+	}
+	return nil
+}
+
+// refreshInBackground fetches a new token without blocking the caller, which keeps using the cached one
+func (m *tokenManager) refreshInBackground(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if _, err := m.fetch(ctx); err != nil {
+			logging.Infolog.Printf("background token refresh failed, the current token is used until it expires: %v", err)
+		}
+	}()
+}
+
+// fetch gets a new token from the source and caches it. Callers arriving while a fetch
+// is in flight wait for its result, and a token refreshed in the meantime is reused.
+// The fetch isn't cancelled along with ctx, since other callers may be waiting for it,
+// but the caller stops waiting once ctx is done.
+func (m *tokenManager) fetch(ctx context.Context) (*Token, error) {
+	results := m.fetches.DoChan(m.key, func() (interface{}, error) {
+		if cached := m.cached(); cached != nil && !cached.needsRefresh() {
+			return cached.Token, nil
+		}
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tokenFetchTimeout)
+		defer cancel()
+		token, err := m.source.Token(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+		if token == nil || token.AccessToken == "" {
+			return nil, errors.New("received an empty access token")
+		}
+		m.store(token)
+		return token, nil
+	})
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*Token), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// store caches the token until shortly before its expiry
+func (m *tokenManager) store(token *Token) {
+	if token.Expiry.IsZero() {
+		m.cache.Put(m.key, &cachedToken{Token: token}, 0)
+		return
+	}
+	lifetime := time.Until(token.Expiry)
+	ttl := lifetime - min(tokenExpiryDelta, lifetime/10)
+	if ttl <= 0 {
+		// a zero TTL would cache the token forever
+		logging.Infolog.Printf("the access token has already expired at %s, it is not cached", token.Expiry)
+		return
+	}
+	refreshAt := time.Now().Add(time.Duration(float64(lifetime) * tokenRefreshAheadRatio))
+	m.cache.Put(m.key, &cachedToken{Token: token, RefreshAt: refreshAt}, ttl)
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+// countingTokenSource returns the tokens in order, the last one once the others are used, and counts the calls
+type countingTokenSource struct {
+	mutex  sync.Mutex
+	tokens []*Token
+	calls  int
+	// release blocks the calls until it is closed, if set
+	release chan struct{}
+}
+
+func (s *countingTokenSource) Token(context.Context) (*Token, error) {
+	if s.release != nil {
+		<-s.release
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	token := s.tokens[min(s.calls, len(s.tokens)-1)]
+	s.calls++
+	return token, nil
+}
+
+func (s *countingTokenSource) callCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls
+}
+
+func TestTokenManagerCaching(t *testing.T) {
+	source := &countingTokenSource{tokens: []*Token{{AccessToken: "first"}, {AccessToken: "second"}}}
+	manager := newTokenManager(source)
+
+	for i := 0; i < 3; i++ {
+		token, err := manager.accessToken(context.TODO())
+		utils.RaiseIfError(t, err)
+		utils.AssertEqual(token, "first", t, "wrong cached token")
+	}
+	utils.AssertEqual(source.callCount(), 1, t, "the token source should be called once")
+
+	manager.invalidate()
+	token, err := manager.accessToken(context.TODO())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token, "second", t, "the token should be fetched again after invalidation")
+	utils.AssertEqual(source.callCount(), 2, t, "the token source should be called again after invalidation")
+}
+
+func TestTokenManagerExpiry(t *testing.T) {
+	source := &countingTokenSource{tokens: []*Token{
+		{AccessToken: "first", Expiry: time.Now().Add(50 * time.Millisecond)},
+		{AccessToken: "second", Expiry: time.Now().Add(time.Hour)},
+	}}
+	manager := newTokenManager(source)
+
+	token, err := manager.accessToken(context.TODO())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token, "first", t, "wrong token")
+
+	// Waiting for the token to get expired
+	time.Sleep(60 * time.Millisecond)
+	token, err = manager.accessToken(context.TODO())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token, "second", t, "the expired token should be fetched again")
+	utils.AssertEqual(source.callCount(), 2, t, "the token source should be called twice")
+}
+
+func TestTokenManagerBackgroundRefresh(t *testing.T) {
+	source := &countingTokenSource{tokens: []*Token{
+		{AccessToken: "first", Expiry: time.Now().Add(500 * time.Millisecond)},
+		{AccessToken: "second", Expiry: time.Now().Add(time.Hour)},
+	}}
+	manager := newTokenManager(source)
+	_, err := manager.accessToken(context.TODO())
+	utils.RaiseIfError(t, err)
+
+	// Waiting for the refresh window, the token is still valid
+	time.Sleep(420 * time.Millisecond)
+	token, err := manager.accessToken(context.TODO())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token, "first", t, "the current token should be used while it is refreshed")
+
+	deadline := time.Now().Add(time.Second)
+	for source.callCount() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	// the token might be fetched, but not stored yet
+	time.Sleep(5 * time.Millisecond)
+	token, err = manager.accessToken(context.TODO())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token, "second", t, "the token should be refreshed in the background")
+	utils.AssertEqual(source.callCount(), 2, t, "the token should be refreshed once")
+}
+
+func TestTokenManagerSingleFlight(t *testing.T) {
+	source := &countingTokenSource{tokens: []*Token{{AccessToken: "token"}}, release: make(chan struct{})}
+	manager := newTokenManager(source)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	tokens := make([]string, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = manager.accessToken(context.TODO())
+		}(i)
+	}
+	// give the callers the time to join the in-flight fetch
+	time.Sleep(50 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		utils.RaiseIfError(t, errs[i])
+		utils.AssertEqual(tokens[i], "token", t, "wrong token")
+	}
+	utils.AssertEqual(source.callCount(), 1, t, "concurrent fetches should be deduplicated")
+}
+
+func TestTokenManagerErrors(t *testing.T) {
+	sourceErr := errors.New("vault is sealed")
+	manager := newTokenManager(TokenSourceFunc(func(context.Context) (*Token, error) {
+		return nil, sourceErr
+	}))
+	if _, err := manager.accessToken(context.TODO()); !errors.Is(err, sourceErr) {
+		t.Errorf("expected the token source error, got %v", err)
+	}
+
+	if _, err := newTokenManager(StaticTokenSource("")).accessToken(context.TODO()); err == nil {
+		t.Errorf("expected an error for an empty token")
+	}
+
+	source := &countingTokenSource{tokens: []*Token{{AccessToken: "expired", Expiry: time.Now().Add(-time.Second)}}}
+	manager = newTokenManager(source)
+	for i := 0; i < 2; i++ {
+		token, err := manager.accessToken(context.TODO())
+		utils.RaiseIfError(t, err)
+		utils.AssertEqual(token, "expired", t, "wrong token")
+	}
+	utils.AssertEqual(source.callCount(), 2, t, "an expired token should not be cached")
+}
+
+func TestTokenManagerCallerContext(t *testing.T) {
+	source := &countingTokenSource{tokens: []*Token{{AccessToken: "token"}}, release: make(chan struct{})}
+	manager := newTokenManager(source)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := manager.accessToken(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline exceeded error, got %v", err)
+	}
+
+	// the fetch isn't cancelled along with the caller, its token is cached for the next callers
+	close(source.release)
+	token, err := manager.accessToken(context.Background())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token, "token", t, "wrong token")
+	utils.AssertEqual(source.callCount(), 1, t, "the token fetched for the cancelled caller should be reused")
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
+)
+
+// Token is an access token along with its expiry time
+type Token struct {
+	AccessToken string
+	// Expiry is the time the token expires at, a zero value means the token doesn't expire
+	Expiry time.Time
+}
+
+// TokenSource provides the access tokens used to authenticate requests, e.g. from Vault,
+// a secrets manager, a rotated file or a workload identity exchange.
+// The SDK caches the returned token, refreshes it in the background before it expires and
+// fetches it again when it is rejected by the server, so implementations don't need to cache
+// tokens themselves. Concurrent fetches are deduplicated.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc is an adapter to use an ordinary function as a TokenSource
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls f(ctx)
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource that always returns the same non-expiring token
+func StaticTokenSource(accessToken string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		return &Token{AccessToken: accessToken}, nil
+	})
+}
+
+// FileTokenSource returns a TokenSource that reads the token from a file, e.g. one
+// rotated by a sidecar. Leading and trailing whitespace is trimmed.
+// The file is read again once refreshInterval has passed, or when the server rejects the token.
+// A zero refreshInterval only re-reads the file when the token is rejected.
+func FileTokenSource(path string, refreshInterval time.Duration) TokenSource {
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errorUtils.ConstructNestedError("error reading token file", err)
+		}
+		token := &Token{AccessToken: strings.TrimSpace(string(content))}
+		if refreshInterval > 0 {
+			token.Expiry = time.Now().Add(refreshInterval)
+		}
+		return token, nil
+	})
+}
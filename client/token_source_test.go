@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+func TestFileTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	utils.RaiseIfError(t, os.WriteFile(path, []byte("first\n"), 0600))
+
+	source := FileTokenSource(path, time.Minute)
+	token, err := source.Token(context.Background())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token.AccessToken, "first", t, "wrong token read from file")
+	if token.Expiry.IsZero() {
+		t.Errorf("expected the token to expire after the refresh interval")
+	}
+
+	utils.RaiseIfError(t, os.WriteFile(path, []byte("second"), 0600))
+	token, err = FileTokenSource(path, 0).Token(context.Background())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token.AccessToken, "second", t, "the rotated token should be read")
+	utils.AssertEqual(token.Expiry.IsZero(), true, t, "the token should not expire without a refresh interval")
+
+	if _, err = FileTokenSource(filepath.Join(t.TempDir(), "missing"), 0).Token(context.Background()); err == nil {
+		t.Errorf("expected an error for a missing token file")
+	}
+}
+
+// TestTokenSourceRefreshOnUnauthorized tests that a token rejected by the server is fetched again from the token source
+func TestTokenSourceRefreshOnUnauthorized(t *testing.T) {
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	source := &countingTokenSource{tokens: []*Token{{AccessToken: "stale"}, {AccessToken: "fresh"}}}
+	client := &ClientImpl{BaseClient: BaseClient{ApiEndpoint: server.URL}}
+	client.SetTokenSource(source)
+
+	resp := client.requestWithAuthRetry(context.TODO(), "GET", server.URL, nil, "")
+	utils.RaiseIfError(t, resp.err)
+	utils.AssertEqual(len(authHeaders), 2, t, "the request should be retried once")
+	utils.AssertEqual(authHeaders[0], "Bearer stale", t, "wrong token in the first request")
+	utils.AssertEqual(source.callCount(), 2, t, "the token should be fetched again after a 401")
+
+	resp = client.requestWithAuthRetry(context.TODO(), "GET", server.URL, nil, "")
+	utils.RaiseIfError(t, resp.err)
+	utils.AssertEqual(source.callCount(), 2, t, "the refreshed token should be cached")
+}
+
+func TestClientFactoryWithTokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	settings := &types.FireboltSettings{NewVersion: true, Url: server.URL}
+	client, err := ClientFactoryWithTokenSource(context.TODO(), settings, server.URL, StaticTokenSource("token"))
+	utils.RaiseIfError(t, err)
+	engineClient, ok := client.(*ClientImplEngine)
+	utils.AssertEqual(ok, true, t, "client is not *ClientImplEngine")
+	token, err := engineClient.AccessTokenGetter(context.TODO())
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(token, "token", t, "the token should come from the token source")
+
+	_, err = ClientFactoryWithTokenSource(context.TODO(), settings, server.URL, StaticTokenSource(""))
+	if err == nil {
+		t.Errorf("expected an error for a token source returning an empty token")
+	}
+
+	_, err = ClientFactoryWithTokenSource(context.TODO(), &types.FireboltSettings{}, server.URL, StaticTokenSource("token"))
+	if err == nil {
+		t.Errorf("expected an error for username and password settings")
+	}
+}
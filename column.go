@@ -12,6 +12,8 @@ import (
 	"unsafe"
 
 	"github.com/parquet-go/parquet-go"
+
+	"github.com/firebolt-db/firebolt-go-sdk/rows"
 )
 
 var epoch = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -149,8 +151,10 @@ func newScalarColumn(colName, fireboltType string) (column, error) {
 		return &float32Column{colName: colName}, nil
 	case "double", "double precision":
 		return &float64Column{colName: colName}, nil
-	case "text", "geography":
+	case "text":
 		return &stringColumn{colName: colName}, nil
+	case "geography":
+		return &geographyColumn{stringColumn{colName: colName}}, nil
 	case "json":
 		return &jsonColumn{colName: colName}, nil
 	case "boolean":
@@ -503,6 +507,49 @@ func (c *stringColumn) parquetValues(colIdx int) []parquet.Value {
 	return vals
 }
 
+// ---------------------------------------------------------------------------
+// geographyColumn
+// ---------------------------------------------------------------------------
+
+// geographyColumn buffers values destined for a Firebolt GEOGRAPHY column.
+//
+// Values are written as text, which the engine parses as WKT, hex-encoded
+// (E)WKB or GeoJSON. Strings are taken as is, rows.Geography values, (E)WKB
+// bytes and go-geom geometries are converted to hex-encoded (E)WKB.
+type geographyColumn struct {
+	stringColumn
+}
+
+func (c *geographyColumn) appendRow(v interface{}) error {
+	if s, ok := v.(string); ok {
+		c.data = append(c.data, s)
+		return nil
+	}
+	g, valid, err := rows.ToGeography(v)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("cannot append a NULL geography to a non-nullable column")
+	}
+	c.data = append(c.data, string(g))
+	return nil
+}
+
+func (c *geographyColumn) appendColumn(v interface{}) error {
+	switch vals := v.(type) {
+	case []string:
+		c.data = append(c.data, vals...)
+		return nil
+	case []rows.Geography:
+		for _, g := range vals {
+			c.data = append(c.data, string(g))
+		}
+		return nil
+	}
+	return appendColumnFallback(c, v)
+}
+
 // ---------------------------------------------------------------------------
 // jsonColumn
 // ---------------------------------------------------------------------------
@@ -0,0 +1,81 @@
+package fireboltgosdk
+
+import (
+	"testing"
+
+	"github.com/twpayne/go-geom"
+
+	"github.com/firebolt-db/firebolt-go-sdk/rows"
+)
+
+const pointOneOneWKB = "0101000020E6100000000000000000F03F000000000000F03F"
+
+func TestGeographyColumnType(t *testing.T) {
+	col, err := newColumn("location", "geography")
+	if err != nil {
+		t.Fatalf("geography should be a supported batch column type: %v", err)
+	}
+	if _, ok := col.(*geographyColumn); !ok {
+		t.Fatalf("newColumn returned %T, want *geographyColumn", col)
+	}
+}
+
+func TestGeographyColumnAppendRow(t *testing.T) {
+	wkb, err := rows.Geography(pointOneOneWKB).WKB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	point := geom.NewPointFlat(geom.XY, []float64{1, 1}).SetSRID(4326)
+
+	col := &geographyColumn{}
+	for _, v := range []interface{}{
+		"POINT(1 1)",
+		rows.Geography(pointOneOneWKB),
+		rows.NullGeography{Geography: rows.Geography(pointOneOneWKB), Valid: true},
+		wkb,
+		point,
+	} {
+		if err := col.appendRow(v); err != nil {
+			t.Fatalf("appendRow(%T): %v", v, err)
+		}
+	}
+	want := []string{"POINT(1 1)", pointOneOneWKB, pointOneOneWKB, pointOneOneWKB, pointOneOneWKB}
+	for i := range want {
+		if col.data[i] != want[i] {
+			t.Errorf("row %d = %q, want %q", i, col.data[i], want[i])
+		}
+	}
+
+	for _, v := range []interface{}{1, rows.NullGeography{}} {
+		if err := col.appendRow(v); err == nil {
+			t.Errorf("appendRow(%#v) should fail", v)
+		}
+	}
+	if col.rows() != len(want) {
+		t.Errorf("rows() = %d after rejected values, want %d", col.rows(), len(want))
+	}
+}
+
+func TestGeographyColumnAppendColumn(t *testing.T) {
+	col, err := newColumn("location", "array(geography) null")
+	if err != nil {
+		t.Fatalf("newColumn: %v", err)
+	}
+	if err := col.appendRow([]rows.Geography{pointOneOneWKB, pointOneOneWKB}); err != nil {
+		t.Fatalf("appendRow: %v", err)
+	}
+	if err := col.appendRow(nil); err != nil {
+		t.Fatalf("appendRow(nil): %v", err)
+	}
+
+	geography := &geographyColumn{}
+	if err := geography.appendColumn([]rows.Geography{pointOneOneWKB}); err != nil {
+		t.Fatalf("appendColumn: %v", err)
+	}
+	if err := geography.appendColumn([]interface{}{"POINT(0 0)", geom.NewPointFlat(geom.XY, []float64{0, 0})}); err != nil {
+		t.Fatalf("appendColumn: %v", err)
+	}
+	if geography.rows() != 3 {
+		t.Errorf("rows() = %d, want 3", geography.rows())
+	}
+}
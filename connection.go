@@ -3,10 +3,16 @@ package fireboltgosdk
 import (
 	"context"
 	"database/sql/driver"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/firebolt-db/firebolt-go-sdk/logging"
 	"github.com/firebolt-db/firebolt-go-sdk/utils"
 
 	"github.com/firebolt-db/firebolt-go-sdk/statement"
@@ -46,6 +52,19 @@ type DescribeConnection interface {
 	Describe(ctx context.Context, query string, args ...interface{}) (*types.DescribeResult, error)
 }
 
+// DescribeStatement interface provides access to the description of a prepared statement.
+// Statements prepared through conn.Raw() implement it. Prepared statements are also described on their
+// first execution with arguments, and the result is cached.
+type DescribeStatement interface {
+	Describe(ctx context.Context) (*types.DescribeResult, error)
+	ColumnTypes(ctx context.Context) ([]rows.ColumnType, error)
+}
+
+// ArrowConnection interface provides access to query results as Apache Arrow record batches
+type ArrowConnection interface {
+	QueryArrow(ctx context.Context, query string, args ...interface{}) (array.RecordReader, error)
+}
+
 type fireboltConnection struct {
 	client     client.Client
 	engineUrl  string
@@ -68,6 +87,51 @@ func (c *fireboltConnection) PrepareContext(ctx context.Context, query string) (
 	return nil, errors.New("firebolt connection isn't properly initialized")
 }
 
+// CheckNamedValue converts query arguments, allowing slices, maps and decimals
+// to be bound as ARRAY, STRUCT and NUMERIC values. Arguments that can't be bound
+// are rejected before the query is sent.
+func (c *fireboltConnection) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := statement.CheckParameter(nv.Value)
+	if err != nil {
+		if nv.Name != "" {
+			return fmt.Errorf("unsupported value for parameter '%s' of type %T: %w", nv.Name, nv.Value, err)
+		}
+		return fmt.Errorf("unsupported value for parameter %d of type %T: %w", nv.Ordinal, nv.Value, err)
+	}
+	nv.Value = value
+	return nil
+}
+
+// Ping checks that the engine of the connection is reachable by running a lightweight query
+func (c *fireboltConnection) Ping(ctx context.Context) error {
+	if !c.IsValid() {
+		return driver.ErrBadConn
+	}
+	if _, err := c.ExecContext(ctx, "SELECT 1", nil); err != nil {
+		return errorUtils.ConstructNestedError("error pinging the engine", err)
+	}
+	return nil
+}
+
+// ResetSession is called before a pooled connection is reused. It restores the engine URL and the parameters
+// cached by the connector, which drops the transaction state, the parameters that aren't persisted and
+// the engine selected with USE ENGINE on the connection.
+func (c *fireboltConnection) ResetSession(ctx context.Context) error {
+	if !c.IsValid() {
+		return driver.ErrBadConn
+	}
+	if c.connector == nil {
+		return nil
+	}
+	c.engineUrl, c.parameters = c.connector.session()
+	return nil
+}
+
+// IsValid returns false for closed connections, so that they are discarded by the pool
+func (c *fireboltConnection) IsValid() bool {
+	return c.client != nil && len(c.engineUrl) != 0
+}
+
 // Close closes the connection, and make the fireboltConnection unusable
 func (c *fireboltConnection) Close() error {
 	c.client = nil
@@ -85,13 +149,14 @@ func (c *fireboltConnection) Begin() (driver.Tx, error) {
 	return &fireboltTransaction{conn: c}, nil
 }
 
-// ExecContext sends the query to the engine and returns empty fireboltResult
+// ExecContext sends the query to the engine and returns fireboltResult with the statistics of every statement
 func (c *fireboltConnection) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
 	stmt, err := statement.MakeStmt(c, query, contextUtils.GetPreparedStatementsStyle(ctx))
 	if err != nil {
 		return nil, errorUtils.ConstructNestedError("error during preparing a statement", err)
 	}
-	if rs, err := c.ExecutePreparedQueries(ctx, stmt.Queries, args, false); err != nil {
+	c.resolveParameterTypes(ctx, stmt, query, args)
+	if rs, err := c.ExecutePreparedQueries(ctx, stmt.Queries, args, true); err != nil {
 		return nil, errorUtils.Wrap(errorUtils.QueryExecutionError, err)
 	} else {
 		return rs.Result()
@@ -104,12 +169,13 @@ func (c *fireboltConnection) QueryContext(ctx context.Context, query string, arg
 	if err != nil {
 		return nil, errorUtils.ConstructNestedError("error during preparing a statement", err)
 	}
+	c.resolveParameterTypes(ctx, stmt, query, args)
 	return c.ExecutePreparedQueries(ctx, stmt.Queries, args, true)
 }
 
 // Describe executes a query with describe context and returns the unmarshalled DescribeResult.
 // This function is only usable when accessed through conn.Raw().
-func (c *fireboltConnection) Describe(ctx context.Context, query string, args ...interface{}) (result *types.DescribeResult, err error) {
+func (c *fireboltConnection) Describe(ctx context.Context, query string, args ...interface{}) (*types.DescribeResult, error) {
 	// Validate that the context uses Firebolt numeric prepared statements style
 	if contextUtils.GetPreparedStatementsStyle(ctx) != contextUtils.PreparedStatementsStyleFbNumeric {
 		return nil, errors.New("Describe function requires PreparedStatementsStyleFbNumeric context parameter")
@@ -132,45 +198,86 @@ func (c *fireboltConnection) Describe(ctx context.Context, query string, args ..
 	if err != nil {
 		return nil, errorUtils.ConstructNestedError("error executing describe query", err)
 	}
-	defer func() {
-		if closeErr := queryRows.Close(); closeErr != nil {
-			result = nil
-			err = errors.Join(err, errorUtils.ConstructNestedError("error closing describe rows", closeErr))
-		}
-	}()
+	return statement.ReadDescribeResult(queryRows)
+}
 
-	// Read the JSON result using driver.Rows interface
-	dest := make([]driver.Value, 1) // describe returns one column
-	err = queryRows.Next(dest)
+// QueryArrow executes a single statement and returns its result as a stream of Arrow record batches.
+// The result is streamed from the server when the client supports it, and values are converted
+// into Arrow arrays directly, without an intermediate driver.Value per cell.
+// The returned reader must be released by the caller. This function is only usable when accessed through conn.Raw().
+func (c *fireboltConnection) QueryArrow(ctx context.Context, query string, args ...interface{}) (array.RecordReader, error) {
+	driverValues, err := convertToNamedValues(args)
 	if err != nil {
-		return nil, errorUtils.ConstructNestedError("error reading describe result", err)
+		return nil, errorUtils.ConstructNestedError("error converting arguments", err)
 	}
-
-	// Extract the JSON string from the result
-	jsonResult, ok := dest[0].(string)
-	if !ok {
-		return nil, errors.New("describe result is not a string")
+	if supportsStreaming(c) {
+		ctx = contextUtils.WithStreaming(ctx)
 	}
 
-	// Unmarshal the JSON into DescribeResult
-	var describeResult types.DescribeResult
-	if err := json.Unmarshal([]byte(jsonResult), &describeResult); err != nil {
-		return nil, errorUtils.ConstructNestedError("error unmarshalling describe result", err)
+	stmt, err := statement.MakeStmt(c, query, contextUtils.GetPreparedStatementsStyle(ctx))
+	if err != nil {
+		return nil, errorUtils.ConstructNestedError("error during preparing a statement", err)
+	}
+	c.resolveParameterTypes(ctx, stmt, query, driverValues)
+	queryRows, err := c.ExecutePreparedQueries(ctx, stmt.Queries, driverValues, false)
+	if err != nil {
+		return nil, err
 	}
 
-	return &describeResult, nil
+	reader, err := rows.NewArrowRecordReader(queryRows, memory.DefaultAllocator, rows.DefaultArrowBatchSize)
+	if err != nil {
+		return nil, errors.Join(errorUtils.ConstructNestedError("error creating arrow record reader", err), queryRows.Close())
+	}
+	return reader, nil
 }
 
-func (c *fireboltConnection) makeRows(ctx context.Context) rows.ExtendableRowsWithResult {
+// makeRows returns the rows matching the execution mode of the context. Streaming is never silently
+// replaced by buffering, an error is returned if the result can't be streamed.
+func (c *fireboltConnection) makeRows(ctx context.Context) (rows.ExtendableRowsWithResult, error) {
 	isAsync := contextUtils.IsAsync(ctx)
+	isStreaming := contextUtils.IsStreaming(ctx)
 	if isAsync {
-		return &rows.AsyncRows{}
+		if isStreaming {
+			return nil, errorUtils.Wrap(errorUtils.StreamingNotSupportedError, errors.New("asynchronous queries don't return rows"))
+		}
+		return &rows.AsyncRows{}, nil
 	}
-	isStreaming := contextUtils.IsStreaming(ctx)
-	if isStreaming && isNewVersion(c) {
-		return &rows.StreamRows{}
+	if !isStreaming {
+		return &rows.InMemoryRows{}, nil
+	}
+	if isNewVersion(c) {
+		return &rows.StreamRows{}, nil
+	}
+	if _, isV0 := c.client.(*client.ClientImplV0); isV0 {
+		// V0 engines don't support JSONLines_Compact, JSON_Compact responses are decoded incrementally instead
+		return &rows.CompactStreamRows{}, nil
+	}
+	return nil, errorUtils.Wrap(errorUtils.StreamingNotSupportedError, fmt.Errorf("client %T can't stream results", c.client))
+}
+
+// supportsStreaming reports whether the results of the client can be streamed
+func supportsStreaming(c *fireboltConnection) bool {
+	_, isV0 := c.client.(*client.ClientImplV0)
+	return isV0 || isNewVersion(c)
+}
+
+// parameterTypesResolver describes a statement executed once if the formatting of its arguments needs it
+type parameterTypesResolver interface {
+	ResolveParameterTypes(ctx context.Context, args []driver.NamedValue, cache *statement.DescribeCache, scope string)
+}
+
+// resolveParameterTypes describes a query executed once if the formatting of its arguments depends on the types
+// of the parameters. The outcome is cached by the connector for the engine and the database of the connection,
+// so that the query isn't described on every execution. V0 clients don't support describe requests.
+func (c *fireboltConnection) resolveParameterTypes(ctx context.Context, stmt parameterTypesResolver, query string, args []driver.NamedValue) {
+	if _, isV0 := c.client.(*client.ClientImplV0); isV0 {
+		return
+	}
+	var cache *statement.DescribeCache
+	if c.connector != nil {
+		cache = &c.connector.describeCache
 	}
-	return &rows.InMemoryRows{}
+	stmt.ResolveParameterTypes(ctx, args, cache, c.engineUrl+"\x00"+c.parameters["database"])
 }
 
 func isNewVersion(c *fireboltConnection) bool {
@@ -194,7 +301,10 @@ func (c *fireboltConnection) ExecutePreparedQueries(ctx context.Context, queries
 		return nil, fmt.Errorf("multistatement is not allowed")
 	}
 
-	var rowsInst = c.makeRows(ctx)
+	rowsInst, err := c.makeRows(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	connectionControl := client.ConnectionControl{
 		UpdateParameters: c.setParameter,
@@ -203,7 +313,7 @@ func (c *fireboltConnection) ExecutePreparedQueries(ctx context.Context, queries
 	}
 
 	for _, query := range queries {
-		sql, additionalParameters, err := query.Format(args)
+		sql, additionalParameters, err := query.Format(args, sessionLocation(c.parameters))
 		if err != nil {
 			return rowsInst, errorUtils.Wrap(errorUtils.QueryExecutionError, err)
 		}
@@ -216,23 +326,60 @@ func (c *fireboltConnection) ExecutePreparedQueries(ctx context.Context, queries
 			query.OnSuccess(connectionControl)
 		}
 	}
+	// TIMESTAMPTZ values are returned in the session time zone, including the changes made by the query
+	rowsInst.SetLocation(sessionLocation(c.parameters))
 	return rowsInst, nil
 }
 
+// timeZoneParameters are the names of the parameter that sets the session time zone
+var timeZoneParameters = []string{"time_zone", "timezone"}
+
+// locations caches the loaded session time zones by their names
+var locations sync.Map
+
+// sessionLocation returns the session time zone, which is UTC unless the time zone parameter is set.
+// nil is returned if the time zone can't be loaded, then time values keep the offsets returned by the server.
+func sessionLocation(parameters map[string]string) *time.Location {
+	name := "UTC"
+	for key, value := range parameters {
+		if utils.ContainsString(timeZoneParameters, strings.ToLower(key)) {
+			name = strings.Trim(value, "'\"")
+		}
+	}
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		logging.Infolog.Printf("failed to load session time zone %s: %v", name, err)
+		location = nil
+	}
+	locations.Store(name, location)
+	return location
+}
+
+// connectionParameters are only kept by the connection that sets them, since they go with its engine URL,
+// which isn't shared with the other connections of the connector
+var connectionParameters = []string{"engine"}
+
 func (c *fireboltConnection) setParameter(key, value string) {
 	if c.parameters == nil {
 		c.parameters = make(map[string]string)
 	}
 	c.parameters[key] = value
 	// Cache parameter in connector as well in case connection will be recreated by the pool
+	c.connector.mutex.Lock()
+	defer c.connector.mutex.Unlock()
 	if c.connector.cachedParameters == nil {
 		c.connector.cachedParameters = make(map[string]string)
 	}
-	if !utils.ContainsString(statement.GetNotPersistentParametersList(), key) {
+	if !utils.ContainsString(statement.GetNotPersistentParametersList(), key) && !utils.ContainsString(connectionParameters, key) {
 		c.connector.cachedParameters[key] = value
 	}
 }
 
+// setEngineURL switches the connection to another engine until its session is reset,
+// the other connections of the connector keep theirs
 func (c *fireboltConnection) setEngineURL(engineUrl string) {
 	c.engineUrl = engineUrl
 }
@@ -246,6 +393,8 @@ func (c *fireboltConnection) resetAllParameters() {
 			}
 		}
 	}
+	c.connector.mutex.Lock()
+	defer c.connector.mutex.Unlock()
 	if c.connector.cachedParameters != nil {
 		for k := range c.connector.cachedParameters {
 			if !utils.ContainsString(ignoreParameters, k) {
@@ -263,6 +412,8 @@ func (c *fireboltConnection) resetParametersList(parametersList []string) {
 			}
 		}
 	}
+	c.connector.mutex.Lock()
+	defer c.connector.mutex.Unlock()
 	if c.connector.cachedParameters != nil {
 		for k := range c.connector.cachedParameters {
 			if utils.ContainsString(parametersList, k) {
@@ -202,7 +202,7 @@ func TestConnectionPreparedStatement(t *testing.T) {
 		tstz := time.Date(2021, 1, 1, 2, 10, 20, 3000, loc)
 		ba := []byte("hello_world_123ツ\n\u0048")
 		ge := "POINT(1 1)"
-		geEncoded := "0101000020E6100000FEFFFFFFFFFFEF3F000000000000F03F"
+		geEncoded := rows.Geography("0101000020E6100000FEFFFFFFFFFFEF3F000000000000F03F")
 
 		var sql string
 		if contextUtils.GetPreparedStatementsStyle(ctx) == contextUtils.PreparedStatementsStyleNative {
@@ -438,8 +438,8 @@ func getExpectedColumnTypes(isStreaming bool) []columnType {
 		{"col_decimal_null", "Decimal(38, 30)", reflect.TypeOf(rows.FireboltNullDecimal{}), true, true, false, 0, true, 38, 30},
 		{"col_bytea", "bytea", reflect.TypeOf([]byte{}), true, false, true, math.MaxInt64, false, 0, 0},
 		{"col_bytea_null", "bytea", reflect.TypeOf(rows.NullBytes{}), true, true, true, math.MaxInt64, false, 0, 0},
-		{"col_geography", "geography", reflect.TypeOf(""), true, false, false, 0, false, 0, 0},
-		{"col_geography_null", "geography", reflect.TypeOf(sql.NullString{}), true, true, false, 0, false, 0, 0},
+		{"col_geography", "geography", reflect.TypeOf(rows.Geography("")), true, false, false, 0, false, 0, 0},
+		{"col_geography_null", "geography", reflect.TypeOf(rows.NullGeography{}), true, true, false, 0, false, 0, 0},
 	}
 	// Some types are returned by different alias from database when streaming
 	if isStreaming {
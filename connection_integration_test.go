@@ -147,55 +147,62 @@ func TestConnectionUseDatabaseEngine(t *testing.T) {
 	const insertSQL = "INSERT INTO test_use VALUES (1)"
 	const insertSQL2 = "INSERT INTO test_use VALUES (2)"
 
-	conn, err := sql.Open("firebolt", dsnSystemEngineMock)
+	db, err := sql.Open("firebolt", dsnSystemEngineMock)
 	if err != nil {
 		t.Errorf("opening a connection failed unexpectedly")
 		t.FailNow()
 	}
+	// USE ENGINE only applies to the connection that runs it until it's returned to the pool
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Errorf("getting a connection failed unexpectedly")
+		t.FailNow()
+	}
+	defer conn.Close()
 
-	_, err = conn.Exec(createTableSQL)
+	_, err = conn.ExecContext(context.Background(), createTableSQL)
 	if err == nil {
 		t.Errorf("create table worked on a system engine without a database, while it shouldn't")
 		t.FailNow()
 	}
 
-	_, err = conn.Exec(fmt.Sprintf("USE DATABASE \"%s\"", databaseMock))
+	_, err = conn.ExecContext(context.Background(), fmt.Sprintf("USE DATABASE \"%s\"", databaseMock))
 	if err != nil {
 		t.Errorf("use database failed with %v", err)
 		t.FailNow()
 	}
 
-	_, err = conn.Exec(createTableSQL)
+	_, err = conn.ExecContext(context.Background(), createTableSQL)
 	if err != nil {
 		t.Errorf("create table failed with %v", err)
 		t.FailNow()
 	}
 
-	_, err = conn.Exec(insertSQL)
+	_, err = conn.ExecContext(context.Background(), insertSQL)
 	if err == nil {
 		t.Errorf("insert worked on a system engine, while it shouldn't")
 		t.FailNow()
 	}
 
-	_, err = conn.Exec(fmt.Sprintf("USE ENGINE \"%s\"", engineNameMock))
+	_, err = conn.ExecContext(context.Background(), fmt.Sprintf("USE ENGINE \"%s\"", engineNameMock))
 	if err != nil {
 		t.Errorf("use engine failed with %v", err)
 		t.FailNow()
 	}
 
-	_, err = conn.Exec(insertSQL)
+	_, err = conn.ExecContext(context.Background(), insertSQL)
 	if err != nil {
 		t.Errorf("insert failed with %v", err)
 		t.FailNow()
 	}
 
-	_, err = conn.Exec("USE ENGINE system")
+	_, err = conn.ExecContext(context.Background(), "USE ENGINE system")
 	if err != nil {
 		t.Errorf("use engine failed with %v", err)
 		t.FailNow()
 	}
 
-	_, err = conn.Exec(insertSQL2)
+	_, err = conn.ExecContext(context.Background(), insertSQL2)
 	if err == nil {
 		t.Errorf("insert worked on a system engine, while it shouldn't")
 		t.FailNow()
@@ -235,7 +242,7 @@ func TestConnectionPreparedStatement(t *testing.T) {
 		tstz := time.Date(2021, 1, 1, 2, 10, 20, 3000, loc)
 		ba := []byte("hello_world_123ツ\n\u0048")
 		ge := "POINT(1 1)"
-		geEncoded := "0101000020E6100000FEFFFFFFFFFFEF3F000000000000F03F"
+		geEncoded := rows.Geography("0101000020E6100000FEFFFFFFFFFFEF3F000000000000F03F")
 
 		var sql string
 		if contextUtils.GetPreparedStatementsStyle(ctx) == contextUtils.PreparedStatementsStyleNative {
@@ -602,8 +609,8 @@ func getExpectedColumnTypes(isStreaming bool) []columnType {
 		{"col_decimal_null", "Decimal(38, 30)", reflect.TypeOf(rows.FireboltNullDecimal{}), true, true, false, 0, true, 38, 30},
 		{"col_bytea", "bytea", reflect.TypeOf([]byte{}), true, false, true, math.MaxInt64, false, 0, 0},
 		{"col_bytea_null", "bytea", reflect.TypeOf(rows.NullBytes{}), true, true, true, math.MaxInt64, false, 0, 0},
-		{"col_geography", "geography", reflect.TypeOf(""), true, false, false, 0, false, 0, 0},
-		{"col_geography_null", "geography", reflect.TypeOf(sql.NullString{}), true, true, false, 0, false, 0, 0},
+		{"col_geography", "geography", reflect.TypeOf(rows.Geography("")), true, false, false, 0, false, 0, 0},
+		{"col_geography_null", "geography", reflect.TypeOf(rows.NullGeography{}), true, true, false, 0, false, 0, 0},
 	}
 	// Some types are returned by different alias from database when streaming
 	if isStreaming {
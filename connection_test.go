@@ -3,12 +3,20 @@ package fireboltgosdk
 import (
 	"bytes"
 	"context"
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/firebolt-db/firebolt-go-sdk/client"
 	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
+	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
+	"github.com/firebolt-db/firebolt-go-sdk/rows"
+	"github.com/firebolt-db/firebolt-go-sdk/statement"
 	"github.com/firebolt-db/firebolt-go-sdk/types"
 	"github.com/firebolt-db/firebolt-go-sdk/utils"
 )
@@ -40,6 +48,35 @@ func TestConnectionClose(t *testing.T) {
 	}
 }
 
+func TestCheckNamedValue(t *testing.T) {
+	fireboltConnection := fireboltConnection{}
+	for _, value := range []interface{}{[]int64{1, 2}, map[string]interface{}{"a": 1}, [][]string{{"a"}}} {
+		nv := driver.NamedValue{Ordinal: 1, Value: value}
+		if err := fireboltConnection.CheckNamedValue(&nv); err != nil {
+			t.Errorf("CheckNamedValue failed for %v: %v", value, err)
+		}
+		if !reflect.DeepEqual(nv.Value, value) {
+			t.Errorf("composite value should be passed as is, got %v", nv.Value)
+		}
+	}
+
+	nv := driver.NamedValue{Ordinal: 1, Value: int8(1)}
+	if err := fireboltConnection.CheckNamedValue(&nv); err != nil {
+		t.Errorf("CheckNamedValue failed for int8: %v", err)
+	}
+	utils.AssertEqual(nv.Value, int64(1), t, "int8 value should be converted to int64")
+
+	nv = driver.NamedValue{Ordinal: 1, Value: struct{}{}}
+	if err := fireboltConnection.CheckNamedValue(&nv); err == nil {
+		t.Errorf("CheckNamedValue should fail for an unsupported type")
+	}
+
+	nv = driver.NamedValue{Name: "ids", Ordinal: 1, Value: []interface{}{1, struct{}{}}}
+	if err := fireboltConnection.CheckNamedValue(&nv); err == nil || !strings.Contains(err.Error(), "'ids'") {
+		t.Errorf("CheckNamedValue should fail for an unsupported array element and name the parameter, got %v", err)
+	}
+}
+
 func TestSetParameter(t *testing.T) {
 
 	connector := FireboltConnector{}
@@ -285,6 +322,34 @@ func TestDescribeFunctionFbNumeric(t *testing.T) {
 	}
 }
 
+// TestQueryArrow tests that query results are returned as arrow record batches
+func TestQueryArrow(t *testing.T) {
+	mockClient := &mockClientForDescribe{}
+	fireboltConnection := fireboltConnection{mockClient, "engine_url", map[string]string{}, nil}
+
+	reader, err := fireboltConnection.QueryArrow(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("QueryArrow failed unexpectedly: %v", err)
+	}
+	defer reader.Release()
+
+	utils.AssertEqual(reader.Schema().Field(0).Name, "describe_result", t, "arrow field name doesn't match")
+	if !reader.Next() {
+		t.Fatalf("Expected a record batch, got error: %v", reader.Err())
+	}
+	utils.AssertEqual(reader.RecordBatch().NumRows(), int64(1), t, "number of rows doesn't match")
+	if reader.Next() {
+		t.Errorf("Expected a single record batch")
+	}
+	if err := reader.Err(); err != nil {
+		t.Errorf("Unexpected error reading arrow records: %v", err)
+	}
+
+	if _, err := fireboltConnection.QueryArrow(context.Background(), "SELECT 1; SELECT 2"); err == nil {
+		t.Errorf("Expected QueryArrow to reject multi-statement queries")
+	}
+}
+
 func TestDefaultQueryParamsSeededInConnection(t *testing.T) {
 	connector := FireboltConnector{}
 	connector.cachedParameters = map[string]string{
@@ -400,3 +465,245 @@ func TestTransactionCommitFailureAutoRollback(t *testing.T) {
 		t.Error("ROLLBACK was not automatically called after commit failure")
 	}
 }
+
+// TestDescribeStatement tests that server-side prepared statements can be described before execution
+func TestDescribeStatement(t *testing.T) {
+	fireboltConnection := fireboltConnection{&mockClientForDescribe{}, "engine_url", map[string]string{}, nil}
+	ctx := contextUtils.WithPreparedStatementsStyle(context.Background(), contextUtils.PreparedStatementsStyleFbNumeric)
+
+	stmt, err := fireboltConnection.PrepareContext(ctx, "SELECT 1 as col1, $1 as col2, $2 as col3")
+	if err != nil {
+		t.Fatalf("PrepareContext failed: %v", err)
+	}
+	describeStmt, ok := stmt.(DescribeStatement)
+	if !ok {
+		t.Fatalf("prepared statement doesn't implement DescribeStatement")
+	}
+	columnTypes, err := describeStmt.ColumnTypes(ctx)
+	if err != nil {
+		t.Fatalf("ColumnTypes failed: %v", err)
+	}
+	utils.AssertEqual(len(columnTypes), 3, t, "number of columns doesn't match")
+	utils.AssertEqual(columnTypes[1].Name, "col2", t, "column name doesn't match")
+	utils.AssertEqual(columnTypes[1].DatabaseTypeName, "text", t, "column type doesn't match")
+
+	if _, err = stmt.(driver.StmtExecContext).ExecContext(ctx, []driver.NamedValue{{Ordinal: 1, Value: 1}, {Ordinal: 2, Value: 2}}); err == nil {
+		t.Errorf("an integer argument for a text parameter should be rejected")
+	}
+}
+
+// TestConnectionPing tests that Ping runs a query on the engine, and fails for closed connections
+func TestConnectionPing(t *testing.T) {
+	mockClient := &mockClientForTransactionCommitFailure{}
+	connector := FireboltConnector{client: mockClient, engineUrl: "engine_url", cachedParameters: map[string]string{}}
+	conn := fireboltConnection{mockClient, "engine_url", map[string]string{}, &connector}
+
+	if err := conn.Ping(context.Background()); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+	utils.AssertEqual(mockClient.queryCalls, []string{"SELECT 1"}, t, "Ping should run a single query")
+
+	utils.AssertEqual(conn.Close(), nil, t, "Close failed")
+	utils.AssertEqual(conn.IsValid(), false, t, "closed connection should be invalid")
+	utils.AssertEqual(conn.Ping(context.Background()), driver.ErrBadConn, t, "Ping of a closed connection should return ErrBadConn")
+	utils.AssertEqual(conn.ResetSession(context.Background()), driver.ErrBadConn, t, "ResetSession of a closed connection should return ErrBadConn")
+}
+
+// TestConnectionResetSession tests that resetting a connection drops its transaction state and the engine
+// selected with USE ENGINE, and picks up the parameters shared by the connector
+func TestConnectionResetSession(t *testing.T) {
+	mockClient := &mockClientForTransactionCommitFailure{}
+	connector := FireboltConnector{client: mockClient, engineUrl: "engine_url", cachedParameters: map[string]string{"database": "db"}}
+	conn := fireboltConnection{mockClient, "engine_url", map[string]string{"database": "db"}, &connector}
+	other := fireboltConnection{mockClient, "engine_url", map[string]string{"database": "db"}, &connector}
+
+	conn.setParameter("transaction_id", "123")
+	conn.setParameter("time_zone", "UTC")
+	conn.setEngineURL("new_engine_url")
+	conn.setParameter("engine", "new_engine")
+	utils.AssertEqual(conn.IsValid(), true, t, "open connection should be valid")
+	utils.AssertEqual(conn.parameters["engine"], "new_engine", t, "engine parameter should be set on the connection")
+	utils.AssertEqual(connector.engineUrl, "engine_url", t, "USE ENGINE shouldn't change the engine URL of the connector")
+	if _, ok := connector.cachedParameters["engine"]; ok {
+		t.Errorf("engine parameter shouldn't be cached by the connector")
+	}
+
+	if err := conn.ResetSession(context.Background()); err != nil {
+		t.Fatalf("ResetSession failed: %v", err)
+	}
+	utils.AssertEqual(conn.engineUrl, "engine_url", t, "engine URL of the connector should be restored")
+	if !reflect.DeepEqual(conn.parameters, map[string]string{"database": "db", "time_zone": "UTC"}) {
+		t.Errorf("transaction parameters should be dropped, got %v", conn.parameters)
+	}
+
+	if err := other.ResetSession(context.Background()); err != nil {
+		t.Fatalf("ResetSession failed: %v", err)
+	}
+	utils.AssertEqual(other.engineUrl, "engine_url", t, "USE ENGINE shouldn't change the engine URL of other pooled connections")
+	if !reflect.DeepEqual(other.parameters, conn.parameters) {
+		t.Errorf("parameters of a pooled connection should be updated, got %v", other.parameters)
+	}
+
+	newConn, err := connector.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	utils.AssertEqual(newConn.(*fireboltConnection).engineUrl, "engine_url", t, "USE ENGINE shouldn't change the engine URL of new connections")
+}
+
+// mockClientWithStatistics records all queries and reports the number of the query as rows_read
+type mockClientWithStatistics struct {
+	mockClientForTransactionCommitFailure
+}
+
+func (m *mockClientWithStatistics) Query(ctx context.Context, engineUrl, query string, parameters map[string]string, control client.ConnectionControl) (*client.Response, error) {
+	m.queryCalls = append(m.queryCalls, query)
+
+	queryResponse := types.QueryResponse{
+		Meta:       []types.Column{},
+		Data:       [][]interface{}{},
+		Statistics: map[string]interface{}{"rows_read": len(m.queryCalls), "elapsed": 0.5},
+	}
+	responseData, _ := json.Marshal(queryResponse)
+	return client.MakeResponse(io.NopCloser(bytes.NewReader(responseData)), 200, nil, nil), nil
+}
+
+// TestExecContextMultiStatement tests that every statement of a multi-statement query is executed
+// and the statistics of every statement are returned in the result
+func TestExecContextMultiStatement(t *testing.T) {
+	mockClient := &mockClientWithStatistics{}
+	conn := fireboltConnection{client: mockClient, engineUrl: "engine_url", parameters: map[string]string{}}
+
+	result, err := conn.ExecContext(context.Background(), "INSERT INTO t VALUES (1); INSERT INTO t VALUES (2)", nil)
+	if err != nil {
+		t.Fatalf("ExecContext failed unexpectedly: %v", err)
+	}
+	utils.AssertEqual(len(mockClient.queryCalls), 2, t, "every statement should be executed")
+
+	statistics := result.(*rows.FireboltResult).Statistics()
+	utils.AssertEqual(len(statistics), 2, t, "every statement should have statistics")
+	utils.AssertEqual(statistics[0].RowsRead, int64(1), t, "rows_read of the first statement doesn't match")
+	utils.AssertEqual(statistics[1].RowsRead, int64(2), t, "rows_read of the second statement doesn't match")
+	utils.AssertEqual(statistics[1].Elapsed, 0.5, t, "elapsed of the second statement doesn't match")
+}
+
+// TestMakeRowsStreaming tests that streaming is either supported by the rows or rejected explicitly
+func TestMakeRowsStreaming(t *testing.T) {
+	streamingCtx := contextUtils.WithStreaming(context.Background())
+
+	conn := fireboltConnection{client: &client.ClientImpl{}}
+	if rowsInst, err := conn.makeRows(streamingCtx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if _, ok := rowsInst.(*rows.StreamRows); !ok {
+		t.Errorf("expected StreamRows, got %T", rowsInst)
+	}
+
+	conn = fireboltConnection{client: &client.ClientImplV0{}}
+	if rowsInst, err := conn.makeRows(streamingCtx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if _, ok := rowsInst.(*rows.CompactStreamRows); !ok {
+		t.Errorf("expected CompactStreamRows, got %T", rowsInst)
+	}
+	if rowsInst, err := conn.makeRows(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if _, ok := rowsInst.(*rows.InMemoryRows); !ok {
+		t.Errorf("expected InMemoryRows, got %T", rowsInst)
+	}
+
+	conn = fireboltConnection{client: &mockClientForDescribe{}}
+	if _, err := conn.makeRows(streamingCtx); !errors.Is(err, errorUtils.StreamingNotSupportedError) {
+		t.Errorf("expected StreamingNotSupportedError for an unknown client, got %v", err)
+	}
+	if _, err := conn.QueryContext(streamingCtx, "SELECT 1", nil); !errors.Is(err, errorUtils.StreamingNotSupportedError) {
+		t.Errorf("expected StreamingNotSupportedError from QueryContext, got %v", err)
+	}
+
+	conn = fireboltConnection{client: &client.ClientImpl{}}
+	if _, err := conn.makeRows(contextUtils.WithAsync(streamingCtx)); !errors.Is(err, errorUtils.StreamingNotSupportedError) {
+		t.Errorf("expected StreamingNotSupportedError for an async query, got %v", err)
+	}
+}
+
+// mockClientWithTimestamp returns a single TIMESTAMPTZ value for every query
+type mockClientWithTimestamp struct {
+	mockClientForTransactionCommitFailure
+}
+
+func (m *mockClientWithTimestamp) Query(ctx context.Context, engineUrl, query string, parameters map[string]string, control client.ConnectionControl) (*client.Response, error) {
+	m.queryCalls = append(m.queryCalls, query)
+
+	queryResponse := types.QueryResponse{
+		Meta: []types.Column{{Name: "tstz", Type: "timestamptz"}},
+		Data: [][]interface{}{{"2022-01-10 01:00:00.123456+01"}},
+	}
+	responseData, _ := json.Marshal(queryResponse)
+	return client.MakeResponse(io.NopCloser(bytes.NewReader(responseData)), 200, nil, nil), nil
+}
+
+// TestSessionLocation tests that the session time zone is loaded from the time zone parameters
+func TestSessionLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	utils.AssertEqual(sessionLocation(nil), time.UTC, t, "UTC should be used if the time zone isn't set")
+	utils.AssertEqual(sessionLocation(map[string]string{"time_zone": "Europe/Berlin"}).String(), berlin.String(), t, "time_zone should be used")
+	utils.AssertEqual(sessionLocation(map[string]string{"timezone": "'Europe/Berlin'"}).String(), berlin.String(), t, "quoted timezone should be used")
+	if location := sessionLocation(map[string]string{"time_zone": "Not/AZone"}); location != nil {
+		t.Errorf("unknown time zone should return nil, got %v", location)
+	}
+}
+
+// TestQueryContextTimeZone tests that time arguments and TIMESTAMPTZ values use the session time zone
+func TestQueryContextTimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	mockClient := &mockClientWithTimestamp{}
+	conn := fireboltConnection{client: mockClient, engineUrl: "engine_url", parameters: map[string]string{}, connector: &FireboltConnector{}}
+
+	if _, err = conn.ExecContext(context.Background(), "SET time_zone = Europe/Berlin", nil); err != nil {
+		t.Fatalf("ExecContext failed unexpectedly: %v", err)
+	}
+	queryRows, err := conn.QueryContext(context.Background(), "SELECT ?",
+		[]driver.NamedValue{{Ordinal: 1, Value: time.Date(2022, 1, 10, 1, 0, 0, 0, berlin)}})
+	if err != nil {
+		t.Fatalf("QueryContext failed unexpectedly: %v", err)
+	}
+	utils.AssertEqual(mockClient.queryCalls[1], "SELECT $1", t, "query with a time argument should be described")
+	// the mock doesn't return a describe result, so the argument is formatted without its type
+	utils.AssertEqual(mockClient.queryCalls[2], "SELECT '2022-01-10 01:00:00+01:00'", t, "time argument doesn't match")
+
+	dest := make([]driver.Value, 1)
+	utils.AssertEqual(queryRows.Next(dest), nil, t, "Next returned an error")
+	utils.AssertEqual(dest[0], time.Date(2022, 1, 10, 1, 0, 0, 123456000, berlin), t, "TIMESTAMPTZ value doesn't match")
+	utils.AssertEqual(dest[0].(time.Time).Location().String(), "Europe/Berlin", t, "TIMESTAMPTZ value should be in the session time zone")
+
+	if _, err = conn.QueryContext(context.Background(), "SELECT ?",
+		[]driver.NamedValue{{Ordinal: 1, Value: time.Date(2022, 1, 10, 1, 0, 0, 0, berlin)}}); err != nil {
+		t.Fatalf("QueryContext failed unexpectedly: %v", err)
+	}
+	utils.AssertEqual(mockClient.queryCalls[3:], []string{"SELECT '2022-01-10 01:00:00+01:00'"}, t, "the describe outcome of the query should be reused")
+}
+
+// parameterTypesResolverMock records the calls of ResolveParameterTypes
+type parameterTypesResolverMock struct {
+	calls int
+}
+
+func (m *parameterTypesResolverMock) ResolveParameterTypes(_ context.Context, _ []driver.NamedValue, _ *statement.DescribeCache, _ string) {
+	m.calls++
+}
+
+// TestResolveParameterTypesV0 tests that queries aren't described with V0 clients, which don't support describe requests
+func TestResolveParameterTypesV0(t *testing.T) {
+	resolver := &parameterTypesResolverMock{}
+	conn := fireboltConnection{&client.ClientImplV0{}, "engine_url", map[string]string{}, &FireboltConnector{}}
+	conn.resolveParameterTypes(context.Background(), resolver, "SELECT ?", []driver.NamedValue{{Ordinal: 1, Value: time.Now()}})
+	utils.AssertEqual(resolver.calls, 0, t, "queries shouldn't be described with a V0 client")
+
+	conn.client = &mockClientForDescribe{}
+	conn.resolveParameterTypes(context.Background(), resolver, "SELECT ?", []driver.NamedValue{{Ordinal: 1, Value: time.Now()}})
+	utils.AssertEqual(resolver.calls, 1, t, "queries should be described with other clients")
+}
@@ -53,7 +53,11 @@ func WithAsync(ctx context.Context) context.Context {
 	return context.WithValue(ctx, AsyncContextKey, true)
 }
 
+// WithDescribe returns a context describing the query instead of executing it. The description
+// is returned as a regular result, so the query is neither asynchronous nor streamed.
 func WithDescribe(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, AsyncContextKey, false)
+	ctx = context.WithValue(ctx, StreamingContextKey, false)
 	return context.WithValue(ctx, DescribeContextKey, true)
 }
 
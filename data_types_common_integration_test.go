@@ -398,6 +398,31 @@ func TestSelectArrayInt(t *testing.T) {
 	})
 }
 
+func TestSelectArrayNativeTypes(t *testing.T) {
+	utils.RunInMemoryAndStream(t, func(t *testing.T, ctx context.Context) {
+		conn, err := sql.Open("firebolt", dsnMock)
+		if err != nil {
+			t.Errorf(OPEN_CONNECTION_ERROR_MSG)
+			t.FailNow()
+		}
+		defer conn.Close()
+
+		var ints []int32
+		var longs []*int64
+		var nested [][]string
+		err = conn.QueryRowContext(ctx, "SELECT [1, 2]::ARRAY(INT), [1, NULL]::ARRAY(BIGINT NULL), [['a'], ['b', 'c']]").Scan(&ints, &longs, &nested)
+		if err != nil {
+			t.Errorf("%s: %v", scanErrorMessage, err)
+			t.FailNow()
+		}
+		utils.AssertEqual(ints, []int32{1, 2}, t, "invalid value returned for array(int)")
+		utils.AssertEqual(len(longs), 2, t, "invalid value returned for array(bigint null)")
+		utils.AssertEqual(*longs[0], int64(1), t, "invalid value returned for array(bigint null)")
+		utils.AssertEqual(longs[1] == nil, true, t, "invalid value returned for array(bigint null)")
+		utils.AssertEqual(nested, [][]string{{"a"}, {"b", "c"}}, t, "invalid value returned for array(array(text))")
+	})
+}
+
 func TestSelectArrayArrayInt(t *testing.T) {
 	utils.RunInMemoryAndStream(t, func(t *testing.T, ctx context.Context) {
 		aai, aaiNullNotNull, aaiNullNull, colTypes, cleanup := runSetupAndSelect(
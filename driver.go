@@ -4,10 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
+	"github.com/firebolt-db/firebolt-go-sdk/cache"
 	"github.com/firebolt-db/firebolt-go-sdk/client"
+	"github.com/firebolt-db/firebolt-go-sdk/statement"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
 
 	"github.com/firebolt-db/firebolt-go-sdk/errors"
 	"github.com/firebolt-db/firebolt-go-sdk/logging"
@@ -20,6 +25,21 @@ type FireboltDriver struct {
 	client       client.Client
 	lastUsedDsn  string
 	transport    http.RoundTripper
+	// responseCompression overrides the response_compression DSN parameter, if set
+	responseCompression []string
+	requestCompression  string
+	// tokenSource replaces the authentication with the DSN credentials, if set
+	tokenSource client.TokenSource
+	// cacheBackend replaces the default backend of the token and URL caches, if set
+	cacheBackend cache.Backend
+	// connectTimeout bounds the authentication and engine discovery when a connector is opened, zero means no timeout
+	connectTimeout time.Duration
+	// tls overrides the TLS settings of the DSN, field by field
+	tls types.TLSSettings
+	// network overrides the proxy, dialer and connection pool settings of the DSN, field by field
+	network types.NetworkSettings
+	// optionErr is the first error of a driver option, reported when the connector is constructed
+	optionErr error
 }
 
 // Open parses the dsn string, and if correct tries to establish a connection
@@ -39,12 +59,73 @@ func copyMap(original map[string]string) map[string]string {
 	return newMap
 }
 
+// overrideTLSSettings replaces the TLS settings parsed from the DSN by the ones set with driver options
+func overrideTLSSettings(settings *types.TLSSettings, overrides types.TLSSettings) {
+	if overrides.Config != nil {
+		settings.Config = overrides.Config
+	}
+	if overrides.CAFile != "" {
+		settings.CAFile = overrides.CAFile
+	}
+	if overrides.CertFile != "" {
+		settings.CertFile, settings.KeyFile = overrides.CertFile, overrides.KeyFile
+	}
+	if overrides.MinVersion != 0 {
+		settings.MinVersion = overrides.MinVersion
+	}
+}
+
+// overrideNetworkSettings replaces the network settings parsed from the DSN by the ones set with driver options
+func overrideNetworkSettings(settings *types.NetworkSettings, overrides types.NetworkSettings) {
+	if overrides.ProxyURL != "" || overrides.NoProxy != "" {
+		settings.ProxyURL, settings.NoProxy = overrides.ProxyURL, overrides.NoProxy
+	}
+	if overrides.DialTimeout != 0 {
+		settings.DialTimeout = overrides.DialTimeout
+	}
+	if overrides.KeepAlive != 0 {
+		settings.KeepAlive = overrides.KeepAlive
+	}
+	if overrides.MaxIdleConns != 0 {
+		settings.MaxIdleConns = overrides.MaxIdleConns
+	}
+}
+
+// setOptionError records the error of a driver option, only the first one is kept
+func (d *FireboltDriver) setOptionError(err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.optionErr == nil {
+		d.optionErr = err
+	}
+}
+
+// optionError returns the error recorded by setOptionError, options can be applied concurrently with Connect
+func (d *FireboltDriver) optionError() error {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.optionErr
+}
+
+// connectContext returns the context bounding the authentication and engine discovery when a connector is opened
+func (d *FireboltDriver) connectContext() (context.Context, context.CancelFunc) {
+	if d.connectTimeout > 0 {
+		return context.WithTimeout(context.Background(), d.connectTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
 func (d *FireboltDriver) OpenConnector(dsn string) (driver.Connector, error) {
 	logging.Infolog.Println("Opening firebolt connector")
 
 	d.mutex.RLock()
+	if d.optionErr != nil {
+		err := d.optionErr
+		d.mutex.RUnlock()
+		return nil, err
+	}
 	if d.lastUsedDsn == dsn && d.lastUsedDsn != "" {
-		connector := &FireboltConnector{d.engineUrl, d.client, copyMap(d.cachedParams), d}
+		connector := &FireboltConnector{engineUrl: d.engineUrl, client: d.client, cachedParameters: copyMap(d.cachedParams), driver: d}
 		d.mutex.RUnlock()
 		return connector, nil
 	}
@@ -54,7 +135,7 @@ func (d *FireboltDriver) OpenConnector(dsn string) (driver.Connector, error) {
 	defer d.mutex.Unlock()
 
 	if d.lastUsedDsn == dsn && d.lastUsedDsn != "" {
-		return &FireboltConnector{d.engineUrl, d.client, copyMap(d.cachedParams), d}, nil
+		return &FireboltConnector{engineUrl: d.engineUrl, client: d.client, cachedParameters: copyMap(d.cachedParams), driver: d}, nil
 	}
 
 	d.lastUsedDsn = ""
@@ -65,14 +146,28 @@ func (d *FireboltDriver) OpenConnector(dsn string) (driver.Connector, error) {
 	}
 
 	settings.Transport = d.transport
+	settings.CacheBackend = d.cacheBackend
+	if d.responseCompression != nil {
+		settings.ResponseCompression = d.responseCompression
+	}
+	settings.RequestCompression = d.requestCompression
+	overrideTLSSettings(&settings.TLS, d.tls)
+	overrideNetworkSettings(&settings.Network, d.network)
+
+	ctx, cancel := d.connectContext()
+	defer cancel()
 
 	logging.Infolog.Println("dsn parsed correctly, trying to authenticate")
-	d.client, err = client.ClientFactory(settings, client.GetHostNameURL())
+	if d.tokenSource != nil {
+		d.client, err = client.ClientFactoryWithTokenSource(ctx, settings, client.GetHostNameURL(), d.tokenSource)
+	} else {
+		d.client, err = client.ClientFactory(ctx, settings, client.GetHostNameURL())
+	}
 	if err != nil {
 		return nil, errors.ConstructNestedError("error during initializing client", err)
 	}
 
-	d.engineUrl, d.cachedParams, err = d.client.GetConnectionParameters(context.TODO(), settings.EngineName, settings.Database)
+	d.engineUrl, d.cachedParams, err = d.client.GetConnectionParameters(ctx, settings.EngineName, settings.Database)
 	if err != nil {
 		return nil, errors.ConstructNestedError("error during getting connection parameters", err)
 	}
@@ -89,7 +184,7 @@ func (d *FireboltDriver) OpenConnector(dsn string) (driver.Connector, error) {
 
 	d.lastUsedDsn = dsn
 
-	return &FireboltConnector{d.engineUrl, d.client, copyMap(d.cachedParams), d}, nil
+	return &FireboltConnector{engineUrl: d.engineUrl, client: d.client, cachedParameters: copyMap(d.cachedParams), driver: d}, nil
 }
 
 // FireboltConnector is an intermediate type between a Connection and a Driver which stores session data
@@ -98,12 +193,45 @@ type FireboltConnector struct {
 	client           client.Client
 	cachedParameters map[string]string
 	driver           *FireboltDriver
+	// mutex guards cachedParameters, which are shared by all the connections of the connector
+	mutex sync.Mutex
+	// describeCache keeps the describe results of the queries executed once by the connections of the connector
+	describeCache statement.DescribeCache
 }
 
 // Connect returns a connection to the database
 func (c *FireboltConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.driver != nil {
+		if err := c.driver.optionError(); err != nil {
+			return nil, err
+		}
+	}
 	logging.Infolog.Printf("firebolt connection is created")
-	return &fireboltConnection{c.client, c.engineUrl, copyMap(c.cachedParameters), c}, nil
+	engineUrl, parameters := c.session()
+	return &fireboltConnection{c.client, engineUrl, parameters, c}, nil
+}
+
+// session returns the engine URL and a copy of the parameters a new connection starts with
+func (c *FireboltConnector) session() (string, map[string]string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.engineUrl, copyMap(c.cachedParameters)
+}
+
+// TokenExpiry returns the time the access token used by the connector expires at, a zero time means
+// the token doesn't expire. The SDK refreshes the token in the background before it expires.
+func (c *FireboltConnector) TokenExpiry(ctx context.Context) (time.Time, error) {
+	tokenClient, ok := c.client.(interface {
+		Token(context.Context) (*client.Token, error)
+	})
+	if !ok {
+		return time.Time{}, errors.ConstructNestedError("error getting token expiry", fmt.Errorf("%T doesn't manage access tokens", c.client))
+	}
+	token, err := tokenClient.Token(ctx)
+	if err != nil {
+		return time.Time{}, errors.ConstructNestedError("error getting token expiry", err)
+	}
+	return token.Expiry, nil
 }
 
 // Driver returns the underlying driver of the Connector
@@ -2,10 +2,15 @@ package fireboltgosdk
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/firebolt-db/firebolt-go-sdk/cache"
 	"github.com/firebolt-db/firebolt-go-sdk/client"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
 )
 
 type driverOption func(d *FireboltDriver)
@@ -79,12 +84,29 @@ func withClientOption(setter func(baseClient *client.BaseClient)) driverOption {
 // WithToken defines token for the driver
 func WithToken(token string) driverOption {
 	return withClientOption(func(baseClient *client.BaseClient) {
-		baseClient.AccessTokenGetter = func() (string, error) {
+		baseClient.AccessTokenGetter = func(context.Context) (string, error) {
 			return token, nil
 		}
 	})
 }
 
+// WithTokenSource makes the driver get its access tokens from a custom source, e.g. Vault,
+// a secrets manager or a file rotated by a sidecar, instead of authenticating with the
+// client ID and secret. The SDK caches the tokens until they expire or are rejected by the server.
+//
+//	source := client.FileTokenSource("/var/run/secrets/firebolt/token", time.Minute)
+//	connector, _ := firebolt.OpenConnectorWithDSN("firebolt:///my_db?account_name=my_account&engine=my_engine", firebolt.WithTokenSource(source))
+func WithTokenSource(source client.TokenSource) driverOption {
+	return func(d *FireboltDriver) {
+		withClientOption(func(baseClient *client.BaseClient) {
+			baseClient.SetTokenSource(source)
+		})(d)
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.tokenSource = source
+	}
+}
+
 // WithUserAgent defines user agent for the driver
 func WithUserAgent(userAgent string) driverOption {
 	return withClientOption(func(baseClient *client.BaseClient) {
@@ -134,8 +156,10 @@ func WithDatabaseAndEngineName(databaseName, engineName string) driverOptionWith
 		if d.client == nil {
 			return errors.New("client must be initialized before setting database and engine name")
 		}
+		ctx, cancel := d.connectContext()
+		defer cancel()
 		var err error
-		d.engineUrl, d.cachedParams, err = d.client.GetConnectionParameters(context.TODO(), engineName, databaseName)
+		d.engineUrl, d.cachedParams, err = d.client.GetConnectionParameters(ctx, engineName, databaseName)
 		if err != nil {
 			return err
 		}
@@ -160,6 +184,162 @@ func WithTransport(transport http.RoundTripper) driverOption {
 	}
 }
 
+// WithTLSConfig sets the base TLS configuration of the connections to Firebolt, e.g. to set cipher suites
+// or a custom certificate verification. The CA bundle, client certificate and minimum TLS version set with
+// the DSN or the other TLS options are applied on top of it. The configuration is applied to a clone of
+// the transport set with WithTransport, which must then be an *http.Transport.
+func WithTLSConfig(config *tls.Config) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.tls.Config = config
+	}
+}
+
+// WithCAFile makes the SDK trust the root CAs of the PEM bundle at path instead of the system ones,
+// e.g. for engines behind a TLS-terminating proxy with a private CA. Overrides the tls_ca_file DSN parameter.
+func WithCAFile(path string) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.tls.CAFile = path
+	}
+}
+
+// WithClientCertificate makes the SDK authenticate with the PEM client certificate and key for mutual TLS.
+// Overrides the tls_cert_file and tls_key_file DSN parameters.
+func WithClientCertificate(certFile, keyFile string) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.tls.CertFile, d.tls.KeyFile = certFile, keyFile
+	}
+}
+
+// WithTLSMinVersion sets the minimum TLS version, e.g. tls.VersionTLS13. Overrides the tls_min_version DSN parameter.
+func WithTLSMinVersion(version uint16) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.tls.MinVersion = version
+	}
+}
+
+// WithProxy sends the requests through the HTTP, HTTPS or SOCKS5 proxy at proxyURL, instead of the proxy
+// of the environment, except for the hosts matching noProxy, a comma separated list with the semantics of
+// the NO_PROXY environment variable. An empty proxyURL keeps the proxy of the environment and only applies noProxy.
+// Overrides the proxy_url and no_proxy DSN parameters.
+//
+//	connector, _ := firebolt.OpenConnectorWithDSN(dsn, firebolt.WithProxy("http://proxy.internal:3128", ".internal,10.0.0.0/8"))
+func WithProxy(proxyURL, noProxy string) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.network.ProxyURL, d.network.NoProxy = proxyURL, noProxy
+	}
+}
+
+// WithDialTimeout bounds the establishment of each TCP connection, 30 seconds by default.
+// Overrides the connect_timeout DSN parameter. Unlike WithConnectTimeout, it doesn't bound the whole handshake.
+func WithDialTimeout(timeout time.Duration) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.network.DialTimeout = timeout
+	}
+}
+
+// WithKeepAlive sets the interval of the TCP keep-alive probes, 30 seconds by default.
+// Overrides the keepalive DSN parameter.
+func WithKeepAlive(interval time.Duration) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.network.KeepAlive = interval
+	}
+}
+
+// WithMaxIdleConns bounds the number of idle connections kept open across all hosts, 100 by default.
+// Overrides the max_idle_conns DSN parameter.
+func WithMaxIdleConns(maxIdleConns int) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.network.MaxIdleConns = maxIdleConns
+	}
+}
+
+// WithConnectTimeout bounds the whole handshake done when a connector is opened: authentication,
+// system engine URL resolution and the USE DATABASE and USE ENGINE statements.
+// Without it, the handshake is only bound by the timeouts of the transport.
+func WithConnectTimeout(timeout time.Duration) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.connectTimeout = timeout
+	}
+}
+
+// WithCacheBackend makes the connector store its access tokens and system engine URLs in backend as well as in memory,
+// so that short-lived processes sharing the backend reuse them instead of authenticating again.
+// It overrides the default backend set with client.SetCacheBackend for this connector only.
+//
+//	backend, _ := cache.NewFileBackend(filepath.Join(os.TempDir(), "firebolt-cache"), secret)
+//	connector, _ := firebolt.OpenConnectorWithDSN(dsn, firebolt.WithCacheBackend(backend))
+func WithCacheBackend(backend cache.Backend) driverOption {
+	return func(d *FireboltDriver) {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.cacheBackend = backend
+	}
+}
+
+// WithResponseCompression defines the compression algorithms to request for query results,
+// in the order of preference: client.CompressionZstd, client.CompressionGzip or
+// client.CompressionNone to disable compression. Compressed responses are decompressed
+// as they are read. Overrides the response_compression DSN parameter.
+//
+//	connector, _ := firebolt.OpenConnectorWithDSN(dsn, firebolt.WithResponseCompression(client.CompressionZstd, client.CompressionGzip))
+func WithResponseCompression(algorithms ...string) driverOption {
+	return func(d *FireboltDriver) {
+		withClientOption(func(baseClient *client.BaseClient) {
+			baseClient.ResponseCompression = algorithms
+		})(d)
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.responseCompression = algorithms
+	}
+}
+
+// WithRequestCompression defines the algorithm used to compress request bodies:
+// client.CompressionZstd, client.CompressionGzip or client.CompressionNone (default).
+// Batch uploads are always compressed, SQL statements only when they are large enough
+// for the compression to pay off. The body is sent with the Content-Encoding header.
+//
+// An unsupported algorithm makes the construction of the connector fail.
+func WithRequestCompression(algorithm string) driverOption {
+	return func(d *FireboltDriver) {
+		algorithms, err := client.ParseCompression(algorithm)
+		if err == nil && len(algorithms) != 1 {
+			err = errors.New("a single algorithm is expected")
+		}
+		if err != nil {
+			d.setOptionError(fmt.Errorf("invalid request compression %q: %w", algorithm, err))
+			return
+		}
+		algorithm = algorithms[0]
+		if algorithm == client.CompressionNone {
+			algorithm = ""
+		}
+		withClientOption(func(baseClient *client.BaseClient) {
+			baseClient.RequestCompression = algorithm
+		})(d)
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.requestCompression = algorithm
+	}
+}
+
 // WithDefaultQueryParams defines default query parameters that will be seeded into the connection
 // These parameters will be included in all HTTP requests and can be overridden by SET statements
 func WithDefaultQueryParams(params map[string]string) driverOption {
@@ -199,19 +379,36 @@ func OpenConnectorWithDSN(dsn string, opts ...driverOption) (*FireboltConnector,
 	return c.(*FireboltConnector), nil
 }
 
-// FireboltConnectorWithOptions builds a custom connector
+// applyTransportOptions configures the HTTP client of a client built by the options with the transport,
+// TLS and network settings set with WithTransport, the TLS options and the WithProxy, WithDialTimeout,
+// WithKeepAlive and WithMaxIdleConns options, as OpenConnector does for the client it builds from the DSN
+func (d *FireboltDriver) applyTransportOptions() {
+	clientImpl, ok := d.client.(*client.ClientImpl)
+	if !ok || (d.transport == nil && !d.tls.IsSet() && !d.network.IsSet()) {
+		return
+	}
+	transport, err := client.NewTransport(&types.FireboltSettings{Transport: d.transport, TLS: d.tls, Network: d.network})
+	if err != nil {
+		d.setOptionError(fmt.Errorf("error during configuring the transport: %w", err))
+		return
+	}
+	clientImpl.HttpClient = client.NewHttpClientWithTransport(transport)
+}
+
+// FireboltConnectorWithOptions builds a custom connector, an invalid option makes Connect fail
 func FireboltConnectorWithOptions(opts ...driverOption) *FireboltConnector {
 	d := &FireboltDriver{}
 
 	for _, opt := range opts {
 		opt(d)
 	}
+	d.applyTransportOptions()
 
 	return &FireboltConnector{
-		d.engineUrl,
-		d.client,
-		d.cachedParams,
-		d,
+		engineUrl:        d.engineUrl,
+		client:           d.client,
+		cachedParameters: d.cachedParams,
+		driver:           d,
 	}
 }
 
@@ -224,11 +421,15 @@ func FireboltConnectorWithOptionsWithErrors(opts ...driverOptionWithError) (*Fir
 			return nil, err
 		}
 	}
+	d.applyTransportOptions()
+	if d.optionErr != nil {
+		return nil, d.optionErr
+	}
 
 	return &FireboltConnector{
-		d.engineUrl,
-		d.client,
-		d.cachedParams,
-		d,
+		engineUrl:        d.engineUrl,
+		client:           d.client,
+		cachedParameters: d.cachedParams,
+		driver:           d,
 	}, nil
 }
@@ -22,7 +22,7 @@ import (
 )
 
 func TestFireboltConnectorWithOptions(t *testing.T) {
-	cl, err := client.ClientFactory(&types.FireboltSettings{
+	cl, err := client.ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     clientIdMock,
 		ClientSecret: clientSecretMock,
 		AccountName:  accountName,
@@ -34,7 +34,7 @@ func TestFireboltConnectorWithOptions(t *testing.T) {
 		t.Errorf("failed to authenticate with client id %s: %v", clientIdMock, err)
 		t.FailNow()
 	}
-	token, err := cl.(*client.ClientImpl).AccessTokenGetter()
+	token, err := cl.(*client.ClientImpl).AccessTokenGetter(context.TODO())
 	if err != nil {
 		t.Errorf("failed to get access token: %v", err)
 		t.FailNow()
@@ -80,7 +80,7 @@ func TestFireboltConnectorWithOptions(t *testing.T) {
 }
 
 func makeConnectionWithSeparatedOptions(t *testing.T) *FireboltConnector {
-	cl, err := client.ClientFactory(&types.FireboltSettings{
+	cl, err := client.ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     clientIdMock,
 		ClientSecret: clientSecretMock,
 		AccountName:  accountName,
@@ -92,7 +92,7 @@ func makeConnectionWithSeparatedOptions(t *testing.T) *FireboltConnector {
 		t.Errorf("failed to authenticate with client id %s: %v", clientIdMock, err)
 		t.FailNow()
 	}
-	token, err := cl.(*client.ClientImpl).AccessTokenGetter()
+	token, err := cl.(*client.ClientImpl).AccessTokenGetter(context.TODO())
 	if err != nil {
 		t.Errorf("failed to get access token: %v", err)
 		t.FailNow()
@@ -179,7 +179,7 @@ func TestFireboltConnectorWithOptionsInvalidToken(t *testing.T) {
 	token := "invalid token"
 	userAgent := "test user agent"
 
-	cl, err := client.ClientFactory(&types.FireboltSettings{
+	cl, err := client.ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     clientIdMock,
 		ClientSecret: clientSecretMock,
 		AccountName:  accountName,
@@ -219,7 +219,7 @@ func TestFireboltConnectorWithOptionsInvalidToken(t *testing.T) {
 }
 
 func TestFireboltConnectorWithOptionsWithErrors(t *testing.T) {
-	cl, err := client.ClientFactory(&types.FireboltSettings{
+	cl, err := client.ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     clientIdMock,
 		ClientSecret: clientSecretMock,
 		AccountName:  accountName,
@@ -231,7 +231,7 @@ func TestFireboltConnectorWithOptionsWithErrors(t *testing.T) {
 		t.Errorf("failed to authenticate with client id %s: %v", clientIdMock, err)
 		t.FailNow()
 	}
-	token, err := cl.(*client.ClientImpl).AccessTokenGetter()
+	token, err := cl.(*client.ClientImpl).AccessTokenGetter(context.TODO())
 	if err != nil {
 		t.Errorf("failed to get access token: %v", err)
 		t.FailNow()
@@ -274,7 +274,7 @@ func TestFireboltConnectorWithOptionsWithErrors(t *testing.T) {
 }
 
 func TestFireboltConnectorWithOptionsWithErrorsHandleErrors(t *testing.T) {
-	cl, err := client.ClientFactory(&types.FireboltSettings{
+	cl, err := client.ClientFactory(context.TODO(), &types.FireboltSettings{
 		ClientID:     clientIdMock,
 		ClientSecret: clientSecretMock,
 		AccountName:  accountName,
@@ -286,7 +286,7 @@ func TestFireboltConnectorWithOptionsWithErrorsHandleErrors(t *testing.T) {
 		t.Errorf("failed to authenticate with client id %s: %v", clientIdMock, err)
 		t.FailNow()
 	}
-	token, err := cl.(*client.ClientImpl).AccessTokenGetter()
+	token, err := cl.(*client.ClientImpl).AccessTokenGetter(context.TODO())
 	if err != nil {
 		t.Errorf("failed to get access token: %v", err)
 		t.FailNow()
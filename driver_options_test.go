@@ -1,13 +1,20 @@
 package fireboltgosdk
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"net"
 	"net/http"
+	"net/url"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/firebolt-db/firebolt-go-sdk/cache"
 	"github.com/firebolt-db/firebolt-go-sdk/client"
 
+	"github.com/firebolt-db/firebolt-go-sdk/types"
 	"github.com/firebolt-db/firebolt-go-sdk/utils"
 )
 
@@ -34,7 +41,7 @@ func TestDriverOptions(t *testing.T) {
 	utils.AssertEqual(connectionAccountID, accountID, t, "accountID is invalid")
 	utils.AssertEqual(cl.UserAgent, userAgent, t, "userAgent is invalid")
 
-	tok, err := cl.AccessTokenGetter()
+	tok, err := cl.AccessTokenGetter(context.TODO())
 	if err != nil {
 		t.Errorf("token getter returned an error: %v", err)
 	}
@@ -67,7 +74,7 @@ func TestDriverOptionsSeparateClientParams(t *testing.T) {
 	utils.AssertEqual(connectionAccountID, accountID, t, "accountID is invalid")
 	utils.AssertEqual(cl.UserAgent, userAgent, t, "userAgent is invalid")
 
-	tok, err := cl.AccessTokenGetter()
+	tok, err := cl.AccessTokenGetter(context.TODO())
 	if err != nil {
 		t.Errorf("token getter returned an error: %v", err)
 	}
@@ -183,3 +190,204 @@ func TestWithTransportAcceptsCustomRoundTripper(t *testing.T) {
 		t.Error("expected transport NOT to be *http.Transport")
 	}
 }
+
+func TestWithTokenSource(t *testing.T) {
+	source := client.StaticTokenSource("source-token")
+	conn := FireboltConnectorWithOptions(
+		WithEngineUrl("https://engine.url"),
+		WithTokenSource(source),
+	)
+
+	cl, ok := conn.client.(*client.ClientImpl)
+	utils.AssertEqual(ok, true, t, "client is not *ClientImpl")
+
+	tok, err := cl.AccessTokenGetter(context.TODO())
+	if err != nil {
+		t.Errorf("token getter returned an error: %v", err)
+	}
+	utils.AssertEqual(tok, "source-token", t, "token getter returned wrong token")
+	utils.AssertEqual(conn.driver.tokenSource != nil, true, t, "token source is not stored in the driver")
+}
+
+func TestWithCacheBackend(t *testing.T) {
+	backend, err := cache.NewFileBackend(filepath.Join(t.TempDir(), "cache"), []byte("secret"))
+	utils.RaiseIfError(t, err)
+	conn := FireboltConnectorWithOptions(WithCacheBackend(backend))
+	utils.AssertEqual(conn.driver.cacheBackend, cache.Backend(backend), t, "cache backend is not stored in the driver")
+}
+
+func TestConnectorTokenExpiry(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	conn := FireboltConnectorWithOptions(
+		WithTokenSource(client.TokenSourceFunc(func(context.Context) (*client.Token, error) {
+			return &client.Token{AccessToken: "source-token", Expiry: expiry}, nil
+		})),
+	)
+	tokenExpiry, err := conn.TokenExpiry(context.TODO())
+	if err != nil {
+		t.Errorf("TokenExpiry returned an error: %v", err)
+	}
+	utils.AssertEqual(tokenExpiry.Equal(expiry), true, t, "wrong token expiry")
+
+	conn = FireboltConnectorWithOptions(WithToken("static-token"))
+	if _, err = conn.TokenExpiry(context.TODO()); err == nil {
+		t.Errorf("expected an error for a token that is not managed by the SDK")
+	}
+}
+
+func TestTLSOptionsOverrideDSN(t *testing.T) {
+	config := &tls.Config{ServerName: "custom"}
+	d := &FireboltDriver{}
+	WithTLSConfig(config)(d)
+	WithCAFile("/option/ca.pem")(d)
+	WithClientCertificate("/option/client.pem", "/option/client-key.pem")(d)
+	WithTLSMinVersion(tls.VersionTLS13)(d)
+
+	settings, err := ParseDSNString("firebolt:///test_db?url=https://my-svc:443&tls_ca_file=/dsn/ca.pem&tls_cert_file=/dsn/client.pem&tls_key_file=/dsn/client-key.pem&tls_min_version=1.2")
+	utils.RaiseIfError(t, err)
+	overrideTLSSettings(&settings.TLS, d.tls)
+	utils.AssertEqual(settings.TLS, types.TLSSettings{
+		Config:     config,
+		CAFile:     "/option/ca.pem",
+		CertFile:   "/option/client.pem",
+		KeyFile:    "/option/client-key.pem",
+		MinVersion: tls.VersionTLS13,
+	}, t, "driver options should override the TLS settings of the DSN")
+
+	// the settings not set with options are kept
+	settings, err = ParseDSNString("firebolt:///test_db?url=https://my-svc:443&tls_ca_file=/dsn/ca.pem&tls_min_version=1.2")
+	utils.RaiseIfError(t, err)
+	d = &FireboltDriver{}
+	WithClientCertificate("/option/client.pem", "/option/client-key.pem")(d)
+	overrideTLSSettings(&settings.TLS, d.tls)
+	utils.AssertEqual(settings.TLS, types.TLSSettings{
+		CAFile:     "/dsn/ca.pem",
+		CertFile:   "/option/client.pem",
+		KeyFile:    "/option/client-key.pem",
+		MinVersion: tls.VersionTLS12,
+	}, t, "TLS settings of the DSN should be kept")
+}
+
+func TestTLSOptionsAppliedWithOptions(t *testing.T) {
+	conn := FireboltConnectorWithOptions(
+		WithEngineUrl("https://engine.url"),
+		WithToken("token"),
+		WithTLSMinVersion(tls.VersionTLS13),
+	)
+	utils.RaiseIfError(t, conn.driver.optionErr)
+	cl, ok := conn.client.(*client.ClientImpl)
+	utils.AssertEqual(ok, true, t, "client is not *ClientImpl")
+	transport, ok := cl.HttpClient.Transport.(*http.Transport)
+	utils.AssertEqual(ok, true, t, "transport is not *http.Transport")
+	utils.AssertEqual(transport.TLSClientConfig.MinVersion, uint16(tls.VersionTLS13), t, "the TLS options should be applied to the client")
+
+	// a CA bundle that can't be read is reported instead of being ignored
+	_, err := FireboltConnectorWithOptionsWithErrors(
+		NoError(WithEngineUrl("https://engine.url")),
+		NoError(WithToken("token")),
+		NoError(WithCAFile("/nonexistent/ca.pem")),
+	)
+	if err == nil {
+		t.Errorf("expected an error for a CA bundle that can't be read")
+	}
+	conn = FireboltConnectorWithOptions(WithEngineUrl("https://engine.url"), WithToken("token"), WithCAFile("/nonexistent/ca.pem"))
+	if _, err = conn.Connect(context.TODO()); err == nil {
+		t.Errorf("expected Connect to fail for a CA bundle that can't be read")
+	}
+}
+
+func TestNetworkOptionsAppliedWithOptions(t *testing.T) {
+	conn := FireboltConnectorWithOptions(
+		WithEngineUrl("https://engine.url"),
+		WithToken("token"),
+		WithProxy("http://proxy.internal:3128", ".internal"),
+		WithMaxIdleConns(5),
+	)
+	utils.RaiseIfError(t, conn.driver.optionErr)
+	cl, ok := conn.client.(*client.ClientImpl)
+	utils.AssertEqual(ok, true, t, "client is not *ClientImpl")
+	transport, ok := cl.HttpClient.Transport.(*http.Transport)
+	utils.AssertEqual(ok, true, t, "transport is not *http.Transport")
+	utils.AssertEqual(transport.MaxIdleConns, 5, t, "the network options should be applied to the client")
+	proxyURL, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "engine.url"}})
+	utils.RaiseIfError(t, err)
+	utils.AssertEqual(proxyURL.String(), "http://proxy.internal:3128", t, "the proxy option should be applied to the client")
+
+	// a network option that can't be applied is reported instead of being ignored
+	_, err = FireboltConnectorWithOptionsWithErrors(
+		NoError(WithToken("token")),
+		NoError(WithProxy("ftp://proxy.internal", "")),
+	)
+	if err == nil {
+		t.Errorf("expected an error for an unsupported proxy scheme")
+	}
+}
+
+// TestConnectReportsOptionErrorConcurrently checks that Connect reports an option error recorded concurrently
+func TestConnectReportsOptionErrorConcurrently(t *testing.T) {
+	conn := FireboltConnectorWithOptions(WithEngineUrl("https://engine.url"), WithToken("token"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn.driver.setOptionError(errors.New("option error"))
+	}()
+	_, _ = conn.Connect(context.Background())
+	<-done
+	if _, err := conn.Connect(context.Background()); err == nil {
+		t.Errorf("expected the option error to be returned by Connect")
+	}
+}
+
+func TestNetworkOptionsOverrideDSN(t *testing.T) {
+	d := &FireboltDriver{}
+	WithProxy("http://option-proxy:3128", ".option")(d)
+	WithDialTimeout(time.Second)(d)
+	WithKeepAlive(time.Minute)(d)
+	WithMaxIdleConns(5)(d)
+
+	settings, err := ParseDSNString("firebolt:///test_db?account_name=test_acc&proxy_url=http://dsn-proxy:3128&no_proxy=.dsn&connect_timeout=5s&max_idle_conns=20")
+	utils.RaiseIfError(t, err)
+	overrideNetworkSettings(&settings.Network, d.network)
+	utils.AssertEqual(settings.Network, types.NetworkSettings{
+		ProxyURL:     "http://option-proxy:3128",
+		NoProxy:      ".option",
+		DialTimeout:  time.Second,
+		KeepAlive:    time.Minute,
+		MaxIdleConns: 5,
+	}, t, "driver options should override the network settings of the DSN")
+
+	// the settings not set with options are kept
+	settings, err = ParseDSNString("firebolt:///test_db?account_name=test_acc&proxy_url=http://dsn-proxy:3128&no_proxy=.dsn&keepalive=10s")
+	utils.RaiseIfError(t, err)
+	d = &FireboltDriver{}
+	WithMaxIdleConns(5)(d)
+	overrideNetworkSettings(&settings.Network, d.network)
+	utils.AssertEqual(settings.Network, types.NetworkSettings{
+		ProxyURL:     "http://dsn-proxy:3128",
+		NoProxy:      ".dsn",
+		KeepAlive:    10 * time.Second,
+		MaxIdleConns: 5,
+	}, t, "network settings of the DSN should be kept")
+}
+
+func TestWithRequestCompressionValidation(t *testing.T) {
+	conn := FireboltConnectorWithOptions(WithEngineUrl("https://engine.url"), WithRequestCompression(client.CompressionZstd))
+	cl, ok := conn.client.(*client.ClientImpl)
+	utils.AssertEqual(ok, true, t, "client is not *ClientImpl")
+	utils.AssertEqual(cl.RequestCompression, client.CompressionZstd, t, "request compression is not set on the client")
+
+	conn = FireboltConnectorWithOptions(WithRequestCompression(client.CompressionNone))
+	utils.AssertEqual(conn.driver.requestCompression, "", t, "none should disable request compression")
+
+	for _, algorithm := range []string{"lz4", "zstd,gzip"} {
+		if _, err := OpenConnectorWithDSN("firebolt://db", WithRequestCompression(algorithm)); err == nil {
+			t.Errorf("expected OpenConnectorWithDSN to fail for request compression %q", algorithm)
+		}
+		if _, err := FireboltConnectorWithOptionsWithErrors(NoError(WithRequestCompression(algorithm))); err == nil {
+			t.Errorf("expected FireboltConnectorWithOptionsWithErrors to fail for request compression %q", algorithm)
+		}
+		if _, err := FireboltConnectorWithOptions(WithRequestCompression(algorithm)).Connect(context.TODO()); err == nil {
+			t.Errorf("expected Connect to fail for request compression %q", algorithm)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package fireboltgosdk
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/firebolt-db/firebolt-go-sdk/client"
 	"github.com/firebolt-db/firebolt-go-sdk/utils"
@@ -66,3 +69,28 @@ func TestDriverOpenFail(t *testing.T) {
 		t.Errorf("missing username in dsn should result into sql.Open error")
 	}
 }
+
+// TestOpenConnectorConnectTimeout tests that the connect timeout bounds the authentication
+func TestOpenConnectorConnectTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write(utils.GetAuthResponse(3600))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	currentEndpoint := os.Getenv("FIREBOLT_ENDPOINT")
+	utils.Must(os.Setenv("FIREBOLT_ENDPOINT", server.URL))
+	defer func() { utils.Must(os.Setenv("FIREBOLT_ENDPOINT", currentEndpoint)) }()
+
+	start := time.Now()
+	_, err := OpenConnectorWithDSN("firebolt:///db?account_name=acc&client_id=timeout_client_id&client_secret=secret",
+		WithConnectTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("opening the connector wasn't bound by the connect timeout, took %s", elapsed)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/firebolt-db/firebolt-go-sdk/client"
 	"github.com/firebolt-db/firebolt-go-sdk/types"
 
 	"github.com/firebolt-db/firebolt-go-sdk/logging"
@@ -63,6 +65,12 @@ func makeSettings(dsnMatch []string) (*types.FireboltSettings, error) {
 			continue
 		}
 
+		if isTransportParameter, err := setTransportParameter(&result, key, decodedValue); err != nil {
+			return nil, err
+		} else if isTransportParameter {
+			continue
+		}
+
 		// Handle regular parameters
 		switch key {
 		case "account_name":
@@ -74,7 +82,15 @@ func makeSettings(dsnMatch []string) (*types.FireboltSettings, error) {
 		case "client_secret":
 			result.ClientSecret = decodedValue
 		case "url":
-			result.Url = decodedValue
+			engineURLs := splitEngineURLs(decodedValue)
+			if len(engineURLs) == 0 {
+				return nil, errors.New("the url parameter must not be empty")
+			}
+			result.Url = engineURLs[0]
+			// several comma separated URLs are balanced across by the client
+			if len(engineURLs) > 1 {
+				result.EngineURLs = engineURLs
+			}
 		case "client_side_lb":
 			result.ClientSideLB = decodedValue == "true"
 		case "client_side_lb_dns_ttl":
@@ -83,13 +99,54 @@ func makeSettings(dsnMatch []string) (*types.FireboltSettings, error) {
 				return nil, fmt.Errorf("invalid client_side_lb_dns_ttl value %q: %w", decodedValue, err)
 			}
 			result.DNSTTL = d
+		case "client_side_lb_policy":
+			if _, err := client.ParseLoadBalancingPolicy(decodedValue); err != nil {
+				return nil, fmt.Errorf("invalid client_side_lb_policy value: %w", err)
+			}
+			result.LBPolicy = decodedValue
+		case "client_side_lb_srv":
+			result.SRVName = decodedValue
+		case "client_side_lb_dns_server":
+			server, err := client.ParseDNSServer(decodedValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client_side_lb_dns_server value: %w", err)
+			}
+			result.DNSServer = server
+		case "response_compression":
+			compression, err := client.ParseCompression(decodedValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid response_compression value %q: %w", decodedValue, err)
+			}
+			result.ResponseCompression = compression
 		default:
 			return nil, fmt.Errorf("unknown parameter name %s", key)
 		}
 	}
+	if result.EngineURLs != nil && !result.ClientSideLB {
+		return nil, errors.New("several engine URLs require client_side_lb to be enabled")
+	}
+	if result.SRVName != "" {
+		if result.Url == "" || !result.ClientSideLB {
+			return nil, errors.New("client_side_lb_srv requires url and client_side_lb to be enabled")
+		}
+		if result.EngineURLs != nil {
+			return nil, errors.New("client_side_lb_srv can't be used with several engine URLs")
+		}
+	}
 	return &result, nil
 }
 
+// splitEngineURLs splits a comma separated list of engine URLs, dropping the empty ones
+func splitEngineURLs(value string) []string {
+	var urls []string
+	for _, engineURL := range strings.Split(value, ",") {
+		if engineURL = strings.TrimSpace(engineURL); engineURL != "" {
+			urls = append(urls, engineURL)
+		}
+	}
+	return urls
+}
+
 func makeSettingsV0(dsnMatch []string) (*types.FireboltSettings, error) {
 	var result types.FireboltSettings
 	result.DefaultQueryParams = make(map[string]string)
@@ -123,10 +180,22 @@ func makeSettingsV0(dsnMatch []string) (*types.FireboltSettings, error) {
 			continue
 		}
 
+		if isTransportParameter, err := setTransportParameter(&result, key, decodedValue); err != nil {
+			return nil, err
+		} else if isTransportParameter {
+			continue
+		}
+
 		// Handle regular parameters
 		switch key {
 		case "account_name":
 			result.AccountName = decodedValue
+		case "response_compression":
+			compression, err := client.ParseCompression(decodedValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid response_compression value %q: %w", decodedValue, err)
+			}
+			result.ResponseCompression = compression
 		default:
 			return nil, fmt.Errorf("unknown parameter name %s", key)
 		}
@@ -135,6 +204,68 @@ func makeSettingsV0(dsnMatch []string) (*types.FireboltSettings, error) {
 	return &result, nil
 }
 
+// setTransportParameter sets the setting of a TLS, proxy, dialer or connection pool parameter,
+// it returns false for other parameters
+func setTransportParameter(settings *types.FireboltSettings, key, value string) (bool, error) {
+	switch key {
+	case "tls_ca_file":
+		settings.TLS.CAFile = value
+	case "tls_cert_file":
+		settings.TLS.CertFile = value
+	case "tls_key_file":
+		settings.TLS.KeyFile = value
+	case "tls_min_version":
+		version, err := client.ParseTLSVersion(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid tls_min_version value %q: %w", value, err)
+		}
+		settings.TLS.MinVersion = version
+	case "proxy_url":
+		if _, err := client.ParseProxyURL(value); err != nil {
+			return true, fmt.Errorf("invalid proxy_url value: %w", err)
+		}
+		settings.Network.ProxyURL = value
+	case "no_proxy":
+		settings.Network.NoProxy = value
+	case "connect_timeout", "dial_timeout":
+		// dial_timeout is an alias, named after the WithDialTimeout option
+		d, err := parsePositiveDuration(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid %s value %q: %w", key, value, err)
+		}
+		settings.Network.DialTimeout = d
+	case "keepalive":
+		d, err := parsePositiveDuration(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid keepalive value %q: %w", value, err)
+		}
+		settings.Network.KeepAlive = d
+	case "max_idle_conns":
+		n, err := strconv.Atoi(value)
+		if err == nil && n <= 0 {
+			err = errors.New("must be positive")
+		}
+		if err != nil {
+			return true, fmt.Errorf("invalid max_idle_conns value %q: %w", value, err)
+		}
+		settings.Network.MaxIdleConns = n
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func parsePositiveDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("must be positive")
+	}
+	return d, nil
+}
+
 func parseParams(paramsStr string) [][]string {
 	if len(paramsStr) == 0 {
 		return make([][]string, 0)
@@ -1,7 +1,9 @@
 package fireboltgosdk
 
 import (
+	"crypto/tls"
 	"net/url"
+	"reflect"
 	"testing"
 	"time"
 
@@ -45,10 +47,38 @@ func runDSNTest(t *testing.T, input string, expectedSettings types.FireboltSetti
 		t.Errorf("for ClientSideLB got %t want %t", settings.ClientSideLB, expectedSettings.ClientSideLB)
 	}
 
+	if !reflect.DeepEqual(settings.EngineURLs, expectedSettings.EngineURLs) {
+		t.Errorf("for EngineURLs got %v want %v", settings.EngineURLs, expectedSettings.EngineURLs)
+	}
+
+	if settings.SRVName != expectedSettings.SRVName {
+		t.Errorf("for SRVName got %s want %s", settings.SRVName, expectedSettings.SRVName)
+	}
+
+	if settings.DNSServer != expectedSettings.DNSServer {
+		t.Errorf("for DNSServer got %s want %s", settings.DNSServer, expectedSettings.DNSServer)
+	}
+
 	if settings.DNSTTL != expectedSettings.DNSTTL {
 		t.Errorf("for DNSTTL got %v want %v", settings.DNSTTL, expectedSettings.DNSTTL)
 	}
 
+	if settings.LBPolicy != expectedSettings.LBPolicy {
+		t.Errorf("for LBPolicy got %s want %s", settings.LBPolicy, expectedSettings.LBPolicy)
+	}
+
+	if !reflect.DeepEqual(settings.ResponseCompression, expectedSettings.ResponseCompression) {
+		t.Errorf("for ResponseCompression got %v want %v", settings.ResponseCompression, expectedSettings.ResponseCompression)
+	}
+
+	if settings.TLS != expectedSettings.TLS {
+		t.Errorf("for TLS got %+v want %+v", settings.TLS, expectedSettings.TLS)
+	}
+
+	if settings.Network != expectedSettings.Network {
+		t.Errorf("for Network got %+v want %+v", settings.Network, expectedSettings.Network)
+	}
+
 	// Check DefaultQueryParams
 	if len(settings.DefaultQueryParams) != len(expectedSettings.DefaultQueryParams) {
 		t.Errorf("for DefaultQueryParams length got %d want %d", len(settings.DefaultQueryParams), len(expectedSettings.DefaultQueryParams))
@@ -149,6 +179,43 @@ func TestDSNEngineClientSideLB(t *testing.T) {
 		types.FireboltSettings{Database: "test_db", Url: "http://my-svc:8080", NewVersion: true, ClientSideLB: true})
 }
 
+func TestDSNEngineMultipleURLs(t *testing.T) {
+	runDSNTest(t, "firebolt:///test_db?url=http://node-1:3473,http://node-2:3473",
+		types.FireboltSettings{Database: "test_db", Url: "http://node-1:3473", EngineURLs: []string{"http://node-1:3473", "http://node-2:3473"},
+			NewVersion: true, ClientSideLB: true})
+
+	// Empty entries and surrounding spaces are ignored.
+	runDSNTest(t, "firebolt:///test_db?url=http://node-1:3473,%20http://node-2:3473,",
+		types.FireboltSettings{Database: "test_db", Url: "http://node-1:3473", EngineURLs: []string{"http://node-1:3473", "http://node-2:3473"},
+			NewVersion: true, ClientSideLB: true})
+
+	runDSNTestFail(t, "firebolt:///test_db?url=http://node-1:3473,http://node-2:3473&client_side_lb=false")
+	runDSNTestFail(t, "firebolt:///test_db?url=,")
+}
+
+func TestDSNEngineSRV(t *testing.T) {
+	runDSNTest(t, "firebolt:///test_db?url=https://engine.example.com&client_side_lb_srv=_firebolt._tcp.engine.example.com&client_side_lb_dns_server=10.0.0.10",
+		types.FireboltSettings{Database: "test_db", Url: "https://engine.example.com", NewVersion: true, ClientSideLB: true,
+			SRVName: "_firebolt._tcp.engine.example.com", DNSServer: "10.0.0.10:53"})
+
+	runDSNTest(t, "firebolt:///test_db?url=http://my-svc:8080&client_side_lb_dns_server=[fd00::10]:5353",
+		types.FireboltSettings{Database: "test_db", Url: "http://my-svc:8080", NewVersion: true, ClientSideLB: true, DNSServer: "[fd00::10]:5353"})
+
+	runDSNTestFail(t, "firebolt:///test_db?url=http://my-svc:8080&client_side_lb_dns_server=10.0.0.10:dns53")
+	runDSNTestFail(t, "firebolt:///test_db?url=http://my-svc:8080&client_side_lb_dns_server=")
+	runDSNTestFail(t, "firebolt:///test_db?url=http://my-svc:8080&client_side_lb_srv=_firebolt._tcp.my-svc&client_side_lb=false")
+	runDSNTestFail(t, "firebolt:///test_db?account_name=test_acc&client_side_lb=true&client_side_lb_srv=_firebolt._tcp.my-svc")
+	runDSNTestFail(t, "firebolt:///test_db?url=http://node-1:3473,http://node-2:3473&client_side_lb_srv=_firebolt._tcp.my-svc")
+}
+
+func TestDSNCloudClientSideLB(t *testing.T) {
+	runDSNTest(t, "firebolt:///test_db?account_name=test_acc&engine=test_eng&client_side_lb=true",
+		types.FireboltSettings{Database: "test_db", AccountName: "test_acc", EngineName: "test_eng", NewVersion: true, ClientSideLB: true})
+
+	runDSNTest(t, "firebolt:///test_db?account_name=test_acc&engine=test_eng&client_side_lb=false",
+		types.FireboltSettings{Database: "test_db", AccountName: "test_acc", EngineName: "test_eng", NewVersion: true, ClientSideLB: false})
+}
+
 func TestDSNEngineFailed(t *testing.T) {
 	runDSNTestFail(t, "firebolt:///user:password?url=http")
 	runDSNTestFail(t, "firebolt:///test_db?url=http&k=v")
@@ -174,6 +241,66 @@ func TestDSNEngineClientSideLBDNSTTLInvalid(t *testing.T) {
 	runDSNTestFail(t, "firebolt:///test_db?url=http://my-svc:8080&client_side_lb_dns_ttl=")
 }
 
+func TestDSNEngineClientSideLBPolicy(t *testing.T) {
+	runDSNTest(t, "firebolt:///test_db?url=http://my-svc:8080&client_side_lb_policy=least_outstanding",
+		types.FireboltSettings{Database: "test_db", Url: "http://my-svc:8080", NewVersion: true, ClientSideLB: true, LBPolicy: "least_outstanding"})
+
+	runDSNTest(t, "firebolt:///test_db?url=http://my-svc:8080&client_side_lb_policy=power_of_two",
+		types.FireboltSettings{Database: "test_db", Url: "http://my-svc:8080", NewVersion: true, ClientSideLB: true, LBPolicy: "power_of_two"})
+
+	runDSNTestFail(t, "firebolt:///test_db?url=http://my-svc:8080&client_side_lb_policy=random")
+}
+
+func TestDSNResponseCompression(t *testing.T) {
+	runDSNTest(t, "firebolt:///test_db?url=http://my-svc:8080&response_compression=zstd,gzip",
+		types.FireboltSettings{Database: "test_db", Url: "http://my-svc:8080", NewVersion: true, ClientSideLB: true, ResponseCompression: []string{"zstd", "gzip"}})
+
+	runDSNTest(t, "firebolt:///test_db?account_name=test_acc&response_compression=none",
+		types.FireboltSettings{Database: "test_db", AccountName: "test_acc", NewVersion: true, ClientSideLB: true, ResponseCompression: []string{"none"}})
+
+	runDSNTest(t, "firebolt://user@firebolt.io:password@db_name?response_compression=GZIP",
+		types.FireboltSettings{ClientID: "user@firebolt.io", ClientSecret: "password", Database: "db_name", ResponseCompression: []string{"gzip"}})
+
+	runDSNTestFail(t, "firebolt:///test_db?response_compression=lz4")
+	runDSNTestFail(t, "firebolt:///test_db?response_compression=none,gzip")
+}
+
+func TestDSNTLS(t *testing.T) {
+	runDSNTest(t, "firebolt:///test_db?url=https://my-svc:443&tls_ca_file=/etc/firebolt/ca.pem&tls_cert_file=/etc/firebolt/client.pem&tls_key_file=/etc/firebolt/client-key.pem&tls_min_version=1.3",
+		types.FireboltSettings{Database: "test_db", Url: "https://my-svc:443", NewVersion: true, ClientSideLB: true, TLS: types.TLSSettings{
+			CAFile: "/etc/firebolt/ca.pem", CertFile: "/etc/firebolt/client.pem", KeyFile: "/etc/firebolt/client-key.pem", MinVersion: tls.VersionTLS13}})
+
+	runDSNTest(t, "firebolt:///test_db?account_name=test_acc&tls_ca_file=%2Fetc%2Fca.pem",
+		types.FireboltSettings{Database: "test_db", AccountName: "test_acc", NewVersion: true, ClientSideLB: true, TLS: types.TLSSettings{CAFile: "/etc/ca.pem"}})
+
+	runDSNTest(t, "firebolt://user@firebolt.io:password@db_name?tls_min_version=1.2",
+		types.FireboltSettings{ClientID: "user@firebolt.io", ClientSecret: "password", Database: "db_name", TLS: types.TLSSettings{MinVersion: tls.VersionTLS12}})
+
+	runDSNTestFail(t, "firebolt:///test_db?url=https://my-svc:443&tls_min_version=1.4")
+}
+
+func TestDSNNetwork(t *testing.T) {
+	runDSNTest(t, "firebolt:///test_db?account_name=test_acc&proxy_url=http://proxy.internal:3128&no_proxy=.internal,10.0.0.0%2F8&connect_timeout=5s&keepalive=1m&max_idle_conns=20",
+		types.FireboltSettings{Database: "test_db", AccountName: "test_acc", NewVersion: true, ClientSideLB: true, Network: types.NetworkSettings{
+			ProxyURL: "http://proxy.internal:3128", NoProxy: ".internal,10.0.0.0/8", DialTimeout: 5 * time.Second, KeepAlive: time.Minute, MaxIdleConns: 20}})
+
+	runDSNTest(t, "firebolt:///test_db?url=http://my-svc:8080&proxy_url=socks5%3A%2F%2Fproxy.internal%3A1080",
+		types.FireboltSettings{Database: "test_db", Url: "http://my-svc:8080", NewVersion: true, ClientSideLB: true, Network: types.NetworkSettings{
+			ProxyURL: "socks5://proxy.internal:1080"}})
+
+	runDSNTest(t, "firebolt://user@firebolt.io:password@db_name?connect_timeout=10s",
+		types.FireboltSettings{ClientID: "user@firebolt.io", ClientSecret: "password", Database: "db_name", Network: types.NetworkSettings{DialTimeout: 10 * time.Second}})
+	runDSNTest(t, "firebolt://user@firebolt.io:password@db_name?dial_timeout=10s",
+		types.FireboltSettings{ClientID: "user@firebolt.io", ClientSecret: "password", Database: "db_name", Network: types.NetworkSettings{DialTimeout: 10 * time.Second}})
+
+	runDSNTestFail(t, "firebolt:///test_db?proxy_url=ftp://proxy.internal")
+	runDSNTestFail(t, "firebolt:///test_db?connect_timeout=5")
+	runDSNTestFail(t, "firebolt:///test_db?dial_timeout=5")
+	runDSNTestFail(t, "firebolt:///test_db?keepalive=-1s")
+	runDSNTestFail(t, "firebolt:///test_db?max_idle_conns=0")
+	runDSNTestFail(t, "firebolt:///test_db?max_idle_conns=many")
+}
+
 func TestDSNWithDefaultParams(t *testing.T) {
 	// Test with prefixed default_param.* parameters
 	expectedParams := map[string]string{
@@ -5,13 +5,15 @@ Please verify the account name and make sure your service account has the
 correct RBAC permissions and is linked to a user`
 
 var (
-	AuthenticationError    = ConstructNestedError("authentication error", nil)
-	AuthorizationError     = ConstructNestedError("authorization error", nil)
-	QueryExecutionError    = ConstructNestedError("query execution error", nil)
-	QueryParsingError      = ConstructNestedError("query parsing error", nil)
-	DSNParseError          = ConstructNestedError("error parsing DSN", nil)
-	InvalidAccountError    = ConstructNestedError(accountErrorMsg, nil)
-	AsyncNotSupportedError = ConstructNestedError("async queries are not supported by this client", nil)
+	AuthenticationError        = ConstructNestedError("authentication error", nil)
+	AuthorizationError         = ConstructNestedError("authorization error", nil)
+	QueryExecutionError        = ConstructNestedError("query execution error", nil)
+	QueryParsingError          = ConstructNestedError("query parsing error", nil)
+	DSNParseError              = ConstructNestedError("error parsing DSN", nil)
+	InvalidAccountError        = ConstructNestedError(accountErrorMsg, nil)
+	AsyncNotSupportedError     = ConstructNestedError("async queries are not supported by this client", nil)
+	StreamingNotSupportedError = ConstructNestedError("streaming is not supported", nil)
+	DescribeNotSupportedError  = ConstructNestedError("describing queries is not supported by this client", nil)
 	// OperationCommittedError marks a failure reported after the server has
 	// already accepted an operation. Callers must not blindly retry it.
 	OperationCommittedError = ConstructNestedError("operation committed but response handling failed", nil)
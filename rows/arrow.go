@@ -0,0 +1,421 @@
+package rows
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/shopspring/decimal"
+
+	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
+)
+
+// DefaultArrowBatchSize is the maximum number of rows in a record batch produced by ArrowRecordReader
+const DefaultArrowBatchSize = 64 * 1024
+
+const maxDecimal128Precision = 38
+
+// columnarSource is implemented by rows that decode the rows of the current result set column by column
+type columnarSource interface {
+	driver.Rows
+	columnRecords() []columnRecord
+	// timeLocation returns the session time zone TIMESTAMPTZ values are returned in, nil if it isn't set
+	timeLocation() *time.Location
+	// nextColumnarBatch returns the batch holding the next rows of the current result set,
+	// returns io.EOF if it was the end. The rows are consumed by advancing the cursor of the batch.
+	nextColumnarBatch() (*columnarBatch, error)
+}
+
+// arrowDataType maps a Firebolt column type onto an Arrow data type, TIMESTAMPTZ columns
+// are in the session time zone location, as the values returned by Scan, or in UTC if it is nil
+func arrowDataType(columnType string, location *time.Location) (arrow.DataType, bool, error) {
+	if strings.HasSuffix(columnType, nullableSuffix) {
+		dataType, _, err := arrowDataType(columnType[0:len(columnType)-len(nullableSuffix)], location)
+		return dataType, true, err
+	}
+	if strings.HasPrefix(columnType, arrayPrefix) && strings.HasSuffix(columnType, complexTypeSuffix) {
+		elemType, elemNullable, err := arrowDataType(columnType[len(arrayPrefix):len(columnType)-len(complexTypeSuffix)], location)
+		if err != nil {
+			return nil, false, err
+		}
+		return arrow.ListOfField(arrow.Field{Name: "item", Type: elemType, Nullable: elemNullable}), false, nil
+	}
+	if (strings.HasPrefix(columnType, decimalPrefix) || strings.HasPrefix(columnType, numericPrefix)) && strings.HasSuffix(columnType, complexTypeSuffix) {
+		fbType, err := parseType(columnType)
+		if err != nil {
+			return nil, false, err
+		}
+		if fbType.precision > maxDecimal128Precision {
+			return nil, false, fmt.Errorf("decimal precision %d is not supported in arrow output", fbType.precision)
+		}
+		return &arrow.Decimal128Type{Precision: int32(fbType.precision), Scale: int32(fbType.scale)}, false, nil
+	}
+	if strings.HasPrefix(columnType, structPrefix) && strings.HasSuffix(columnType, complexTypeSuffix) {
+		structFields, err := extractStructFields(columnType[len(structPrefix) : len(columnType)-len(complexTypeSuffix)])
+		if err != nil {
+			return nil, false, errorUtils.ConstructNestedError("error during parsing struct type", err)
+		}
+		fields := make([]arrow.Field, len(structFields))
+		for i, field := range structFields {
+			fieldType, fieldNullable, err := arrowDataType(field.fieldType, location)
+			if err != nil {
+				return nil, false, err
+			}
+			fields[i] = arrow.Field{Name: field.name, Type: fieldType, Nullable: fieldNullable}
+		}
+		return arrow.StructOf(fields...), false, nil
+	}
+
+	switch columnType {
+	case intType, integerType:
+		return arrow.PrimitiveTypes.Int32, false, nil
+	case longType, bigIntType:
+		return arrow.PrimitiveTypes.Int64, false, nil
+	case floatType, realType:
+		return arrow.PrimitiveTypes.Float32, false, nil
+	case doubleType, doublePrecisionType:
+		return arrow.PrimitiveTypes.Float64, false, nil
+	case textType, geographyType:
+		return arrow.BinaryTypes.String, false, nil
+	case dateType, pgDateType:
+		return arrow.FixedWidthTypes.Date32, false, nil
+	case timestampType, timestampNtzType:
+		return &arrow.TimestampType{Unit: arrow.Microsecond}, false, nil
+	case timestampTzType:
+		timeZone := time.UTC.String()
+		if location != nil {
+			timeZone = location.String()
+		}
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: timeZone}, false, nil
+	case booleanType:
+		return arrow.FixedWidthTypes.Boolean, false, nil
+	case byteaType:
+		return arrow.BinaryTypes.Binary, false, nil
+	}
+	return nil, false, fmt.Errorf("unknown column type: %s", columnType)
+}
+
+// arrowSchema builds an Arrow schema from the columns of the current result set
+func arrowSchema(columns []columnRecord, location *time.Location) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(columns))
+	for i, column := range columns {
+		dataType, _, err := arrowDataType(column.fbType.dbType, location)
+		if err != nil {
+			return nil, errorUtils.ConstructNestedError(fmt.Sprintf("error mapping column %s to arrow type", column.name), err)
+		}
+		fields[i] = arrow.Field{Name: column.name, Type: dataType, Nullable: column.fbType.isNullable}
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// parseDecimal128Value converts a decimal into the Decimal128 representation of the data type
+func parseDecimal128Value(d decimal.Decimal, dataType *arrow.Decimal128Type) (decimal128.Num, error) {
+	coefficient := d.Shift(dataType.Scale).BigInt()
+	if coefficient.BitLen() > 127 {
+		return decimal128.Num{}, fmt.Errorf("value %s doesn't fit into %s", d.String(), dataType)
+	}
+	return decimal128.FromBigInt(coefficient), nil
+}
+
+// appendArrowTime appends a date or timestamp to a Date32 or Timestamp builder
+func appendArrowTime(builder array.Builder, t time.Time) error {
+	switch b := builder.(type) {
+	case *array.Date32Builder:
+		b.Append(arrow.Date32FromTime(t))
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(t.UnixMicro()))
+	default:
+		return fmt.Errorf("unsupported arrow builder %T for a time value", builder)
+	}
+	return nil
+}
+
+// appendArrowValue appends a value decoded by parseValue, e.g. an array, a struct or a decimal, to an Arrow builder
+func appendArrowValue(builder array.Builder, val driver.Value) error {
+	if val == nil {
+		builder.AppendNull()
+		return nil
+	}
+	unexpected := fmt.Errorf("unexpected value %v for arrow type %s", val, builder.Type())
+
+	switch b := builder.(type) {
+	case *array.ListBuilder:
+		elems := reflect.ValueOf(val)
+		if elems.Kind() != reflect.Slice {
+			return unexpected
+		}
+		b.Append(true)
+		for i := 0; i < elems.Len(); i++ {
+			if err := appendArrowValue(b.ValueBuilder(), derefElement(elems.Index(i).Interface())); err != nil {
+				return fmt.Errorf("error parsing array element %d: %w", i, err)
+			}
+		}
+	case *array.StructBuilder:
+		structValue, ok := val.(map[string]driver.Value)
+		if !ok {
+			return unexpected
+		}
+		b.Append(true)
+		for i, field := range b.Type().(*arrow.StructType).Fields() {
+			if err := appendArrowValue(b.FieldBuilder(i), structValue[field.Name]); err != nil {
+				return errorUtils.ConstructNestedError("error during parsing struct field", err)
+			}
+		}
+	case *array.Decimal128Builder:
+		var d decimal.Decimal
+		switch v := val.(type) {
+		case decimal.Decimal:
+			d = v
+		case *FireboltDecimal:
+			d = v.Decimal
+		case *FireboltNullDecimal:
+			if !v.Valid {
+				b.AppendNull()
+				return nil
+			}
+			d = v.Decimal
+		default:
+			return unexpected
+		}
+		num, err := parseDecimal128Value(d, b.Type().(*arrow.Decimal128Type))
+		if err != nil {
+			return err
+		}
+		b.Append(num)
+	case *array.Int32Builder:
+		v, ok := val.(int32)
+		if !ok {
+			return unexpected
+		}
+		b.Append(v)
+	case *array.Int64Builder:
+		v, ok := val.(int64)
+		if !ok {
+			return unexpected
+		}
+		b.Append(v)
+	case *array.Float32Builder:
+		v, ok := val.(float32)
+		if !ok {
+			return unexpected
+		}
+		b.Append(v)
+	case *array.Float64Builder:
+		v, ok := val.(float64)
+		if !ok {
+			return unexpected
+		}
+		b.Append(v)
+	case *array.StringBuilder:
+		switch v := val.(type) {
+		case string:
+			b.Append(v)
+		case Geography:
+			b.Append(string(v))
+		default:
+			return unexpected
+		}
+	case *array.BooleanBuilder:
+		v, ok := val.(bool)
+		if !ok {
+			return unexpected
+		}
+		b.Append(v)
+	case *array.BinaryBuilder:
+		v, ok := val.([]byte)
+		if !ok {
+			return unexpected
+		}
+		b.Append(v)
+	case *array.Date32Builder, *array.TimestampBuilder:
+		v, ok := val.(time.Time)
+		if !ok {
+			return unexpected
+		}
+		return appendArrowTime(builder, v)
+	default:
+		return fmt.Errorf("unsupported arrow builder %T", builder)
+	}
+	return nil
+}
+
+// appendArrowValues appends the values of a typed column decoder in bulk to the builder of the matching type
+func appendArrowValues[T any, B interface{ AppendValues([]T, []bool) }](builder array.Builder, decoder *typedColumnDecoder[T], from, to int) error {
+	b, ok := builder.(B)
+	if !ok {
+		return fmt.Errorf("unsupported arrow builder %T for column type %s", builder, decoder.columnType)
+	}
+	b.AppendValues(decoder.values[from:to], decoder.valid[from:to])
+	return nil
+}
+
+// appendArrowColumn appends the rows from..to of a decoded column to an Arrow builder. The values of
+// the primitive types are copied in bulk from the typed buffers of the decoder.
+func appendArrowColumn(builder array.Builder, decoder columnDecoder, from, to int) error {
+	switch d := decoder.(type) {
+	case *typedColumnDecoder[int32]:
+		return appendArrowValues[int32, *array.Int32Builder](builder, d, from, to)
+	case *typedColumnDecoder[int64]:
+		return appendArrowValues[int64, *array.Int64Builder](builder, d, from, to)
+	case *typedColumnDecoder[float32]:
+		return appendArrowValues[float32, *array.Float32Builder](builder, d, from, to)
+	case *typedColumnDecoder[float64]:
+		return appendArrowValues[float64, *array.Float64Builder](builder, d, from, to)
+	case *typedColumnDecoder[string]:
+		return appendArrowValues[string, *array.StringBuilder](builder, d, from, to)
+	case *typedColumnDecoder[bool]:
+		return appendArrowValues[bool, *array.BooleanBuilder](builder, d, from, to)
+	case *typedColumnDecoder[[]byte]:
+		return appendArrowValues[[]byte, *array.BinaryBuilder](builder, d, from, to)
+	case *typedColumnDecoder[time.Time]:
+		for row := from; row < to; row++ {
+			if !d.valid[row] {
+				builder.AppendNull()
+			} else if err := appendArrowTime(builder, d.values[row]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for row := from; row < to; row++ {
+		if err := appendArrowValue(builder, decoder.value(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ArrowRecordReader converts the current result set of a query into a stream of
+// Arrow record batches. It implements array.RecordReader. The columns are built
+// from the typed column buffers the response is decoded into, the values of the
+// primitive types are copied in bulk without driver.Value conversion.
+type ArrowRecordReader struct {
+	refCount  atomic.Int64
+	source    columnarSource
+	schema    *arrow.Schema
+	mem       memory.Allocator
+	batchSize int
+
+	current arrow.RecordBatch
+	err     error
+}
+
+// NewArrowRecordReader creates a record reader over rows returned by a Firebolt
+// query. Each record batch holds at most batchSize rows; if batchSize is not
+// positive, DefaultArrowBatchSize is used. The reader takes ownership of the
+// rows and closes them once it is released.
+func NewArrowRecordReader(rows driver.Rows, mem memory.Allocator, batchSize int) (*ArrowRecordReader, error) {
+	source, ok := rows.(columnarSource)
+	if !ok {
+		return nil, fmt.Errorf("rows of type %T cannot be converted to arrow", rows)
+	}
+	schema, err := arrowSchema(source.columnRecords(), source.timeLocation())
+	if err != nil {
+		return nil, err
+	}
+	if mem == nil {
+		mem = memory.DefaultAllocator
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultArrowBatchSize
+	}
+	reader := &ArrowRecordReader{source: source, schema: schema, mem: mem, batchSize: batchSize}
+	reader.refCount.Store(1)
+	return reader, nil
+}
+
+// Retain increases the reference count by 1
+func (r *ArrowRecordReader) Retain() {
+	r.refCount.Add(1)
+}
+
+// Release decreases the reference count by 1, and releases the current
+// record batch and closes the underlying rows when it reaches 0
+func (r *ArrowRecordReader) Release() {
+	if r.refCount.Add(-1) == 0 {
+		if r.current != nil {
+			r.current.Release()
+			r.current = nil
+		}
+		if err := r.source.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+}
+
+// Schema returns the Arrow schema of the result set
+func (r *ArrowRecordReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+// Next builds the next record batch, returns false at the end of the result set or on error
+func (r *ArrowRecordReader) Next() bool {
+	if r.current != nil {
+		r.current.Release()
+		r.current = nil
+	}
+	if r.err != nil {
+		return false
+	}
+
+	builder := array.NewRecordBuilder(r.mem, r.schema)
+	defer builder.Release()
+	columns := r.source.columnRecords()
+
+	numRows := 0
+	for numRows < r.batchSize {
+		batch, err := r.source.nextColumnarBatch()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			r.err = err
+			return false
+		}
+		if batch.err != nil && batch.cursor == batch.errRow {
+			r.err = batch.err
+			return false
+		}
+		from := batch.cursor
+		to := min(batch.numRows, from+r.batchSize-numRows)
+		for i, column := range columns {
+			if err = appendArrowColumn(builder.Field(i), batch.decoders[i], from, to); err != nil {
+				r.err = errorUtils.ConstructNestedError(fmt.Sprintf("error converting column %s to arrow", column.name), err)
+				return false
+			}
+		}
+		batch.cursor = to
+		numRows += to - from
+	}
+	if numRows == 0 {
+		return false
+	}
+	r.current = builder.NewRecordBatch()
+	return true
+}
+
+// RecordBatch returns the current record batch. It is only valid until the next call to Next
+func (r *ArrowRecordReader) RecordBatch() arrow.RecordBatch {
+	return r.current
+}
+
+// Record returns the current record batch. It is only valid until the next call to Next
+//
+// Deprecated: Use RecordBatch instead.
+func (r *ArrowRecordReader) Record() arrow.Record {
+	return r.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (r *ArrowRecordReader) Err() error {
+	return r.err
+}
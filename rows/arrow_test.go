@@ -0,0 +1,136 @@
+package rows
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+func testArrowRecordReader(t *testing.T, rowsFactory func(isMultiStatement bool) driver.RowsNextResultSet) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	reader, err := NewArrowRecordReader(rowsFactory(false), mem, 2)
+	if err != nil {
+		t.Fatalf("Error creating arrow record reader: %v", err)
+	}
+	defer reader.Release()
+
+	schema := reader.Schema()
+	utils.AssertEqual(schema.NumFields(), 19, t, "number of fields doesn't match")
+	utils.AssertEqual(schema.Field(0).Type.ID(), arrow.INT32, t, "int column type doesn't match")
+	utils.AssertEqual(schema.Field(0).Nullable, true, t, "int column nullability doesn't match")
+	utils.AssertEqual(schema.Field(1).Type.ID(), arrow.INT64, t, "long column type doesn't match")
+	utils.AssertEqual(schema.Field(5).Type.ID(), arrow.DATE32, t, "date column type doesn't match")
+	utils.AssertEqual(schema.Field(9).Type.ID(), arrow.TIMESTAMP, t, "timestamptz column type doesn't match")
+	utils.AssertEqual(arrow.TypeEqual(schema.Field(12).Type, arrow.ListOfNonNullable(arrow.ListOfNonNullable(arrow.BinaryTypes.String))), true, t, "nested array column type doesn't match")
+	utils.AssertEqual(schema.Field(14).Type.String(), "decimal(38, 30)", t, "decimal column type doesn't match")
+	utils.AssertEqual(schema.Field(18).Type.ID(), arrow.STRUCT, t, "struct column type doesn't match")
+
+	if !reader.Next() {
+		t.Fatalf("Error reading first arrow record: %v", reader.Err())
+	}
+	record := reader.RecordBatch()
+	utils.AssertEqual(record.NumRows(), int64(2), t, "number of rows doesn't match")
+	ints := record.Column(0).(*array.Int32)
+	utils.AssertEqual(ints.IsNull(0), true, t, "int value at row 1 should be null")
+	utils.AssertEqual(ints.Value(1), int32(2), t, "int value at row 2 doesn't match")
+	utils.AssertEqual(record.Column(1).(*array.Int64).Value(1), int64(37237366456), t, "long value at row 2 doesn't match")
+	utils.AssertEqual(record.Column(4).(*array.String).Value(0), "text", t, "text value doesn't match")
+	utils.AssertEqual(record.Column(5).(*array.Date32).Value(0).ToTime(), time.Date(2080, 12, 31, 0, 0, 0, 0, time.UTC), t, "date value doesn't match")
+	utils.AssertEqual(record.Column(9).(*array.Timestamp).Value(0), arrow.Timestamp(time.Date(1989, 4, 15, 2, 2, 3, 123456000, time.UTC).UnixMicro()), t, "timestamptz value doesn't match")
+	utils.AssertEqual(record.Column(14).(*array.Decimal128).Value(1), decimal128.FromI64(-12312345678).Mul(decimal128.GetScaleMultiplier(22)), t, "decimal value doesn't match")
+	utils.AssertEqual(record.Column(16).(*array.Binary).Value(0), []byte("abc123"), t, "bytea value doesn't match")
+
+	arrays := record.Column(11).(*array.List)
+	start, end := arrays.ValueOffsets(0)
+	utils.AssertEqual(end-start, int64(3), t, "array length doesn't match")
+
+	structs := record.Column(18).(*array.Struct)
+	utils.AssertEqual(structs.Field(0).(*array.Int32).Value(0), int32(1), t, "struct field value doesn't match")
+	utils.AssertEqual(structs.Field(1).(*array.Struct).IsNull(1), true, t, "nested struct should be null")
+
+	// The fourth row holds a decimal that doesn't fit into Decimal(38, 30)
+	if reader.Next() {
+		t.Errorf("Expected Next to fail on decimal overflow")
+	}
+	if reader.Err() == nil {
+		t.Errorf("Expected decimal overflow error to be reported")
+	}
+}
+
+func TestArrowRecordReaderBatches(t *testing.T) {
+	reader, err := NewArrowRecordReader(mockStreamRowsSingleValue([]interface{}{1, 2}, "array(int)"), nil, 1)
+	if err != nil {
+		t.Fatalf("Error creating arrow record reader: %v", err)
+	}
+	defer reader.Release()
+	var totalRows int64
+	for reader.Next() {
+		totalRows += reader.RecordBatch().NumRows()
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("Error reading arrow records: %v", err)
+	}
+	utils.AssertEqual(totalRows, int64(1), t, "number of rows doesn't match")
+}
+
+func TestArrowRecordReaderSessionTimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	rows := mockStreamRowsSingleValue("2024-01-02 03:04:05+00", "timestamptz")
+	rows.(*StreamRows).SetLocation(berlin)
+	reader, err := NewArrowRecordReader(rows, nil, 0)
+	if err != nil {
+		t.Fatalf("Error creating arrow record reader: %v", err)
+	}
+	defer reader.Release()
+	utils.AssertEqual(reader.Schema().Field(0).Type.(*arrow.TimestampType).TimeZone, "Europe/Berlin", t, "timestamptz column should be in the session time zone")
+
+	reader, err = NewArrowRecordReader(mockStreamRowsSingleValue("2024-01-02 03:04:05+00", "timestamptz"), nil, 0)
+	if err != nil {
+		t.Fatalf("Error creating arrow record reader: %v", err)
+	}
+	defer reader.Release()
+	utils.AssertEqual(reader.Schema().Field(0).Type.(*arrow.TimestampType).TimeZone, "UTC", t, "timestamptz column should be in UTC without a session time zone")
+}
+
+func TestInMemoryRowsArrowRecordReader(t *testing.T) {
+	testArrowRecordReader(t, mockRows)
+}
+
+func TestStreamRowsArrowRecordReader(t *testing.T) {
+	testArrowRecordReader(t, mockStreamRows)
+}
+
+func TestCompactStreamRowsArrowRecordReader(t *testing.T) {
+	testArrowRecordReader(t, mockCompactStreamRows)
+}
+
+func TestArrowRecordReaderUnsupportedRows(t *testing.T) {
+	if _, err := NewArrowRecordReader(&AsyncRows{}, nil, 0); err == nil {
+		t.Errorf("Expected an error creating arrow record reader from async rows")
+	}
+}
+
+func TestArrowRecordReaderConversionError(t *testing.T) {
+	reader, err := NewArrowRecordReader(mockStreamRowsSingleValue("not a number", "int"), nil, 0)
+	if err != nil {
+		t.Fatalf("Error creating arrow record reader: %v", err)
+	}
+	defer reader.Release()
+	if reader.Next() {
+		t.Errorf("Expected Next to fail on invalid value")
+	}
+	if reader.Err() == nil {
+		t.Errorf("Expected conversion error to be reported")
+	}
+}
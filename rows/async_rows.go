@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"time"
 
 	"github.com/firebolt-db/firebolt-go-sdk/client"
 	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
@@ -107,3 +108,6 @@ func (r *AsyncRows) Result() (driver.Result, error) {
 	}
 	return r.result, nil
 }
+
+// SetLocation does nothing, since asynchronous queries don't return values
+func (r *AsyncRows) SetLocation(location *time.Location) {}
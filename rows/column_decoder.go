@@ -0,0 +1,326 @@
+package rows
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+)
+
+// columnDecoder decodes the values of a single column of a data message into a typed buffer.
+// Buffers are reused between data messages.
+type columnDecoder interface {
+	reset()
+	appendValue(token jsonToken) error
+	value(row int) driver.Value
+}
+
+// typedColumnDecoder stores column values in a slice of their Go type. fastParse handles
+// the common JSON representation of the type, any other value is converted with
+// parseValue, so the results and errors are the same as for the generic path
+type typedColumnDecoder[T any] struct {
+	columnType string
+	location   *time.Location
+	values     []T
+	valid      []bool
+	fastParse  func(token jsonToken) (T, bool)
+}
+
+func (c *typedColumnDecoder[T]) reset() {
+	c.values = c.values[:0]
+	c.valid = c.valid[:0]
+}
+
+func (c *typedColumnDecoder[T]) appendValue(token jsonToken) error {
+	if token.kind == jsonNull {
+		var zero T
+		c.values = append(c.values, zero)
+		c.valid = append(c.valid, false)
+		return nil
+	}
+	if c.fastParse != nil {
+		if v, ok := c.fastParse(token); ok {
+			c.values = append(c.values, v)
+			c.valid = append(c.valid, true)
+			return nil
+		}
+	}
+	generic, err := token.decode()
+	if err != nil {
+		return err
+	}
+	parsed, err := parseValue(c.columnType, generic, c.location)
+	if err != nil {
+		return err
+	}
+	v, ok := parsed.(T)
+	if !ok {
+		return fmt.Errorf("unexpected value %v for column type %s", parsed, c.columnType)
+	}
+	c.values = append(c.values, v)
+	c.valid = append(c.valid, true)
+	return nil
+}
+
+func (c *typedColumnDecoder[T]) value(row int) driver.Value {
+	if !c.valid[row] {
+		return nil
+	}
+	return c.values[row]
+}
+
+func parseInt32Token(token jsonToken) (int32, bool) {
+	if token.kind != jsonNumber {
+		return 0, false
+	}
+	// values out of the int32 range are rejected by parseValue
+	v, err := strconv.ParseInt(string(token.raw), 10, 32)
+	return int32(v), err == nil
+}
+
+func parseInt64Token(token jsonToken) (int64, bool) {
+	if token.kind != jsonNumber && token.kind != jsonString {
+		return 0, false
+	}
+	return parseIntBytes(token.raw)
+}
+
+func parseFloat64Token(token jsonToken) (float64, bool) {
+	if token.kind != jsonNumber {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(string(token.raw), 64)
+	return v, err == nil
+}
+
+func parseFloat32Token(token jsonToken) (float32, bool) {
+	v, ok := parseFloat64Token(token)
+	return float32(v), ok
+}
+
+func parseStringToken(token jsonToken) (string, bool) {
+	return string(token.raw), token.kind == jsonString
+}
+
+func parseGeographyToken(token jsonToken) (Geography, bool) {
+	return Geography(token.raw), token.kind == jsonString
+}
+
+func parseBoolToken(token jsonToken) (bool, bool) {
+	return token.kind == jsonTrue, token.kind == jsonTrue || token.kind == jsonFalse
+}
+
+func parseByteaToken(token jsonToken) ([]byte, bool) {
+	if token.kind != jsonString {
+		return nil, false
+	}
+	raw := token.raw
+	if len(raw) >= 2 && raw[0] == '\\' && raw[1] == 'x' {
+		raw = raw[2:]
+	}
+	decoded := make([]byte, hex.DecodedLen(len(raw)))
+	if _, err := hex.Decode(decoded, raw); err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+func makeTimeTokenParser(columnType string, location *time.Location) func(token jsonToken) (time.Time, bool) {
+	return func(token jsonToken) (time.Time, bool) {
+		if token.kind != jsonString {
+			return time.Time{}, false
+		}
+		v, err := parseDateTimeValue(columnType, string(token.raw), location)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return v.(time.Time), true
+	}
+}
+
+// newColumnDecoder creates a decoder for the column type. Types without a dedicated
+// decoder (arrays, structs, decimals) are converted using parseValue.
+// TIMESTAMPTZ values are returned in the location, if it is not nil.
+func newColumnDecoder(columnType string, location *time.Location) columnDecoder {
+	switch columnType {
+	case intType, integerType:
+		return &typedColumnDecoder[int32]{columnType: columnType, location: location, fastParse: parseInt32Token}
+	case longType, bigIntType:
+		return &typedColumnDecoder[int64]{columnType: columnType, location: location, fastParse: parseInt64Token}
+	case floatType, realType:
+		return &typedColumnDecoder[float32]{columnType: columnType, location: location, fastParse: parseFloat32Token}
+	case doubleType, doublePrecisionType:
+		return &typedColumnDecoder[float64]{columnType: columnType, location: location, fastParse: parseFloat64Token}
+	case textType:
+		return &typedColumnDecoder[string]{columnType: columnType, location: location, fastParse: parseStringToken}
+	case geographyType:
+		return &typedColumnDecoder[Geography]{columnType: columnType, location: location, fastParse: parseGeographyToken}
+	case booleanType:
+		return &typedColumnDecoder[bool]{columnType: columnType, location: location, fastParse: parseBoolToken}
+	case byteaType:
+		return &typedColumnDecoder[[]byte]{columnType: columnType, location: location, fastParse: parseByteaToken}
+	case dateType, pgDateType, timestampType, timestampNtzType, timestampTzType:
+		return &typedColumnDecoder[time.Time]{columnType: columnType, location: location, fastParse: makeTimeTokenParser(columnType, location)}
+	}
+	return &typedColumnDecoder[driver.Value]{columnType: columnType, location: location}
+}
+
+// compactChunkSize is the maximum number of rows of a JSON_Compact response decoded into a batch at once
+const compactChunkSize = 1024
+
+// columnarBatch holds the rows of a single data message decoded column by column
+type columnarBatch struct {
+	decoders []columnDecoder
+	scanner  jsonScanner
+	numRows  int
+	cursor   int
+	// errRow is the row at which decoding stopped because of an invalid value, or -1
+	errRow int
+	err    error
+}
+
+func newColumnarBatch(columns []columnRecord, location *time.Location) *columnarBatch {
+	decoders := make([]columnDecoder, len(columns))
+	for i, column := range columns {
+		decoders[i] = newColumnDecoder(column.fbType.dbType, location)
+	}
+	return &columnarBatch{decoders: decoders, errRow: -1}
+}
+
+func (b *columnarBatch) reset() {
+	for _, decoder := range b.decoders {
+		decoder.reset()
+	}
+	b.numRows = 0
+	b.cursor = 0
+	b.errRow = -1
+	b.err = nil
+}
+
+func (b *columnarBatch) hasMoreData() bool {
+	return b.cursor < b.numRows || (b.err != nil && b.cursor == b.errRow)
+}
+
+// decodeLine decodes a JSON lines record. If it is a data message, its rows are
+// decoded into the batch, and the message type is returned. Returns a syntax
+// error if the line is not a valid record.
+func (b *columnarBatch) decodeLine(line []byte) (types.RecordMessageType, error) {
+	b.reset()
+	s := &b.scanner
+	s.reset(line)
+
+	var messageType types.RecordMessageType
+	if err := s.expect('{'); err != nil {
+		return "", err
+	}
+	if s.consume('}') {
+		return "", nil
+	}
+	for {
+		key, err := s.readString()
+		if err != nil {
+			return "", err
+		}
+		// compare before the next read, since key points into the scanner buffers
+		isMessageType, isData := string(key) == "message_type", string(key) == "data"
+		if err = s.expect(':'); err != nil {
+			return "", err
+		}
+		switch {
+		case isMessageType:
+			raw, err := s.readString()
+			if err != nil {
+				return "", err
+			}
+			messageType = types.RecordMessageType(raw)
+		case isData && s.peek() == '[':
+			if err = b.decodeRows(s); err != nil {
+				return "", err
+			}
+		default:
+			if _, err = s.next(); err != nil {
+				return "", err
+			}
+		}
+		if s.consume('}') {
+			return messageType, nil
+		}
+		if err = s.expect(','); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (b *columnarBatch) decodeRows(s *jsonScanner) error {
+	if err := s.expect('['); err != nil {
+		return err
+	}
+	if s.consume(']') {
+		return nil
+	}
+	for {
+		if err := b.decodeRow(s); err != nil {
+			return err
+		}
+		if s.consume(']') {
+			return nil
+		}
+		if err := s.expect(','); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *columnarBatch) decodeRow(s *jsonScanner) error {
+	if err := s.expect('['); err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		if s.consume(']') {
+			if i < len(b.decoders) && b.err == nil {
+				b.errRow, b.err = b.numRows, fmt.Errorf("expected %d values in a row, but got %d", len(b.decoders), i)
+			}
+			break
+		}
+		if i > 0 {
+			if err := s.expect(','); err != nil {
+				return err
+			}
+		}
+		token, err := s.next()
+		if err != nil {
+			return err
+		}
+		// Values after an invalid one or beyond the known columns are skipped
+		if i < len(b.decoders) && b.err == nil {
+			if err = b.decoders[i].appendValue(token); err != nil {
+				b.errRow, b.err = b.numRows, err
+			}
+		}
+	}
+	if b.err == nil {
+		b.numRows++
+	}
+	return nil
+}
+
+// decodeRawRow decodes a row given as a JSON array of values and appends it to the batch
+func (b *columnarBatch) decodeRawRow(row []byte) error {
+	b.scanner.reset(row)
+	return b.decodeRow(&b.scanner)
+}
+
+// next fills dest with the values of the next row
+func (b *columnarBatch) next(dest []driver.Value) error {
+	if b.err != nil && b.cursor == b.errRow {
+		return b.err
+	}
+	for i, decoder := range b.decoders {
+		dest[i] = decoder.value(b.cursor)
+	}
+	b.cursor++
+	return nil
+}
@@ -0,0 +1,239 @@
+package rows
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/firebolt-db/firebolt-go-sdk/client"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+var benchmarkColumns = []types.Column{
+	{Name: "id", Type: "bigint"},
+	{Name: "value", Type: "integer null"},
+	{Name: "price", Type: "double precision"},
+	{Name: "name", Type: "text"},
+	{Name: "flag", Type: "boolean"},
+	{Name: "created", Type: "timestamp"},
+}
+
+// makeStreamResponse builds a JSONLines_Compact response with numMessages data messages of rowsPerMessage rows
+func makeStreamResponse(numMessages, rowsPerMessage int) []byte {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	must(encoder.Encode(types.JSONLinesRecord{MessageType: types.MessageTypeStart, ResultColumns: &benchmarkColumns, QueryID: stringPrt(queryId)}))
+	for m := 0; m < numMessages; m++ {
+		data := make([][]interface{}, rowsPerMessage)
+		for i := range data {
+			row := m*rowsPerMessage + i
+			var value interface{}
+			if row%3 != 0 {
+				value = row
+			}
+			data[i] = []interface{}{fmt.Sprint(row), value, float64(row) / 4, fmt.Sprintf("name \"%d\"", row), row%2 == 0, "2024-01-02 03:04:05"}
+		}
+		must(encoder.Encode(types.JSONLinesRecord{MessageType: types.MessageTypeData, Data: &data}))
+	}
+	must(encoder.Encode(types.JSONLinesRecord{MessageType: types.MessageTypeSuccess}))
+	return buf.Bytes()
+}
+
+func makeStreamRows(response []byte) *StreamRows {
+	rows := &StreamRows{}
+	must(rows.ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(bytes.NewReader(response)), 200, nil, nil)))
+	return rows
+}
+
+// genericRows reads rows the way StreamRows did before columnar decoding: every data message
+// is unmarshalled into a generic structure and every value is converted with parseValue
+type genericRows struct {
+	*StreamRows
+	data   [][]interface{}
+	cursor int
+}
+
+func (g *genericRows) next(dest []driver.Value) error {
+	for g.cursor >= len(g.data) {
+		if g.consumedResponse {
+			return io.EOF
+		}
+		record, err := g.readJsonLine()
+		if err == io.EOF {
+			g.consumedResponse = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if record.MessageType != types.MessageTypeData {
+			if err = g.processControlRecord(record); err != nil {
+				return err
+			}
+			continue
+		}
+		g.data, g.cursor = *record.Data, 0
+	}
+	for i, column := range g.columns {
+		var err error
+		if dest[i], err = parseValue(column.fbType.dbType, g.data[g.cursor][i], nil); err != nil {
+			return err
+		}
+	}
+	g.cursor++
+	return nil
+}
+
+func TestColumnarDecodingMatchesGenericParsing(t *testing.T) {
+	response := makeStreamResponse(3, 7)
+	columnar, generic := makeStreamRows(response), &genericRows{StreamRows: makeStreamRows(response)}
+
+	expected := make([]driver.Value, len(benchmarkColumns))
+	actual := make([]driver.Value, len(benchmarkColumns))
+	numRows := 0
+	for {
+		expectedErr := generic.next(expected)
+		actualErr := columnar.Next(actual)
+		utils.AssertEqual(actualErr, expectedErr, t, "Next errors don't match")
+		if expectedErr != nil {
+			break
+		}
+		utils.AssertEqual(actual, expected, t, fmt.Sprintf("values of row %d don't match", numRows))
+		numRows++
+	}
+	utils.AssertEqual(numRows, 21, t, "number of rows doesn't match")
+}
+
+func TestColumnarDecodingValues(t *testing.T) {
+	columns := []types.Column{
+		{Name: "a", Type: "array(int)"},
+		{Name: "b", Type: "Decimal(38, 2) null"},
+		{Name: "c", Type: "bytea"},
+		{Name: "d", Type: "real"},
+		{Name: "e", Type: "text"},
+	}
+	rows := &StreamRows{}
+	must(rows.setColumns(columns))
+	batch := newColumnarBatch(rows.columns, nil)
+
+	line := `{"message_type": "DATA", "data": [[[1, 2], "1.25", "\\x616263", "inf", "esc\"aped é😀"], [[], null, "\\x", 1.5e1, ""]]}`
+	messageType, err := batch.decodeLine([]byte(line))
+	if err != nil {
+		t.Fatalf("Error decoding line: %v", err)
+	}
+	utils.AssertEqual(messageType, types.MessageTypeData, t, "message type doesn't match")
+
+	dest := make([]driver.Value, len(columns))
+	must(batch.next(dest))
+	utils.AssertEqual(dest[0], []driver.Value{int32(1), int32(2)}, t, ARRAY_VALUES_DO_NOT_MATCH)
+	utils.AssertEqual(dest[1].(*FireboltDecimal).String(), "1.25", t, "decimal value doesn't match")
+	utils.AssertEqual(dest[2], []byte("abc"), t, "bytea value doesn't match")
+	utils.AssertEqual(dest[3], float32(math.Inf(1)), t, "real value doesn't match")
+	utils.AssertEqual(dest[4], "esc\"aped é\U0001F600", t, "text value doesn't match")
+
+	must(batch.next(dest))
+	utils.AssertEqual(dest[0], []driver.Value{}, t, ARRAY_VALUES_DO_NOT_MATCH)
+	utils.AssertEqual(dest[1], nil, t, "null decimal doesn't match")
+	utils.AssertEqual(dest[2], []byte{}, t, "empty bytea value doesn't match")
+	utils.AssertEqual(dest[3], float32(15), t, "real value doesn't match")
+	utils.AssertEqual(dest[4], "", t, "empty text doesn't match")
+	utils.AssertEqual(batch.hasMoreData(), false, t, "batch should be exhausted")
+}
+
+func TestColumnarDecodingIntOverflow(t *testing.T) {
+	rows := &StreamRows{}
+	must(rows.setColumns([]types.Column{{Name: "a", Type: "int"}}))
+	batch := newColumnarBatch(rows.columns, nil)
+
+	if _, err := batch.decodeLine([]byte(`{"message_type":"DATA","data":[[2147483647],[-2147483648],[2147483648]]}`)); err != nil {
+		t.Fatalf("Error decoding line: %v", err)
+	}
+	dest := make([]driver.Value, 1)
+	must(batch.next(dest))
+	utils.AssertEqual(dest[0], int32(math.MaxInt32), t, "max int value doesn't match")
+	must(batch.next(dest))
+	utils.AssertEqual(dest[0], int32(math.MinInt32), t, "min int value doesn't match")
+	if err := batch.next(dest); err == nil {
+		t.Errorf("Expected an error for a value out of the int range, got %v", dest[0])
+	}
+}
+
+func TestColumnarDecodingInvalidValue(t *testing.T) {
+	rows := &StreamRows{}
+	must(rows.setColumns([]types.Column{{Name: "a", Type: "int"}, {Name: "b", Type: "text"}}))
+	batch := newColumnarBatch(rows.columns, nil)
+
+	if _, err := batch.decodeLine([]byte(`{"message_type":"DATA","data":[[1,"a"],["x","b"],[3,"c"]]}`)); err != nil {
+		t.Fatalf("Error decoding line: %v", err)
+	}
+	dest := make([]driver.Value, 2)
+	must(batch.next(dest))
+	utils.AssertEqual(dest, []driver.Value{int32(1), "a"}, t, "values of the first row don't match")
+	if err := batch.next(dest); err == nil {
+		t.Errorf("Expected an error on a row with an invalid value")
+	}
+	if err := batch.next(dest); err == nil {
+		t.Errorf("Expected the error to be returned on every call")
+	}
+
+	if _, err := batch.decodeLine([]byte(`{"message_type":"DATA","data":[[1]]}`)); err != nil {
+		t.Fatalf("Error decoding line: %v", err)
+	}
+	if err := batch.next(dest); err == nil {
+		t.Errorf("Expected an error on a row with missing values")
+	}
+
+	for _, line := range []string{`{"data":[[1,"a"]`, `{"data":[[1,"a]]}`, `{"data":[[tru,"a"]]}`, `[]`} {
+		if _, err := batch.decodeLine([]byte(line)); err == nil {
+			t.Errorf("Expected a syntax error decoding %s", line)
+		}
+	}
+}
+
+func TestStreamRowsLongLinesAndEmptyMessages(t *testing.T) {
+	longText := strings.Repeat("x", 3*4096)
+	response := fmt.Sprintf(`{"message_type":"START","result_columns":[{"name":"t","type":"text"}]}
+{"message_type":"DATA","data":[]}
+{"message_type":"DATA","data":[["%s"],["short"]]}
+{"message_type":"FINISH_SUCCESSFULLY"}
+`, longText)
+	rows := makeStreamRows([]byte(response))
+
+	dest := make([]driver.Value, 1)
+	must(rows.Next(dest))
+	utils.AssertEqual(dest[0], longText, t, "long text value doesn't match")
+	must(rows.Next(dest))
+	utils.AssertEqual(dest[0], "short", t, "short text value doesn't match")
+	utils.AssertEqual(rows.Next(dest), io.EOF, t, "expected end of rows")
+}
+
+func benchmarkStreamRows(b *testing.B, newNext func(r *StreamRows) func(dest []driver.Value) error) {
+	response := makeStreamResponse(100, 1000)
+	dest := make([]driver.Value, len(benchmarkColumns))
+	b.SetBytes(int64(len(response)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		next := newNext(makeStreamRows(response))
+		for next(dest) == nil {
+		}
+	}
+}
+
+func BenchmarkStreamRowsNextColumnar(b *testing.B) {
+	benchmarkStreamRows(b, func(r *StreamRows) func(dest []driver.Value) error {
+		return r.Next
+	})
+}
+
+func BenchmarkStreamRowsNextGeneric(b *testing.B) {
+	benchmarkStreamRows(b, func(r *StreamRows) func(dest []driver.Value) error {
+		return (&genericRows{StreamRows: r}).next
+	})
+}
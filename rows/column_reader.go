@@ -2,6 +2,7 @@ package rows
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/firebolt-db/firebolt-go-sdk/types"
 )
@@ -13,6 +14,18 @@ type columnRecord struct {
 
 type ColumnReader struct {
 	columns []columnRecord
+	// location is the session time zone TIMESTAMPTZ values are returned in, they keep the offset
+	// returned by the server if it is nil
+	location *time.Location
+}
+
+// SetLocation sets the session time zone TIMESTAMPTZ values are returned in
+func (r *ColumnReader) SetLocation(location *time.Location) {
+	r.location = location
+}
+
+func (r *ColumnReader) timeLocation() *time.Location {
+	return r.location
 }
 
 func (r *ColumnReader) setColumns(columns []types.Column) error {
@@ -31,6 +44,10 @@ func (r *ColumnReader) setColumns(columns []types.Column) error {
 
 }
 
+func (r *ColumnReader) columnRecords() []columnRecord {
+	return r.columns
+}
+
 // Columns returns a list of column names in the current row set
 func (r *ColumnReader) Columns() []string {
 	numColumns := len(r.columns)
@@ -74,3 +91,37 @@ func (r *ColumnReader) ColumnTypePrecisionScale(index int) (precision, scale int
 	hasPrecisionScale := r.columns[index].fbType.precision > 0 || r.columns[index].fbType.scale > 0
 	return max(0, r.columns[index].fbType.precision), max(0, r.columns[index].fbType.scale), hasPrecisionScale
 }
+
+// ColumnType describes a result column with the same information sql.ColumnType provides.
+// It allows inspecting the result of a statement before it is executed.
+type ColumnType struct {
+	Name              string
+	DatabaseTypeName  string
+	ScanType          reflect.Type
+	Nullable          bool
+	Length            int64
+	HasLength         bool
+	Precision         int64
+	Scale             int64
+	HasPrecisionScale bool
+}
+
+// MakeColumnTypes describes the columns with the same type information that is reported for query results
+func MakeColumnTypes(columns []types.Column) ([]ColumnType, error) {
+	var reader ColumnReader
+	if err := reader.setColumns(columns); err != nil {
+		return nil, err
+	}
+	columnTypes := make([]ColumnType, len(columns))
+	for i, column := range reader.columns {
+		columnTypes[i] = ColumnType{
+			Name:             column.name,
+			DatabaseTypeName: reader.ColumnTypeDatabaseTypeName(i),
+			ScanType:         reader.ColumnTypeScanType(i),
+		}
+		columnTypes[i].Nullable, _ = reader.ColumnTypeNullable(i)
+		columnTypes[i].Length, columnTypes[i].HasLength = reader.ColumnTypeLength(i)
+		columnTypes[i].Precision, columnTypes[i].Scale, columnTypes[i].HasPrecisionScale = reader.ColumnTypePrecisionScale(i)
+	}
+	return columnTypes, nil
+}
@@ -194,7 +194,7 @@ var testCases = []columnReaderTestCase{
 	{
 		column:             types.Column{Name: "col_geography", Type: "geography"},
 		expectedName:       "col_geography",
-		expectedType:       reflect.TypeOf(""),
+		expectedType:       reflect.TypeOf(Geography("")),
 		expectedDBTypeName: "geography",
 		expectedNullable:   false,
 		expectedLength:     -1,
@@ -303,3 +303,27 @@ func TestColumnReaderColumnTypePrecisionScale(t *testing.T) {
 		}
 	}
 }
+
+func TestMakeColumnTypes(t *testing.T) {
+	columnTypes, err := MakeColumnTypes(testColumns())
+	if err != nil {
+		t.Fatalf("MakeColumnTypes failed: %v", err)
+	}
+
+	for i, tc := range testCases {
+		got := columnTypes[i]
+		if got.Name != tc.expectedName || got.ScanType != tc.expectedType || got.DatabaseTypeName != tc.expectedDBTypeName || got.Nullable != tc.expectedNullable {
+			t.Errorf("Unexpected column type %+v for column %s", got, tc.column.Name)
+		}
+		if got.HasLength != (tc.expectedLength != -1) || (got.HasLength && got.Length != tc.expectedLength) {
+			t.Errorf("Unexpected length %d, ok %v for column %s", got.Length, got.HasLength, tc.column.Name)
+		}
+		if got.HasPrecisionScale && (got.Precision != tc.expectedPrecision || got.Scale != tc.expectedScale) {
+			t.Errorf("Unexpected precision %d, scale %d for column %s", got.Precision, got.Scale, tc.column.Name)
+		}
+	}
+
+	if _, err = MakeColumnTypes([]types.Column{{Name: "a", Type: "unknown"}}); err == nil {
+		t.Errorf("MakeColumnTypes should fail for an unknown type")
+	}
+}
@@ -2,17 +2,17 @@ package rows
 
 import (
 	"database/sql/driver"
-	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"reflect"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/firebolt-db/firebolt-go-sdk/types"
+	"github.com/firebolt-db/firebolt-go-sdk/client"
 	"github.com/firebolt-db/firebolt-go-sdk/utils"
 	"github.com/shopspring/decimal"
 )
@@ -64,9 +64,9 @@ func testRowsNext(t *testing.T, rowsFactory func(isMultiStatement bool) driver.R
 	utils.AssertEqual(dest[9].(time.Time), time.Date(1989, 04, 15, 2, 2, 3, 123456000, loc), t, " at row 1")
 	utils.AssertEqual(dest[13], true, t, "results not equal for boolean at row 1")
 	utils.AssertEqual(dest[14], decimal.NewFromFloat(123.12345678), t, "results not equal for decimal at row 1")
-	utils.AssertEqual(dest[15].([]driver.Value), []driver.Value{decimal.NewFromFloat(123.12345678)}, t, "results not equal for decimal array at row 1")
+	utils.AssertEqual(dest[15].([]*decimal.Decimal), []driver.Value{decimal.NewFromFloat(123.12345678)}, t, "results not equal for decimal array at row 1")
 	utils.AssertEqual(dest[16].([]byte), []byte("abc123"), t, "results not equal for bytes at row 1")
-	utils.AssertEqual(dest[17], "0101000020E6100000FEFFFFFFFFFFEF3F000000000000F03F", t, "results not equal for geography at row 1")
+	utils.AssertEqual(dest[17], Geography("0101000020E6100000FEFFFFFFFFFFEF3F000000000000F03F"), t, "results not equal for geography at row 1")
 	utils.AssertEqual(dest[18].(map[string]driver.Value), map[string]driver.Value{"a": int32(1), "s": map[string]driver.Value{"a": []driver.Value{int32(1), int32(2), int32(3)}, "b": "text"}}, t, "results not equal for struct at row 1")
 
 	// Second row
@@ -79,7 +79,7 @@ func testRowsNext(t *testing.T, rowsFactory func(isMultiStatement bool) driver.R
 	utils.AssertEqual(dest[9].(time.Time), time.Date(1989, 04, 15, 1, 2, 3, 123400000, timezone), t, "results not equal for time at row 2")
 	utils.AssertEqual(dest[13], true, t, "results not equal for boolean at row 2")
 	utils.AssertEqual(dest[14], decimal.NewFromFloat(-123.12345678), t, "results not equal for decimal at row 2")
-	utils.AssertEqual(dest[15].([]driver.Value), []driver.Value{decimal.NewFromFloat(-123.12345678), decimal.NewFromFloat(0.0)}, t, "results not equal for decimal array at row 2")
+	utils.AssertEqual(dest[15].([]*decimal.Decimal), []driver.Value{decimal.NewFromFloat(-123.12345678), decimal.NewFromFloat(0.0)}, t, "results not equal for decimal array at row 2")
 	utils.AssertEqual(dest[16].([]byte), []byte("abc\n\nㅍ ㅎ\\"), t, "results not equal for bytes at row 2")
 	utils.AssertEqual(dest[18].(map[string]driver.Value), map[string]driver.Value{"a": int32(2), "s": nil}, t, "results not equal for struct at row 2")
 
@@ -93,7 +93,7 @@ func testRowsNext(t *testing.T, rowsFactory func(isMultiStatement bool) driver.R
 	utils.AssertEqual(dest[4], "text", t, "results not equal for string at row 3")
 	utils.AssertEqual(dest[13], false, t, "results not equal for boolean at row 3")
 	utils.AssertEqual(dest[14], decimal.NewFromFloat(0.0), t, "results not equal for decimal at row 3")
-	utils.AssertEqual(dest[15].([]driver.Value), []driver.Value{decimal.NewFromFloat(0.0)}, t, "results not equal for decimal array at row 3")
+	utils.AssertEqual(dest[15].([]*decimal.Decimal), []driver.Value{decimal.NewFromFloat(0.0)}, t, "results not equal for decimal array at row 3")
 	utils.AssertEqual(dest[18], nil, t, "results not equal for struct at row 3")
 
 	// Fourth row
@@ -104,7 +104,7 @@ func testRowsNext(t *testing.T, rowsFactory func(isMultiStatement bool) driver.R
 	utils.AssertEqual(dest[13], false, t, "results not equal for boolean at row 4")
 	var longDouble = decimal.NewFromFloat(123456781234567812345678.12345678123456781234567812345678)
 	utils.AssertEqual(dest[14], longDouble, t, "results not equal for decimal at row 4")
-	utils.AssertEqual(dest[15].([]driver.Value), []driver.Value{longDouble}, t, "results not equal for decimal array at row 4")
+	utils.AssertEqual(dest[15].([]*decimal.Decimal), []driver.Value{longDouble}, t, "results not equal for decimal array at row 4")
 
 	// Fifth row
 	err = rows.Next(dest)
@@ -116,7 +116,7 @@ func testRowsNext(t *testing.T, rowsFactory func(isMultiStatement bool) driver.R
 	utils.AssertEqual(dest[9].(time.Time), time.Date(1989, 4, 15, 3, 2, 3, 123456000, loc), t, " at row 5")
 	utils.AssertEqual(dest[13], nil, t, "results not equal for boolean at row 5")
 	utils.AssertEqual(dest[14], nil, t, "results not equal for decimal at row 5")
-	utils.AssertEqual(dest[15].([]driver.Value), []driver.Value{nil}, t, "results not equal for decimal array at row 5")
+	utils.AssertEqual(dest[15].([]*decimal.Decimal), []*decimal.Decimal{nil}, t, "results not equal for decimal array at row 5")
 
 	// Sixth row (does not exist)
 	utils.AssertEqual(io.EOF, rows.Next(dest), t, "Next should return io.EOF if no data available anymore at row 6")
@@ -154,6 +154,68 @@ func testRowsNextSet(t *testing.T, rowsFactory func(isMultiStatement bool) drive
 	utils.AssertEqual(rows.Next(dest), io.EOF, t, "Next should return io.EOF if no data available anymore ")
 }
 
+// testRowsNextSetConsistency checks that NextResultSet only advances when there is a next result set,
+// and that the result sets are unusable after Close
+func testRowsNextSetConsistency(t *testing.T, rowsFactory func(isMultiStatement bool) driver.RowsNextResultSet) {
+	rows := rowsFactory(false)
+	var dest = make([]driver.Value, 19)
+
+	utils.AssertEqual(rows.NextResultSet(), io.EOF, t, "NextResultSet should return io.EOF without a next result set")
+	utils.AssertEqual(rows.Next(dest), nil, t, "NextResultSet without a next result set shouldn't discard the rows")
+	utils.AssertEqual(dest[1], int64(1), t, "results not equal for int64 at row 1")
+
+	rows = rowsFactory(true)
+	// the rows of the first result set are discarded
+	utils.AssertEqual(rows.NextResultSet(), nil, t, "NextResultSet returned an error, but shouldn't")
+	utils.AssertEqual(rows.Next(dest[:1]), nil, t, "Next shouldn't return an error")
+	utils.AssertEqual(dest[0], int32(3), t, "results are not equal for int32")
+
+	utils.AssertEqual(rows.Close(), nil, t, "Close returned an error, but shouldn't")
+	utils.AssertEqual(rows.Next(dest[:1]), io.EOF, t, "Next of closed rows didn't return EOF")
+	utils.AssertEqual(rows.HasNextResultSet(), false, t, "HasNextResultSet of closed rows didn't return false")
+	utils.AssertEqual(rows.NextResultSet(), io.EOF, t, "NextResultSet of closed rows didn't return EOF")
+}
+
+// testRowsStatistics checks the statistics of every result set and of the result
+func testRowsStatistics(t *testing.T, rowsFactory func(isMultiStatement bool) driver.RowsNextResultSet) {
+	rows, ok := rowsFactory(true).(StatisticsRows)
+	if !ok {
+		t.Fatalf("rows don't implement StatisticsRows")
+	}
+	for i, expectedRowsRead := range []int64{3, 2} {
+		if i > 0 {
+			utils.AssertEqual(rows.NextResultSet(), nil, t, "NextResultSet returned an error, but shouldn't")
+		}
+		dest := make([]driver.Value, len(rows.Columns()))
+		for rows.Next(dest) == nil {
+		}
+		statistics := rows.Statistics()
+		if statistics == nil {
+			t.Fatalf("statistics of result set %d are missing", i)
+		}
+		utils.AssertEqual(statistics.RowsRead, expectedRowsRead, t, "rows_read doesn't match")
+		utils.AssertEqual(statistics.ScannedBytesCache, int64(2003), t, "scanned_bytes_cache doesn't match")
+		utils.AssertEqual(statistics.Elapsed, 0.001797702, t, "elapsed doesn't match")
+	}
+
+	resultRows, ok := rowsFactory(true).(interface{ Result() (driver.Result, error) })
+	if !ok {
+		t.Fatalf("rows don't implement Result")
+	}
+	result, err := resultRows.Result()
+	if err != nil {
+		t.Fatalf("Result returned an error: %v", err)
+	}
+	statistics := result.(*FireboltResult).Statistics()
+	utils.AssertEqual(len(statistics), 2, t, "every statement should have statistics")
+	for i, expectedRowsRead := range []int64{3, 2} {
+		if statistics[i] == nil {
+			t.Fatalf("statistics of statement %d are missing", i)
+		}
+		utils.AssertEqual(statistics[i].RowsRead, expectedRowsRead, t, "rows_read doesn't match")
+	}
+}
+
 func testRowsNextStructError(t *testing.T, rowsFactory func(isMultiStatement bool) driver.RowsNextResultSet) {
 	rowsJson := `{
         "query":{"query_id":"16FF2A0300ECA753"},
@@ -162,12 +224,10 @@ func testRowsNextStructError(t *testing.T, rowsFactory func(isMultiStatement boo
         "rows":1,
         "statistics":{}
     }`
-	var response types.QueryResponse
-	if err := json.Unmarshal([]byte(rowsJson), &response); err != nil {
+	rows := &InMemoryRows{}
+	if err := rows.ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(strings.NewReader(rowsJson)), 200, nil, nil)); err != nil {
 		panic(err)
 	}
-
-	rows := &InMemoryRows{ColumnReader{}, []types.QueryResponse{response}, 0, 0}
 	var dest = make([]driver.Value, 1)
 	if err := rows.Next(dest); err == nil {
 		t.Errorf("Next should return an error")
@@ -182,12 +242,10 @@ func testRowsNextStructWithNestedSpaces(t *testing.T, rowsFactory func(isMultiSt
         "rows":1,
         "statistics":{}
     }`
-	var response types.QueryResponse
-	if err := json.Unmarshal([]byte(rowsJson), &response); err != nil {
+	rows := &InMemoryRows{}
+	if err := rows.ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(strings.NewReader(rowsJson)), 200, nil, nil)); err != nil {
 		panic(err)
 	}
-
-	rows := &InMemoryRows{ColumnReader{}, []types.QueryResponse{response}, 0, 0}
 	var dest = make([]driver.Value, 1)
 	if err := rows.Next(dest); err != nil {
 		t.Errorf(nextErrorMessage, err)
@@ -236,3 +294,33 @@ func testRowsDecimalType(t *testing.T, rowsFactorySingleValue func(interface{},
 		utils.AssertEqual(dest[0], c[1], t, fmt.Sprintf("results are not equal for %v", c[0]))
 	}
 }
+
+func testRowsTimestamps(t *testing.T, rowsFactorySingleValue func(interface{}, string) driver.RowsNextResultSet) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	cases := []struct {
+		value      string
+		columnType string
+		location   *time.Location
+		expected   time.Time
+	}{
+		{"1989-04-15 01:02:03", "timestampntz", berlin, time.Date(1989, 4, 15, 1, 2, 3, 0, time.UTC)},
+		{"1989-04-15 01:02:03.1", "timestamp", berlin, time.Date(1989, 4, 15, 1, 2, 3, 100000000, time.UTC)},
+		{"1989-04-15 01:02:03.123456789+05:30", "timestamptz", nil, time.Date(1989, 4, 15, 1, 2, 3, 123456789, time.FixedZone("", 5*60*60+30*60))},
+		{"1989-04-15 01:02:03.123456+05:30", "timestamptz", berlin, time.Date(1989, 4, 14, 21, 32, 3, 123456000, berlin)},
+		{"1989-04-15 01:02:03+00", "timestamptz null", time.UTC, time.Date(1989, 4, 15, 1, 2, 3, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		rows := rowsFactorySingleValue(c.value, c.columnType)
+		rows.(ExtendableRowsWithResult).SetLocation(c.location)
+		var dest = make([]driver.Value, 1)
+		if err := rows.Next(dest); err != nil {
+			t.Errorf(nextErrorMessage, err)
+			continue
+		}
+		utils.AssertEqual(dest[0], c.expected, t, fmt.Sprintf("results are not equal for %s", c.value))
+		utils.AssertEqual(dest[0].(time.Time).Location().String(), c.expected.Location().String(), t, fmt.Sprintf("locations are not equal for %s", c.value))
+	}
+}
@@ -0,0 +1,202 @@
+package rows
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/firebolt-db/firebolt-go-sdk/client"
+	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+)
+
+// CompactStreamRows streams JSON_Compact responses, decoding one row at a time.
+// It is used for streaming with clients that don't support the JSONLines_Compact output format.
+type CompactStreamRows struct {
+	ColumnReader
+	responses         []*client.Response
+	resultSetPosition int
+	// statistics of every response, set once the response is read to the end
+	statistics []*types.Statistics
+	decoder    *jsonCompactDecoder
+	// batch holds the decoded rows of the current result set, it is reused between chunks
+	batch *columnarBatch
+	// rawRow is the buffer the rows are read into before being decoded into the batch
+	rawRow json.RawMessage
+	// readErr is the error that stopped reading the rows of the current batch, it is returned once they are read
+	readErr error
+}
+
+// openResponse starts decoding the current response and reads its columns
+func (r *CompactStreamRows) openResponse() error {
+	r.decoder = newJsonCompactDecoder(responseBody(r.responses[r.resultSetPosition]))
+	r.batch = nil
+	r.readErr = nil
+	if err := r.decoder.readHeader(); err != nil {
+		return err
+	}
+	meta := r.decoder.response.Meta
+	if meta == nil {
+		meta = []types.Column{}
+	}
+	return r.setColumns(meta)
+}
+
+// responseBody returns the body of the response, or an empty reader if there is none
+func responseBody(response *client.Response) io.Reader {
+	if body := response.Body(); body != nil {
+		return body
+	}
+	return bytes.NewReader(nil)
+}
+
+// finishResponse stores the statistics of the current response once it is read to the end
+func (r *CompactStreamRows) finishResponse() {
+	if r.decoder != nil && r.decoder.finished && r.resultSetPosition < len(r.statistics) {
+		r.statistics[r.resultSetPosition] = parseStatistics(r.decoder.response.Statistics)
+	}
+}
+
+// nextRow decodes the next row of the current result set into row
+func (r *CompactStreamRows) nextRow(row interface{}) error {
+	if r.decoder == nil {
+		return io.EOF
+	}
+	err := r.decoder.nextRow(row)
+	if err == io.EOF {
+		r.finishResponse()
+	}
+	return err
+}
+
+// Close makes the rows unusable
+func (r *CompactStreamRows) Close() error {
+	var closeErr error
+	for i := r.resultSetPosition; i < len(r.responses); i++ {
+		if body := r.responses[i].Body(); body != nil {
+			closeErr = errors.Join(closeErr, body.Close())
+		}
+	}
+	r.resultSetPosition = len(r.responses)
+	r.decoder = nil
+	r.batch = nil
+	r.readErr = nil
+	if closeErr != nil {
+		return errorUtils.ConstructNestedError("Error closing response body:", closeErr)
+	}
+	return nil
+}
+
+// Next fetches the values of the next row, returns io.EOF if it was the end
+func (r *CompactStreamRows) Next(dest []driver.Value) error {
+	batch, err := r.nextColumnarBatch()
+	if err != nil {
+		return err
+	}
+	if err = batch.next(dest); err != nil {
+		return errorUtils.ConstructNestedError("error during fetching Next result", err)
+	}
+	return nil
+}
+
+// nextColumnarBatch returns the batch holding the next rows of the current result set, reading and
+// decoding up to compactChunkSize rows if all the decoded ones were read, returns io.EOF if it was the end
+func (r *CompactStreamRows) nextColumnarBatch() (*columnarBatch, error) {
+	if r.batch == nil {
+		r.batch = newColumnarBatch(r.columns, r.location)
+	}
+	if r.batch.hasMoreData() {
+		return r.batch, nil
+	}
+	if r.readErr != nil {
+		return nil, r.readErr
+	}
+	r.batch.reset()
+	for r.batch.numRows < compactChunkSize && r.batch.err == nil {
+		if err := r.nextRow(&r.rawRow); err == io.EOF {
+			break
+		} else if err != nil {
+			r.readErr = err
+			break
+		}
+		if err := r.batch.decodeRawRow(r.rawRow); err != nil {
+			return nil, errorUtils.ConstructNestedError("error during fetching Next result", err)
+		}
+	}
+	if !r.batch.hasMoreData() {
+		if r.readErr != nil {
+			return nil, r.readErr
+		}
+		return nil, io.EOF
+	}
+	return r.batch, nil
+}
+
+// HasNextResultSet reports whether there is another result set available
+func (r *CompactStreamRows) HasNextResultSet() bool {
+	return r.resultSetPosition < len(r.responses)-1
+}
+
+// NextResultSet advances to the next result set, if it is available, otherwise returns io.EOF.
+// The remaining rows of the current result set are discarded.
+func (r *CompactStreamRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+
+	if body := r.responses[r.resultSetPosition].Body(); body != nil {
+		if err := body.Close(); err != nil {
+			return errorUtils.ConstructNestedError("Error closing response body:", err)
+		}
+	}
+
+	r.resultSetPosition++
+	return r.openResponse()
+}
+
+// ProcessAndAppendResponse appends a response to the list of row streams
+func (r *CompactStreamRows) ProcessAndAppendResponse(response *client.Response) error {
+	r.responses = append(r.responses, response)
+	r.statistics = append(r.statistics, nil)
+	if r.decoder == nil {
+		return r.openResponse()
+	}
+	return nil
+}
+
+// Statistics returns the execution statistics of the current result set, they are only
+// available once the result set is read to the end
+func (r *CompactStreamRows) Statistics() *types.Statistics {
+	if r.resultSetPosition >= len(r.statistics) {
+		return nil
+	}
+	return r.statistics[r.resultSetPosition]
+}
+
+// Result reads all the remaining result sets to the end, so that errors reported by the server
+// after the rows aren't lost, and returns the statistics of every statement.
+// The rows are closed afterward.
+func (r *CompactStreamRows) Result() (driver.Result, error) {
+	for r.resultSetPosition < len(r.responses) {
+		if r.decoder != nil {
+			err := r.decoder.skipRows()
+			r.finishResponse()
+			if err != nil {
+				return nil, errors.Join(err, r.Close())
+			}
+		}
+		if !r.HasNextResultSet() {
+			break
+		}
+		if err := r.NextResultSet(); err != nil {
+			return nil, errors.Join(err, r.Close())
+		}
+	}
+	result := &FireboltResult{statistics: r.statistics}
+	if err := r.Close(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package rows
+
+import (
+	"database/sql/driver"
+	stderrors "errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/firebolt-db/firebolt-go-sdk/client"
+	"github.com/firebolt-db/firebolt-go-sdk/errors"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+func mockCompactStreamRows(isMultiStatement bool) driver.RowsNextResultSet {
+	rows := &CompactStreamRows{}
+	appendMockResponses(rows, isMultiStatement)
+	return rows
+}
+
+func mockCompactStreamRowsSingleValue(value interface{}, columnType string) driver.RowsNextResultSet {
+	rows := &CompactStreamRows{}
+	must(rows.ProcessAndAppendResponse(mockSingleValueResponse(value, columnType)))
+	return rows
+}
+
+func mockCompactStreamRowsFromString(t *testing.T, responses ...string) (*CompactStreamRows, error) {
+	t.Helper()
+	rows := &CompactStreamRows{}
+	for _, response := range responses {
+		if err := rows.ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(strings.NewReader(response)), 200, nil, nil)); err != nil {
+			return rows, err
+		}
+	}
+	return rows, nil
+}
+
+func TestCompactStreamRowsColumns(t *testing.T) {
+	testRowsColumns(t, mockCompactStreamRows)
+}
+
+func TestCompactStreamRowsClose(t *testing.T) {
+	testRowsClose(t, mockCompactStreamRows)
+}
+
+func TestCompactStreamRowsNext(t *testing.T) {
+	testRowsNext(t, mockCompactStreamRows)
+}
+
+func TestCompactStreamRowsNextSet(t *testing.T) {
+	testRowsNextSet(t, mockCompactStreamRows)
+}
+
+func TestCompactStreamRowsNextSetConsistency(t *testing.T) {
+	testRowsNextSetConsistency(t, mockCompactStreamRows)
+}
+
+func TestCompactStreamRowsStatistics(t *testing.T) {
+	testRowsStatistics(t, mockCompactStreamRows)
+}
+
+func TestCompactStreamRowsQuotedLong(t *testing.T) {
+	testRowsQuotedLong(t, mockCompactStreamRowsSingleValue)
+}
+
+func TestCompactStreamRowsDecimalType(t *testing.T) {
+	testRowsDecimalType(t, mockCompactStreamRowsSingleValue)
+}
+
+func TestCompactStreamRowsTimestamps(t *testing.T) {
+	testRowsTimestamps(t, mockCompactStreamRowsSingleValue)
+}
+
+func TestCompactStreamRowsEmptyResponse(t *testing.T) {
+	rows, err := mockCompactStreamRowsFromString(t, "")
+	if err != nil {
+		t.Fatalf("empty response shouldn't be an error: %v", err)
+	}
+	utils.AssertEqual(len(rows.Columns()), 0, t, "empty response shouldn't have columns")
+	utils.AssertEqual(rows.Next(nil), io.EOF, t, "empty response shouldn't have rows")
+}
+
+func TestCompactStreamRowsErrors(t *testing.T) {
+	// errors before the data are returned when the response is appended
+	_, err := mockCompactStreamRowsFromString(t, `{"errors":[{"description":"early error"}],"meta":[]}`)
+	var structuredErr *errors.StructuredError
+	if !stderrors.As(err, &structuredErr) || !strings.Contains(structuredErr.Message, "early error") {
+		t.Errorf("expected a structured error, got %v", err)
+	}
+
+	// errors after the data are returned by Next once the rows are read
+	rows, err := mockCompactStreamRowsFromString(t, `{"meta":[{"name":"a","type":"int"}],"data":[[1]],"errors":[{"description":"late error"}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dest := make([]driver.Value, 1)
+	utils.AssertEqual(rows.Next(dest), nil, t, "first row should be read")
+	utils.AssertEqual(dest[0], int32(1), t, "results are not equal for int32")
+	err = rows.Next(dest)
+	if !stderrors.As(err, &structuredErr) || !strings.Contains(structuredErr.Message, "late error") {
+		t.Errorf("expected a structured error, got %v", err)
+	}
+
+	// the same error is returned by Result
+	rows, _ = mockCompactStreamRowsFromString(t, `{"meta":[{"name":"a","type":"int"}],"data":[[1]],"errors":[{"description":"late error"}]}`)
+	if _, err = rows.Result(); !stderrors.As(err, &structuredErr) {
+		t.Errorf("expected a structured error from Result, got %v", err)
+	}
+
+	for _, response := range []string{
+		`{"data":[[1]],"meta":[{"name":"a","type":"int"}]}`,
+		`{"meta":[{"name":"a","type":"int"}],"data":[[1],`,
+		`[1, 2]`,
+	} {
+		rows, err = mockCompactStreamRowsFromString(t, response)
+		for err == nil {
+			err = rows.Next(make([]driver.Value, 1))
+		}
+		if err == io.EOF {
+			t.Errorf("expected an error for response %s", response)
+		}
+	}
+}
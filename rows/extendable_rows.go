@@ -2,6 +2,7 @@ package rows
 
 import (
 	"database/sql/driver"
+	"time"
 
 	"github.com/firebolt-db/firebolt-go-sdk/client"
 )
@@ -10,4 +11,5 @@ type ExtendableRowsWithResult interface {
 	driver.Rows
 	ProcessAndAppendResponse(response *client.Response) error
 	Result() (driver.Result, error)
+	SetLocation(location *time.Location)
 }
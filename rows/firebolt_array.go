@@ -19,7 +19,8 @@ func (fa *FireboltArray) Scan(src interface{}) error {
 
 	*fa = make([]interface{}, t.Len())
 	for i := 0; i < t.Len(); i++ {
-		(*fa)[i] = t.Index(i).Interface()
+		// nullable elements are pointers, NULL elements are stored as nil
+		(*fa)[i] = derefElement(t.Index(i).Interface())
 	}
 	return nil
 }
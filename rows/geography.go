@@ -0,0 +1,156 @@
+package rows
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+// Geography is a GEOGRAPHY value in the hex-encoded (E)WKB format returned by the server,
+// e.g. 0101000020E6100000FEFFFFFFFFFFEF3F000000000000F03F for POINT(1 1).
+// Since it is a string type, GEOGRAPHY values can still be scanned into strings.
+// Geography implements the Scanner and Valuer interfaces, so it can be used as
+// a scan destination and as a query parameter.
+type Geography string
+
+// NewGeographyFromWKB creates a Geography from (E)WKB bytes
+func NewGeographyFromWKB(wkb []byte) Geography {
+	return Geography(strings.ToUpper(hex.EncodeToString(wkb)))
+}
+
+// NewGeographyFromWKT creates a Geography from its WKT representation, e.g. POINT(1 1)
+func NewGeographyFromWKT(text string) (Geography, error) {
+	g, err := wkt.Unmarshal(text)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse WKT value %s: %w", text, err)
+	}
+	return NewGeography(g)
+}
+
+// NewGeography creates a Geography from a go-geom geometry. The SRID of the geometry is kept.
+func NewGeography(g geom.T) (Geography, error) {
+	wkb, err := ewkb.Marshal(g, ewkb.NDR)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode %T as WKB: %w", g, err)
+	}
+	return NewGeographyFromWKB(wkb), nil
+}
+
+// WKB returns the (E)WKB bytes of the value
+func (g Geography) WKB() ([]byte, error) {
+	wkb, err := hex.DecodeString(strings.TrimPrefix(string(g), "\\x"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse hex value: %s", string(g))
+	}
+	return wkb, nil
+}
+
+// Geom decodes the value into a go-geom geometry
+func (g Geography) Geom() (geom.T, error) {
+	wkb, err := g.WKB()
+	if err != nil {
+		return nil, err
+	}
+	res, err := ewkb.Unmarshal(wkb)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode WKB value: %w", err)
+	}
+	return res, nil
+}
+
+// WKT returns the WKT representation of the value, e.g. POINT (1 1)
+func (g Geography) WKT() (string, error) {
+	res, err := g.Geom()
+	if err != nil {
+		return "", err
+	}
+	return wkt.Marshal(res)
+}
+
+// String returns the hex-encoded (E)WKB of the value
+func (g Geography) String() string {
+	return string(g)
+}
+
+// Scan implements the Scanner interface. Hex-encoded (E)WKB and WKT strings,
+// (E)WKB bytes and go-geom geometries are accepted.
+func (g *Geography) Scan(value interface{}) error {
+	res, ok, err := ToGeography(value)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("cannot scan NULL into Geography, use NullGeography instead")
+	}
+	*g = res
+	return nil
+}
+
+// Value implements the driver Valuer interface, the value is sent as hex-encoded (E)WKB
+func (g Geography) Value() (driver.Value, error) {
+	return string(g), nil
+}
+
+// NullGeography represents a Geography that may be null.
+// NullGeography implements the Scanner and Valuer interfaces.
+type NullGeography struct {
+	Geography Geography
+	Valid     bool // Valid is true if Geography is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (n *NullGeography) Scan(value interface{}) error {
+	res, ok, err := ToGeography(value)
+	if err != nil {
+		return err
+	}
+	n.Geography, n.Valid = res, ok
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (n NullGeography) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Geography.Value()
+}
+
+// ToGeography converts a value into a Geography. Geography, NullGeography, go-geom geometries,
+// (E)WKB bytes and hex-encoded (E)WKB or WKT strings are accepted, ok is false for NULL.
+func ToGeography(value interface{}) (g Geography, ok bool, err error) {
+	if value == nil {
+		return "", false, nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return "", false, nil
+	}
+	switch v := value.(type) {
+	case Geography:
+		return v, true, nil
+	case *Geography:
+		return *v, true, nil
+	case NullGeography:
+		return v.Geography, v.Valid, nil
+	case *NullGeography:
+		return v.Geography, v.Valid, nil
+	case []byte:
+		return NewGeographyFromWKB(v), true, nil
+	case string:
+		if _, err = hex.DecodeString(strings.TrimPrefix(v, "\\x")); err == nil {
+			return Geography(strings.ToUpper(strings.TrimPrefix(v, "\\x"))), true, nil
+		}
+		g, err = NewGeographyFromWKT(v)
+		return g, err == nil, err
+	case geom.T:
+		g, err = NewGeography(v)
+		return g, err == nil, err
+	}
+	return "", false, fmt.Errorf("cannot convert %T to Geography", value)
+}
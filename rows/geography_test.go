@@ -0,0 +1,79 @@
+package rows
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+// pointOneOne is POINT(1 1) as returned by the server
+const pointOneOne = "0101000020E6100000FEFFFFFFFFFFEF3F000000000000F03F"
+
+func TestGeographyConversions(t *testing.T) {
+	g := Geography(pointOneOne)
+
+	wkt, err := g.WKT()
+	utils.AssertEqual(err, nil, t, "WKT returned an error")
+	utils.AssertEqual(wkt, "POINT (0.9999999999999998 1)", t, "WKT doesn't match")
+
+	parsed, err := g.Geom()
+	utils.AssertEqual(err, nil, t, "Geom returned an error")
+	point, ok := parsed.(*geom.Point)
+	if !ok {
+		t.Fatalf("expected a point, got %T", parsed)
+	}
+	utils.AssertEqual(point.SRID(), 4326, t, "SRID doesn't match")
+	utils.AssertEqual(point.Y(), 1.0, t, "Y doesn't match")
+
+	fromGeom, err := NewGeography(point)
+	utils.AssertEqual(err, nil, t, "NewGeography returned an error")
+	utils.AssertEqual(fromGeom, g, t, "geography created from geometry doesn't match")
+
+	wkb, err := g.WKB()
+	utils.AssertEqual(err, nil, t, "WKB returned an error")
+	utils.AssertEqual(NewGeographyFromWKB(wkb), g, t, "geography created from WKB doesn't match")
+
+	fromWKT, err := NewGeographyFromWKT("POINT(1 1)")
+	utils.AssertEqual(err, nil, t, "NewGeographyFromWKT returned an error")
+	utils.AssertEqual(fromWKT, Geography("0101000000000000000000F03F000000000000F03F"), t, "geography created from WKT doesn't match")
+
+	if _, err = NewGeographyFromWKT("POINT(1"); err == nil {
+		t.Errorf("invalid WKT should return an error")
+	}
+	if _, err = Geography("not hex").Geom(); err == nil {
+		t.Errorf("invalid hex should return an error")
+	}
+}
+
+func TestGeographyScan(t *testing.T) {
+	var g Geography
+	for _, src := range []interface{}{Geography(pointOneOne), pointOneOne, "\\x" + pointOneOne} {
+		utils.AssertEqual(g.Scan(src), nil, t, "Scan returned an error")
+		utils.AssertEqual(g, Geography(pointOneOne), t, "scanned geography doesn't match")
+	}
+	utils.AssertEqual(g.Scan("POINT(1 1)"), nil, t, "Scan of WKT returned an error")
+	utils.AssertEqual(g, Geography("0101000000000000000000F03F000000000000F03F"), t, "scanned WKT doesn't match")
+	if err := g.Scan(nil); err == nil {
+		t.Errorf("scanning NULL into Geography should return an error")
+	}
+	if err := g.Scan(1); err == nil {
+		t.Errorf("scanning an int into Geography should return an error")
+	}
+
+	var n NullGeography
+	utils.AssertEqual(n.Scan(Geography(pointOneOne)), nil, t, "Scan returned an error")
+	utils.AssertEqual(n, NullGeography{Geography: pointOneOne, Valid: true}, t, "scanned geography doesn't match")
+	utils.AssertEqual(n.Scan(nil), nil, t, "Scan of NULL returned an error")
+	utils.AssertEqual(n.Valid, false, t, "NULL geography should be invalid")
+	value, err := n.Value()
+	utils.AssertEqual(err, nil, t, "Value returned an error")
+	utils.AssertEqual(value, nil, t, "value of NULL geography should be nil")
+
+	// geography values can still be scanned into strings
+	var s sql.NullString
+	utils.AssertEqual(s.Scan(Geography(pointOneOne)), nil, t, "Scan into NullString returned an error")
+	utils.AssertEqual(s.String, pointOneOne, t, "geography scanned into string doesn't match")
+}
@@ -3,7 +3,6 @@ package rows
 import (
 	"database/sql/driver"
 	"encoding/json"
-	errorUtils "errors"
 	"io"
 
 	"github.com/firebolt-db/firebolt-go-sdk/client"
@@ -15,34 +14,59 @@ import (
 
 type InMemoryRows struct {
 	ColumnReader
-	queryResponses    []types.QueryResponse
+	queryResponses []types.QueryResponse
+	// rawRows holds the undecoded rows of every result set, they are decoded column by column on demand
+	rawRows           [][]json.RawMessage
 	cursorPosition    int
 	resultSetPosition int
+	// batch holds the decoded rows of the current result set, it is reused between chunks
+	batch *columnarBatch
 }
 
 // Close makes the rows unusable
 func (r *InMemoryRows) Close() error {
+	if len(r.queryResponses) == 0 {
+		return nil
+	}
 	r.resultSetPosition = len(r.queryResponses) - 1
-	r.cursorPosition = len(r.queryResponses[r.resultSetPosition].Data)
+	r.cursorPosition = len(r.rawRows[r.resultSetPosition])
+	r.batch = nil
 	return nil
 }
 
 // Next fetches the values of the next row, returns io.EOF if it was the end
 func (r *InMemoryRows) Next(dest []driver.Value) error {
-	if r.cursorPosition == len(r.queryResponses[r.resultSetPosition].Data) {
-		return io.EOF
+	batch, err := r.nextColumnarBatch()
+	if err != nil {
+		return err
 	}
+	if err = batch.next(dest); err != nil {
+		return errors.ConstructNestedError("error during fetching Next result", err)
+	}
+	return nil
+}
 
-	for i, column := range r.queryResponses[r.resultSetPosition].Meta {
-		var err error
-		//log.Printf("Rows.Next: %s, %v", column.Type, r.queryResponses.Data[r.cursorPosition][i])
-		if dest[i], err = parseValue(column.Type, r.queryResponses[r.resultSetPosition].Data[r.cursorPosition][i]); err != nil {
-			return errors.ConstructNestedError("error during fetching Next result", err)
+// nextColumnarBatch returns the batch holding the next rows of the current result set, decoding them
+// if all the decoded ones were read, returns io.EOF if it was the end
+func (r *InMemoryRows) nextColumnarBatch() (*columnarBatch, error) {
+	if r.batch == nil {
+		r.batch = newColumnarBatch(r.columns, r.location)
+	}
+	if r.batch.hasMoreData() {
+		return r.batch, nil
+	}
+	if len(r.queryResponses) == 0 || r.cursorPosition == len(r.rawRows[r.resultSetPosition]) {
+		return nil, io.EOF
+	}
+	r.batch.reset()
+	rows := r.rawRows[r.resultSetPosition]
+	end := min(r.cursorPosition+compactChunkSize, len(rows))
+	for ; r.cursorPosition < end && r.batch.err == nil; r.cursorPosition++ {
+		if err := r.batch.decodeRawRow(rows[r.cursorPosition]); err != nil {
+			return nil, errors.ConstructNestedError("error during fetching Next result", err)
 		}
 	}
-
-	r.cursorPosition++
-	return nil
+	return r.batch, nil
 }
 
 // HasNextResultSet reports whether there is another result set available
@@ -58,37 +82,45 @@ func (r *InMemoryRows) NextResultSet() error {
 
 	r.cursorPosition = 0
 	r.resultSetPosition += 1
+	r.batch = nil
 	return r.setColumns(r.queryResponses[r.resultSetPosition].Meta)
 }
 
-// ProcessAndAppendResponse appends the response to the InMemoryRows, parsing the response content
-// and checking for errors in the response body
-func (r *InMemoryRows) ProcessAndAppendResponse(response *client.Response) error {
-	// Check for error in the Response body, despite the status code 200
-	errorResponse := struct {
-		Errors []types.ErrorDetails `json:"errors"`
-	}{}
-	content, err := response.Content()
-	if err != nil {
-		return errors.ConstructNestedError("error during reading response content", err)
+// ProcessAndAppendResponse appends the response to the InMemoryRows. The response is decoded incrementally,
+// only the decoded rows are kept in memory, and errors reported in the response body are returned
+// despite the status code 200
+func (r *InMemoryRows) ProcessAndAppendResponse(response *client.Response) (err error) {
+	if body := response.Body(); body != nil {
+		defer func() {
+			if closeErr := body.Close(); closeErr != nil && err == nil {
+				err = errors.ConstructNestedError("error during reading response content", closeErr)
+			}
+		}()
 	}
-	if err := json.Unmarshal(content, &errorResponse); err == nil {
-		if len(errorResponse.Errors) > 0 {
-			return errors.NewStructuredError(errorResponse.Errors)
+
+	decoder := newJsonCompactDecoder(responseBody(response))
+	if err = decoder.readHeader(); err != nil {
+		return err
+	}
+	var rows []json.RawMessage
+	for {
+		// the rows are kept, so each of them needs its own buffer
+		var row json.RawMessage
+		if err = decoder.nextRow(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
 		}
+		rows = append(rows, row)
 	}
 
-	// Unmarshal the response content
-	var queryResponse types.QueryResponse
 	// Response could be empty, which doesn't mean it is an error
-	if len(content) != 0 {
-		if err = json.Unmarshal(content, &queryResponse); err != nil {
-			return errors.ConstructNestedError("wrong response", errorUtils.New(string(content)))
-		}
+	if decoder.response.Meta != nil {
 		logging.Infolog.Printf("Query was successful")
 	}
 
-	r.queryResponses = append(r.queryResponses, queryResponse)
+	r.queryResponses = append(r.queryResponses, decoder.response)
+	r.rawRows = append(r.rawRows, rows)
 	if r.columns == nil {
 		return r.setColumns(r.queryResponses[r.resultSetPosition].Meta)
 	}
@@ -96,6 +128,19 @@ func (r *InMemoryRows) ProcessAndAppendResponse(response *client.Response) error
 	return nil
 }
 
+// Statistics returns the execution statistics of the current result set
+func (r *InMemoryRows) Statistics() *types.Statistics {
+	if len(r.queryResponses) == 0 {
+		return nil
+	}
+	return parseStatistics(r.queryResponses[r.resultSetPosition].Statistics)
+}
+
+// Result returns the result of the query with the statistics of every statement
 func (r *InMemoryRows) Result() (driver.Result, error) {
-	return &FireboltResult{}, nil
+	statistics := make([]*types.Statistics, len(r.queryResponses))
+	for i, response := range r.queryResponses {
+		statistics[i] = parseStatistics(response.Statistics)
+	}
+	return &FireboltResult{statistics: statistics}, nil
 }
@@ -4,18 +4,22 @@ import (
 	"bytes"
 	"database/sql/driver"
 	"encoding/json"
+	stderrors "errors"
 	"io"
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/firebolt-db/firebolt-go-sdk/client"
+	"github.com/firebolt-db/firebolt-go-sdk/errors"
 
 	"github.com/firebolt-db/firebolt-go-sdk/types"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
 )
 
-func mockRows(isMultiStatement bool) driver.RowsNextResultSet {
-	resultJson := []string{
-		`{
+// mockResultJson are the JSON_Compact responses of a two statement query
+var mockResultJson = []string{
+	`{
         "query":{"query_id":"16FF2A0300ECA753"},
         "meta":[
         	{"name":"int_col","type":"int null"},
@@ -57,7 +61,7 @@ func mockRows(isMultiStatement bool) driver.RowsNextResultSet {
         }
     }`,
 
-		`{
+	`{
         "query":{"query_id":"16FF2A0300ECA753"},
         "meta":[{"name":"int_col","type":"int null"}],
         "data":[[3], [null]],
@@ -72,20 +76,26 @@ func mockRows(isMultiStatement bool) driver.RowsNextResultSet {
             "scanned_bytes_storage":0
         }
     }`,
-	}
+}
 
-	rows := &InMemoryRows{}
+// appendMockResponses appends the mocked responses of a single or a two statement query
+func appendMockResponses(rows ExtendableRowsWithResult, isMultiStatement bool) {
 	for i := 0; i < 2; i += 1 {
 		if i != 0 && !isMultiStatement {
 			break
 		}
-		must(rows.ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(bytes.NewReader([]byte(resultJson[i]))), 200, nil, nil)))
+		must(rows.ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(bytes.NewReader([]byte(mockResultJson[i]))), 200, nil, nil)))
 	}
+}
 
+func mockRows(isMultiStatement bool) driver.RowsNextResultSet {
+	rows := &InMemoryRows{}
+	appendMockResponses(rows, isMultiStatement)
 	return rows
 }
 
-func mockRowsSingleValue(value interface{}, columnType string) driver.RowsNextResultSet {
+// mockSingleValueResponse returns a JSON_Compact response with a single column and a single row
+func mockSingleValueResponse(value interface{}, columnType string) *client.Response {
 	record := types.QueryResponse{
 		Query:      map[string]string{"query_id": "16FF2A0300ECA753"},
 		Meta:       []types.Column{{Name: "single_col", Type: columnType}},
@@ -95,15 +105,16 @@ func mockRowsSingleValue(value interface{}, columnType string) driver.RowsNextRe
 		Statistics: map[string]interface{}{},
 	}
 
-	rows := &InMemoryRows{}
 	jsonData, err := json.Marshal(record)
 	if err != nil {
 		log.Fatalf("Error marshaling JSON: %v", err)
 	}
-	reader := io.NopCloser(bytes.NewReader(jsonData))
-	must(rows.ProcessAndAppendResponse(client.MakeResponse(reader, 200, nil, nil)))
-	// Convert them to json lines
+	return client.MakeResponse(io.NopCloser(bytes.NewReader(jsonData)), 200, nil, nil)
+}
 
+func mockRowsSingleValue(value interface{}, columnType string) driver.RowsNextResultSet {
+	rows := &InMemoryRows{}
+	must(rows.ProcessAndAppendResponse(mockSingleValueResponse(value, columnType)))
 	return rows
 }
 
@@ -143,3 +154,66 @@ func TestInMemoryRowsQuotedLong(t *testing.T) {
 func TestInMemoryRowsDecimalType(t *testing.T) {
 	testRowsDecimalType(t, mockRowsSingleValue)
 }
+
+func TestInMemoryRowsTimestamps(t *testing.T) {
+	testRowsTimestamps(t, mockRowsSingleValue)
+}
+
+func TestInMemoryRowsNextSetConsistency(t *testing.T) {
+	testRowsNextSetConsistency(t, mockRows)
+}
+
+func TestInMemoryRowsStatistics(t *testing.T) {
+	testRowsStatistics(t, mockRows)
+}
+
+func TestInMemoryRowsEmpty(t *testing.T) {
+	rows := &InMemoryRows{}
+	utils.AssertEqual(rows.Next(nil), io.EOF, t, "Next of empty rows didn't return EOF")
+	utils.AssertEqual(rows.Close(), nil, t, "Close of empty rows returned an error")
+	utils.AssertEqual(rows.NextResultSet(), io.EOF, t, "NextResultSet of empty rows didn't return EOF")
+	if rows.Statistics() != nil {
+		t.Errorf("statistics of empty rows should be nil")
+	}
+}
+
+type trackingReadCloser struct {
+	io.Reader
+	closes int
+}
+
+func (b *trackingReadCloser) Close() error {
+	b.closes++
+	return nil
+}
+
+func TestInMemoryRowsProcessAndAppendResponse(t *testing.T) {
+	body := &trackingReadCloser{Reader: strings.NewReader(`{"meta":[{"name":"a","type":"int"}],"data":[[1],[2]],"rows":2,"statistics":{"rows_read":2}}`)}
+	rows := &InMemoryRows{}
+	if err := rows.ProcessAndAppendResponse(client.MakeResponse(body, 200, nil, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	utils.AssertEqual(body.closes, 1, t, "response body should be closed once")
+	utils.AssertEqual(rows.Statistics().RowsRead, int64(2), t, "rows_read doesn't match")
+	dest := make([]driver.Value, 1)
+	for _, expected := range []int32{1, 2} {
+		utils.AssertEqual(rows.Next(dest), nil, t, "Next shouldn't return an error")
+		utils.AssertEqual(dest[0], expected, t, "results are not equal for int32")
+	}
+	utils.AssertEqual(rows.Next(dest), io.EOF, t, "Next should return io.EOF at the end")
+
+	// errors are reported after the rows that were read before the error
+	body = &trackingReadCloser{Reader: strings.NewReader(`{"meta":[{"name":"a","type":"int"}],"data":[[1]],"errors":[{"description":"late error"}]}`)}
+	err := (&InMemoryRows{}).ProcessAndAppendResponse(client.MakeResponse(body, 200, nil, nil))
+	var structuredErr *errors.StructuredError
+	if !stderrors.As(err, &structuredErr) || !strings.Contains(structuredErr.Message, "late error") {
+		t.Errorf("expected a structured error, got %v", err)
+	}
+	utils.AssertEqual(body.closes, 1, t, "response body should be closed on errors")
+
+	for _, response := range []string{`{"meta":[{"name":"a","type":"int"}],"data":[[1],`, `not json`} {
+		if err = (&InMemoryRows{}).ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(strings.NewReader(response)), 200, nil, nil)); err == nil {
+			t.Errorf("expected an error for response %s", response)
+		}
+	}
+}
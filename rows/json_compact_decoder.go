@@ -0,0 +1,141 @@
+package rows
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+)
+
+// jsonCompactDecoder reads a JSON_Compact response incrementally, one row at a time,
+// so that neither the raw response nor all decoded rows have to be held in memory.
+// The fields before "data" are read by readHeader, the fields after it once all rows are read.
+type jsonCompactDecoder struct {
+	decoder  *json.Decoder
+	response types.QueryResponse
+	// inData is true while the rows of the "data" array are being read
+	inData bool
+	// finished is true once the whole response is read
+	finished bool
+}
+
+func newJsonCompactDecoder(reader io.Reader) *jsonCompactDecoder {
+	return &jsonCompactDecoder{decoder: json.NewDecoder(reader)}
+}
+
+func (d *jsonCompactDecoder) syntaxError(err error) error {
+	d.finished = true
+	return errorUtils.ConstructNestedError("wrong response", err)
+}
+
+// readHeader reads the response up to the first row. An empty response is a valid response without columns.
+func (d *jsonCompactDecoder) readHeader() error {
+	token, err := d.decoder.Token()
+	if err == io.EOF {
+		d.finished = true
+		return nil
+	}
+	if err != nil {
+		return d.syntaxError(err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return d.syntaxError(fmt.Errorf("expected a JSON object, got %v", token))
+	}
+	return d.readFields()
+}
+
+// readFields reads the fields of the response object until the start of the "data" array or the end of the object
+func (d *jsonCompactDecoder) readFields() error {
+	for d.decoder.More() {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return d.syntaxError(err)
+		}
+		key, ok := token.(string)
+		if !ok {
+			return d.syntaxError(fmt.Errorf("expected a field name, got %v", token))
+		}
+		switch key {
+		case "data":
+			if d.response.Meta == nil {
+				return d.syntaxError(fmt.Errorf("data is returned before the columns metadata"))
+			}
+			if token, err = d.decoder.Token(); err != nil {
+				return d.syntaxError(err)
+			}
+			if token == nil {
+				continue
+			}
+			if delim, ok := token.(json.Delim); !ok || delim != '[' {
+				return d.syntaxError(fmt.Errorf("expected data to be an array, got %v", token))
+			}
+			d.inData = true
+			return nil
+		case "meta":
+			err = d.decoder.Decode(&d.response.Meta)
+		case "query":
+			err = d.decoder.Decode(&d.response.Query)
+		case "rows":
+			err = d.decoder.Decode(&d.response.Rows)
+		case "errors":
+			err = d.decoder.Decode(&d.response.Errors)
+		case "statistics":
+			err = d.decoder.Decode(&d.response.Statistics)
+		default:
+			var skipped json.RawMessage
+			err = d.decoder.Decode(&skipped)
+		}
+		if err != nil {
+			return d.syntaxError(err)
+		}
+		// errors are reported as soon as they are read, there is no point in reading the rest of the response
+		if len(d.response.Errors) > 0 {
+			d.finished = true
+			return errorUtils.NewStructuredError(d.response.Errors)
+		}
+	}
+	if _, err := d.decoder.Token(); err != nil {
+		return d.syntaxError(err)
+	}
+	d.finished = true
+	return nil
+}
+
+// nextRow decodes the next row into row, returns io.EOF once all rows are read.
+// Passing a *json.RawMessage skips decoding the values.
+func (d *jsonCompactDecoder) nextRow(row interface{}) error {
+	if !d.inData {
+		return io.EOF
+	}
+	if d.decoder.More() {
+		if err := d.decoder.Decode(row); err != nil {
+			d.inData = false
+			return d.syntaxError(err)
+		}
+		return nil
+	}
+	// closing bracket of the data array
+	if _, err := d.decoder.Token(); err != nil {
+		d.inData = false
+		return d.syntaxError(err)
+	}
+	d.inData = false
+	if err := d.readFields(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// skipRows reads the remaining rows without decoding them, returns the errors reported after the rows
+func (d *jsonCompactDecoder) skipRows() error {
+	var row json.RawMessage
+	for {
+		if err := d.nextRow(&row); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
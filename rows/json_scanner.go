@@ -0,0 +1,313 @@
+package rows
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type jsonTokenKind int
+
+const (
+	jsonNull jsonTokenKind = iota
+	jsonTrue
+	jsonFalse
+	jsonNumber
+	jsonString
+	jsonArray
+	jsonObject
+)
+
+// jsonToken is a single JSON value read by jsonScanner. For strings raw holds the
+// unescaped content, for numbers the literal, and for arrays and objects the
+// complete raw JSON. raw may point into the scanner's buffers and is only valid
+// until the next read.
+type jsonToken struct {
+	kind jsonTokenKind
+	raw  []byte
+}
+
+// decode converts the token into the same Go value encoding/json would produce
+func (t jsonToken) decode() (interface{}, error) {
+	switch t.kind {
+	case jsonNull:
+		return nil, nil
+	case jsonTrue:
+		return true, nil
+	case jsonFalse:
+		return false, nil
+	case jsonNumber:
+		return strconv.ParseFloat(string(t.raw), 64)
+	case jsonString:
+		return string(t.raw), nil
+	}
+	var res interface{}
+	if err := json.Unmarshal(t.raw, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// jsonSyntaxError is returned by jsonScanner when the input is not valid JSON
+type jsonSyntaxError struct {
+	msg    string
+	offset int
+}
+
+func (e *jsonSyntaxError) Error() string {
+	return fmt.Sprintf("invalid JSON at offset %d: %s", e.offset, e.msg)
+}
+
+// jsonScanner is a minimal allocation-free JSON tokenizer over a single JSON line.
+// It only validates the structure needed to find value boundaries, full
+// validation of nested values is left to encoding/json.
+type jsonScanner struct {
+	data    []byte
+	pos     int
+	scratch []byte
+}
+
+func (s *jsonScanner) reset(data []byte) {
+	s.data = data
+	s.pos = 0
+}
+
+func (s *jsonScanner) syntaxError(msg string) error {
+	return &jsonSyntaxError{msg: msg, offset: s.pos}
+}
+
+func (s *jsonScanner) skipWhitespace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non-whitespace byte without consuming it, or 0 at the end of input
+func (s *jsonScanner) peek() byte {
+	s.skipWhitespace()
+	if s.pos >= len(s.data) {
+		return 0
+	}
+	return s.data[s.pos]
+}
+
+func (s *jsonScanner) expect(c byte) error {
+	if s.peek() != c {
+		return s.syntaxError(fmt.Sprintf("expected '%c'", c))
+	}
+	s.pos++
+	return nil
+}
+
+// consume consumes c if it is the next non-whitespace byte
+func (s *jsonScanner) consume(c byte) bool {
+	if s.peek() == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *jsonScanner) readLiteral(literal string) error {
+	if len(s.data)-s.pos < len(literal) || string(s.data[s.pos:s.pos+len(literal)]) != literal {
+		return s.syntaxError("invalid literal")
+	}
+	s.pos += len(literal)
+	return nil
+}
+
+func (s *jsonScanner) readNumber() ([]byte, error) {
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch c := s.data[s.pos]; {
+		case c >= '0' && c <= '9', c == '-', c == '+', c == '.', c == 'e', c == 'E':
+			s.pos++
+			continue
+		}
+		break
+	}
+	if start == s.pos {
+		return nil, s.syntaxError("invalid number")
+	}
+	return s.data[start:s.pos], nil
+}
+
+// readString reads a quoted string and returns its unescaped content
+func (s *jsonScanner) readString() ([]byte, error) {
+	if err := s.expect('"'); err != nil {
+		return nil, err
+	}
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '"':
+			s.pos++
+			return s.data[start : s.pos-1], nil
+		case '\\':
+			return s.readEscapedString(start)
+		}
+		s.pos++
+	}
+	return nil, s.syntaxError("unterminated string")
+}
+
+func (s *jsonScanner) readEscapedString(start int) ([]byte, error) {
+	s.scratch = append(s.scratch[:0], s.data[start:s.pos]...)
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return s.scratch, nil
+		case c != '\\':
+			s.scratch = append(s.scratch, c)
+			s.pos++
+			continue
+		}
+		if s.pos+1 >= len(s.data) {
+			break
+		}
+		s.pos += 2
+		switch s.data[s.pos-1] {
+		case '"', '\\', '/':
+			s.scratch = append(s.scratch, s.data[s.pos-1])
+		case 'b':
+			s.scratch = append(s.scratch, '\b')
+		case 'f':
+			s.scratch = append(s.scratch, '\f')
+		case 'n':
+			s.scratch = append(s.scratch, '\n')
+		case 'r':
+			s.scratch = append(s.scratch, '\r')
+		case 't':
+			s.scratch = append(s.scratch, '\t')
+		case 'u':
+			r, ok := s.readHexRune()
+			if !ok {
+				return nil, s.syntaxError("invalid unicode escape")
+			}
+			if utf16.IsSurrogate(r) {
+				if second, ok := s.readSurrogatePair(); ok {
+					r = utf16.DecodeRune(r, second)
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			s.scratch = utf8.AppendRune(s.scratch, r)
+		default:
+			return nil, s.syntaxError("invalid escape sequence")
+		}
+	}
+	return nil, s.syntaxError("unterminated string")
+}
+
+func (s *jsonScanner) readHexRune() (rune, bool) {
+	if len(s.data)-s.pos < 4 {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(string(s.data[s.pos:s.pos+4]), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	s.pos += 4
+	return rune(v), true
+}
+
+func (s *jsonScanner) readSurrogatePair() (rune, bool) {
+	if len(s.data)-s.pos < 6 || s.data[s.pos] != '\\' || s.data[s.pos+1] != 'u' {
+		return 0, false
+	}
+	s.pos += 2
+	return s.readHexRune()
+}
+
+// skipComposite skips an array or an object and returns its raw JSON
+func (s *jsonScanner) skipComposite() ([]byte, error) {
+	start := s.pos
+	depth := 0
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				s.pos++
+				return s.data[start:s.pos], nil
+			}
+		case '"':
+			if _, err := s.readString(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		s.pos++
+	}
+	return nil, s.syntaxError("unterminated array or object")
+}
+
+// next reads the next JSON value
+func (s *jsonScanner) next() (jsonToken, error) {
+	var err error
+	var raw []byte
+	switch c := s.peek(); {
+	case c == 'n':
+		return jsonToken{kind: jsonNull}, s.readLiteral("null")
+	case c == 't':
+		return jsonToken{kind: jsonTrue}, s.readLiteral("true")
+	case c == 'f':
+		return jsonToken{kind: jsonFalse}, s.readLiteral("false")
+	case c == '"':
+		raw, err = s.readString()
+		return jsonToken{kind: jsonString, raw: raw}, err
+	case c == '[':
+		raw, err = s.skipComposite()
+		return jsonToken{kind: jsonArray, raw: raw}, err
+	case c == '{':
+		raw, err = s.skipComposite()
+		return jsonToken{kind: jsonObject, raw: raw}, err
+	case c == '-' || (c >= '0' && c <= '9'):
+		raw, err = s.readNumber()
+		return jsonToken{kind: jsonNumber, raw: raw}, err
+	}
+	return jsonToken{}, s.syntaxError("unexpected character")
+}
+
+// parseIntBytes parses a decimal integer without allocating,
+// returns false if raw is not a plain integer or overflows int64
+func parseIntBytes(raw []byte) (int64, bool) {
+	if len(raw) == 0 {
+		return 0, false
+	}
+	negative := raw[0] == '-'
+	if negative {
+		raw = raw[1:]
+	}
+	if len(raw) == 0 || len(raw) > 19 {
+		return 0, false
+	}
+	var res uint64
+	for _, c := range raw {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		res = res*10 + uint64(c-'0')
+	}
+	if negative {
+		if res > 1<<63 {
+			return 0, false
+		}
+		return -int64(res), true
+	}
+	if res > 1<<63-1 {
+		return 0, false
+	}
+	return int64(res), true
+}
@@ -103,10 +103,16 @@ func extractStructColumn(columnType string) (string, string, error) {
 	return strings.TrimSpace(field[0]), strings.TrimSpace(field[1]), nil
 }
 
-func extractStructColumns(columnTypes string) (map[string]string, error) {
+type structField struct {
+	name      string
+	fieldType string
+}
+
+// extractStructFields returns struct fields in the order they are declared in the struct type
+func extractStructFields(columnTypes string) ([]structField, error) {
 	balance := 0
 	current := strings.Builder{}
-	columns := make(map[string]string)
+	var fields []structField
 	for _, char := range columnTypes {
 		switch char {
 		case '(':
@@ -119,7 +125,7 @@ func extractStructColumns(columnTypes string) (map[string]string, error) {
 			if err != nil {
 				return nil, err
 			}
-			columns[fieldName] = fieldType
+			fields = append(fields, structField{fieldName, fieldType})
 			current.Reset()
 		} else {
 			current.WriteRune(char)
@@ -132,11 +138,47 @@ func extractStructColumns(columnTypes string) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	columns[fieldName] = fieldType
+	fields = append(fields, structField{fieldName, fieldType})
+	return fields, nil
+}
+
+// StructField is a field of a STRUCT type
+type StructField struct {
+	Name string
+	Type string
+}
+
+// ParseStructFields returns the fields of a STRUCT type in the order they are declared,
+// or nil if the type isn't a STRUCT type
+func ParseStructFields(columnType string) ([]StructField, error) {
+	columnType = strings.TrimSuffix(strings.TrimSpace(columnType), nullableSuffix)
+	if !strings.HasPrefix(strings.ToLower(columnType), structPrefix) || !strings.HasSuffix(columnType, complexTypeSuffix) {
+		return nil, nil
+	}
+	fields, err := extractStructFields(columnType[len(structPrefix) : len(columnType)-len(complexTypeSuffix)])
+	if err != nil {
+		return nil, err
+	}
+	res := make([]StructField, len(fields))
+	for i, field := range fields {
+		res[i] = StructField{Name: field.name, Type: field.fieldType}
+	}
+	return res, nil
+}
+
+func extractStructColumns(columnTypes string) (map[string]string, error) {
+	fields, err := extractStructFields(columnTypes)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]string, len(fields))
+	for _, field := range fields {
+		columns[field.name] = field.fieldType
+	}
 	return columns, nil
 }
 
-func parseStruct(structInnerFields string, val interface{}) (map[string]driver.Value, error) {
+func parseStruct(structInnerFields string, val interface{}, location *time.Location) (map[string]driver.Value, error) {
 	fields, err := extractStructColumns(structInnerFields)
 	if err != nil {
 		return nil, errors.ConstructNestedError("error during parsing struct type", err)
@@ -151,7 +193,7 @@ func parseStruct(structInnerFields string, val interface{}) (map[string]driver.V
 	}
 	for fieldName, fieldType := range fields {
 		if fieldValue, ok := structValue[fieldName]; ok {
-			res[fieldName], err = parseValue(fieldType, fieldValue)
+			res[fieldName], err = parseValue(fieldType, fieldValue, location)
 			if err != nil {
 				return nil, errors.ConstructNestedError("error during parsing struct field", err)
 			}
@@ -162,9 +204,15 @@ func parseStruct(structInnerFields string, val interface{}) (map[string]driver.V
 	return res, nil
 }
 
+const (
+	// timestampLayout parses timestamps with any number of fractional digits, including none
+	timestampLayout = "2006-01-02 15:04:05.999999999"
+	dateLayout      = "2006-01-02"
+)
+
+// ParseTimestampTz parses a TIMESTAMPTZ value, keeping its offset
 func ParseTimestampTz(value string) (driver.Value, error) {
-	formats := [...]string{"2006-01-02 15:04:05.000000-07", "2006-01-02 15:04:05.000000-07:00", "2006-01-02 15:04:05.000000-07:00:00",
-		"2006-01-02 15:04:05-07", "2006-01-02 15:04:05-07:00", "2006-01-02 15:04:05-07:00:00"}
+	formats := [...]string{timestampLayout + "-07", timestampLayout + "-07:00", timestampLayout + "-07:00:00"}
 	var res time.Time
 	var err error
 	for _, format := range formats {
@@ -176,18 +224,21 @@ func ParseTimestampTz(value string) (driver.Value, error) {
 	return res, err
 }
 
-// parseDateTimeValue parses different date types
-func parseDateTimeValue(columnType string, value string) (driver.Value, error) {
+// parseDateTimeValue parses different date types. TIMESTAMPTZ values are returned in the location,
+// if it is not nil, dates and timestamps without time zone are returned in UTC.
+func parseDateTimeValue(columnType string, value string, location *time.Location) (driver.Value, error) {
 	switch columnType {
 	case dateType, pgDateType:
-		return time.Parse("2006-01-02", value)
-	case timestampType:
+		return time.Parse(dateLayout, value)
+	case timestampType, timestampNtzType:
 		// Go doesn't use yyyy-mm-dd layout. Instead, it uses the value: Mon Jan 2 15:04:05 MST 2006
-		return time.Parse("2006-01-02 15:04:05", value)
-	case timestampNtzType:
-		return time.Parse("2006-01-02 15:04:05.000000", value)
+		return time.Parse(timestampLayout, value)
 	case timestampTzType:
-		return ParseTimestampTz(value)
+		res, err := ParseTimestampTz(value)
+		if err != nil || location == nil {
+			return res, err
+		}
+		return res.(time.Time).In(location), nil
 	}
 	return nil, fmt.Errorf("type not known: %s", columnType)
 }
@@ -211,14 +262,18 @@ func parseFloatValue(val interface{}) (float64, error) {
 }
 
 // parseSingleValue parses all columns types except arrays
-func parseSingleValue(columnType string, val interface{}) (driver.Value, error) {
+func parseSingleValue(columnType string, val interface{}, location *time.Location) (driver.Value, error) {
 	if err := checkTypeValue(columnType, val); err != nil {
 		return nil, errors.ConstructNestedError("error during value parsing", err)
 	}
 
 	switch columnType {
 	case intType, integerType:
-		return int32(val.(float64)), nil
+		v := val.(float64)
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return nil, fmt.Errorf("value %v is out of range for type %s", v, columnType)
+		}
+		return int32(v), nil
 	case longType, bigIntType:
 		// long values as passed as strings by system engine
 		if unpacked, ok := val.(float64); ok {
@@ -230,10 +285,12 @@ func parseSingleValue(columnType string, val interface{}) (driver.Value, error)
 		return float32(v), err
 	case doubleType, doublePrecisionType:
 		return parseFloatValue(val)
-	case textType, geographyType:
+	case textType:
 		return val.(string), nil
+	case geographyType:
+		return Geography(val.(string)), nil
 	case dateType, pgDateType, timestampType, timestampNtzType, timestampTzType:
-		return parseDateTimeValue(columnType, val.(string))
+		return parseDateTimeValue(columnType, val.(string), location)
 	case booleanType:
 		return val.(bool), nil
 	case byteaType:
@@ -248,26 +305,91 @@ func parseSingleValue(columnType string, val interface{}) (driver.Value, error)
 	return nil, fmt.Errorf("type not known: %s", columnType)
 }
 
-func parseArrayValue(elemType string, val interface{}) (driver.Value, error) {
+var driverValueType = reflect.TypeOf((*driver.Value)(nil)).Elem()
+
+// arrayElementType returns the Go type of the elements of an array of columnType, e.g. int64 for bigint,
+// *int64 for bigint null, []int32 for array(int), decimal.Decimal for decimals and map[string]driver.Value
+// for structs. NULL arrays and structs are nil slices and maps, so they are never pointers.
+func arrayElementType(columnType string) reflect.Type {
+	isNullable := strings.HasSuffix(columnType, nullableSuffix)
+	columnType = strings.TrimSuffix(columnType, nullableSuffix)
+
+	var elemType reflect.Type
+	switch {
+	case !strings.HasSuffix(columnType, complexTypeSuffix):
+		primitive, err := parsePrimitiveType(columnType)
+		if err != nil {
+			return driverValueType
+		}
+		elemType = primitive.goType
+	case strings.HasPrefix(columnType, arrayPrefix):
+		elemType = reflect.SliceOf(arrayElementType(columnType[len(arrayPrefix) : len(columnType)-len(complexTypeSuffix)]))
+	case strings.HasPrefix(columnType, decimalPrefix) || strings.HasPrefix(columnType, numericPrefix):
+		elemType = decimalType
+	case strings.HasPrefix(columnType, structPrefix):
+		elemType = reflect.TypeOf(map[string]driver.Value{})
+	default:
+		return driverValueType
+	}
+	if isNullable && elemType.Kind() != reflect.Slice && elemType.Kind() != reflect.Map {
+		return reflect.PointerTo(elemType)
+	}
+	return elemType
+}
+
+// setArrayElement stores an element parsed by parseValue into elem, an element of a slice of arrayElementType
+func setArrayElement(elem reflect.Value, val driver.Value) error {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case *FireboltDecimal:
+		val = v.Decimal
+	case *FireboltNullDecimal:
+		if !v.Valid {
+			return nil
+		}
+		val = v.Decimal
+	}
+	rv := reflect.ValueOf(val)
+	if elem.Kind() == reflect.Pointer && rv.Type().AssignableTo(elem.Type().Elem()) {
+		ptr := reflect.New(elem.Type().Elem())
+		ptr.Elem().Set(rv)
+		elem.Set(ptr)
+		return nil
+	}
+	if !rv.Type().AssignableTo(elem.Type()) {
+		return fmt.Errorf("unexpected value %v for array element type %s", val, elem.Type())
+	}
+	elem.Set(rv)
+	return nil
+}
+
+// parseArrayValue returns a slice of arrayElementType(elemType), so that database/sql can store it
+// into a pointer to a slice of the same type
+func parseArrayValue(elemType string, val interface{}, location *time.Location) (driver.Value, error) {
 	s := reflect.ValueOf(val)
 	if s.Kind() != reflect.Array && s.Kind() != reflect.Slice {
 		return nil, fmt.Errorf("unexpected value for array type: %T", val)
 	}
-	res := make([]driver.Value, s.Len())
+	res := reflect.MakeSlice(reflect.SliceOf(arrayElementType(elemType)), s.Len(), s.Len())
 
 	for i := 0; i < s.Len(); i++ {
-		var err error
-		res[i], err = parseValue(elemType, s.Index(i).Interface())
+		elem, err := parseValue(elemType, s.Index(i).Interface(), location)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing array element %d: %w", i, err)
 		}
+		if err = setArrayElement(res.Index(i), elem); err != nil {
+			return nil, fmt.Errorf("error parsing array element %d: %w", i, err)
+		}
 	}
-	return res, nil
+	return res.Interface(), nil
 }
 
 // parseValue treating the val according to the column type and casts it to one of the go native types:
-// uint8, uint32, uint64, int32, int64, float32, float64, string, Time or []driver.Value for arrays
-func parseValue(columnType string, val interface{}) (driver.Value, error) {
+// uint8, uint32, uint64, int32, int64, float32, float64, string, Time, or typed slices for arrays,
+// e.g. []int64 for array(bigint), []*int64 for array(bigint null) and [][]string for array(array(text)).
+// TIMESTAMPTZ values are returned in the location, if it is not nil.
+func parseValue(columnType string, val interface{}, location *time.Location) (driver.Value, error) {
 	// No need to parse type if the value is nil
 	if val == nil {
 		return nil, nil
@@ -277,7 +399,7 @@ func parseValue(columnType string, val interface{}) (driver.Value, error) {
 
 	if strings.HasPrefix(columnType, arrayPrefix) && strings.HasSuffix(columnType, complexTypeSuffix) {
 		elemType := columnType[len(arrayPrefix) : len(columnType)-len(complexTypeSuffix)]
-		return parseArrayValue(elemType, val)
+		return parseArrayValue(elemType, val, location)
 	} else if (strings.HasPrefix(columnType, decimalPrefix) || strings.HasPrefix(columnType, numericPrefix)) && strings.HasSuffix(columnType, complexTypeSuffix) {
 		// Store decimals in FireboltNullDecimal, so that they are decomposable for scanning
 		if isNullableType {
@@ -288,10 +410,10 @@ func parseValue(columnType string, val interface{}) (driver.Value, error) {
 			return &res, res.Scan(val)
 		}
 	} else if strings.HasPrefix(columnType, structPrefix) && strings.HasSuffix(columnType, complexTypeSuffix) {
-		return parseStruct(columnType[len(structPrefix):len(columnType)-len(complexTypeSuffix)], val)
+		return parseStruct(columnType[len(structPrefix):len(columnType)-len(complexTypeSuffix)], val, location)
 	} else if strings.HasSuffix(columnType, nullableSuffix) {
-		return parseValue(columnType[0:len(columnType)-len(nullableSuffix)], val)
+		return parseValue(columnType[0:len(columnType)-len(nullableSuffix)], val, location)
 	}
 
-	return parseSingleValue(columnType, val)
+	return parseSingleValue(columnType, val, location)
 }
@@ -19,7 +19,7 @@ func TestParseValueRejectsInvalidArrays(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_, err := parseValue(test.typ, test.value)
+			_, err := parseValue(test.typ, test.value, nil)
 			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
 				t.Fatalf("parseValue() error = %v, want error containing %q", err, test.wantErr)
 			}
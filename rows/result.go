@@ -1,8 +1,23 @@
 package rows
 
-import "github.com/firebolt-db/firebolt-go-sdk/logging"
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/firebolt-db/firebolt-go-sdk/logging"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+)
+
+// StatisticsRows is implemented by the rows of regular queries, which can hold several result sets
+type StatisticsRows interface {
+	driver.RowsNextResultSet
+	// Statistics returns the execution statistics of the current result set, or nil if the server
+	// didn't report them. Streamed result sets only report them once they are read to the end.
+	Statistics() *types.Statistics
+}
 
 type FireboltResult struct {
+	statistics []*types.Statistics
 }
 
 // LastInsertId returns last inserted ID, not supported by firebolt
@@ -16,3 +31,27 @@ func (r FireboltResult) RowsAffected() (int64, error) {
 	logging.Infolog.Printf("result RowsAffected is called and always returns 0")
 	return 0, nil
 }
+
+// Statistics returns the execution statistics of every statement of the query in the order of the statements.
+// The statistics are nil for statements that didn't report them.
+func (r FireboltResult) Statistics() []*types.Statistics {
+	return r.statistics
+}
+
+// parseStatistics converts the statistics decoded from a response into types.Statistics,
+// statistics are informational, so nil is returned if they can't be converted
+func parseStatistics(statistics interface{}) *types.Statistics {
+	if statistics == nil {
+		return nil
+	}
+	raw, err := json.Marshal(statistics)
+	if err != nil {
+		return nil
+	}
+	var res types.Statistics
+	if err = json.Unmarshal(raw, &res); err != nil {
+		logging.Infolog.Printf("failed to parse query statistics: %v", err)
+		return nil
+	}
+	return &res
+}
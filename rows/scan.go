@@ -0,0 +1,378 @@
+package rows
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const structFieldTag = "firebolt"
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	decimalType = reflect.TypeOf(decimal.Decimal{})
+)
+
+type arrayScanner struct {
+	dest interface{}
+}
+
+// ScanArray returns a sql.Scanner that stores a Firebolt array value into the
+// slice pointed to by dest, converting the elements to its element type.
+// Arrays are returned as slices of the type of their elements, e.g. []int32
+// for array(int) or []*int64 for array(bigint null), which rows.Scan stores
+// directly into a pointer to a slice of the same type; ScanArray is needed for
+// other element types, e.g. *[]int64, *[]sql.NullInt32 or *[]MyStruct.
+// Pointer elements receive nil for NULL elements, nested arrays are scanned
+// into nested slices and struct elements are scanned like ScanStruct does.
+// A NULL array is stored as a nil slice.
+//
+//	var ids []int64 // for an array(int) column
+//	err := rows.Scan(fireboltRows.ScanArray(&ids))
+func ScanArray(dest interface{}) sql.Scanner {
+	return &arrayScanner{dest: dest}
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *arrayScanner) Scan(src interface{}) error {
+	dv, err := scanDestination(s.dest)
+	if err != nil {
+		return err
+	}
+	if dv.Kind() != reflect.Slice && dv.Kind() != reflect.Array {
+		return fmt.Errorf("array can only be scanned into a pointer to a slice or an array, got %T", s.dest)
+	}
+	return convertValue(src, dv)
+}
+
+type structScanner struct {
+	dest interface{}
+}
+
+// ScanStruct returns a sql.Scanner that stores a Firebolt struct value into the
+// Go struct or map pointed to by dest. Struct fields are matched with the
+// Firebolt field names using the `firebolt:"name"` tag, or the Go field name
+// compared case-insensitively. Fields that are missing from the value are left
+// untouched, and values that have no matching field are ignored.
+//
+//	type point struct {
+//		X int32 `firebolt:"x"`
+//		Y int32 `firebolt:"y"`
+//	}
+//	var p point
+//	err := rows.Scan(fireboltRows.ScanStruct(&p))
+func ScanStruct(dest interface{}) sql.Scanner {
+	return &structScanner{dest: dest}
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *structScanner) Scan(src interface{}) error {
+	dv, err := scanDestination(s.dest)
+	if err != nil {
+		return err
+	}
+	kind := dv.Kind()
+	if kind == reflect.Pointer {
+		kind = dv.Type().Elem().Kind()
+	}
+	if kind != reflect.Struct && kind != reflect.Map {
+		return fmt.Errorf("struct can only be scanned into a pointer to a struct or a map, got %T", s.dest)
+	}
+	return convertValue(src, dv)
+}
+
+// ConvertValue stores a value returned by the driver for an array, struct or
+// primitive column into dest, which must be a non-nil pointer. It is the
+// conversion used by ScanArray and ScanStruct, and it can be used directly
+// when working with driver.Rows.
+func ConvertValue(src driver.Value, dest interface{}) error {
+	dv, err := scanDestination(dest)
+	if err != nil {
+		return err
+	}
+	return convertValue(src, dv)
+}
+
+func scanDestination(dest interface{}) (reflect.Value, error) {
+	dpv := reflect.ValueOf(dest)
+	if dpv.Kind() != reflect.Pointer || dpv.IsNil() {
+		return reflect.Value{}, fmt.Errorf("destination must be a non-nil pointer, got %T", dest)
+	}
+	return dpv.Elem(), nil
+}
+
+// derefElement returns the value a nullable array element points to, or nil for a NULL element
+func derefElement(src interface{}) interface{} {
+	if sv := reflect.ValueOf(src); sv.Kind() == reflect.Pointer {
+		if sv.IsNil() {
+			return nil
+		}
+		return sv.Elem().Interface()
+	}
+	return src
+}
+
+// unwrapDecimal returns the underlying decimal.Decimal for decimal values produced by parseValue
+func unwrapDecimal(src interface{}) (interface{}, bool) {
+	switch v := src.(type) {
+	case *FireboltDecimal:
+		return v.Decimal, true
+	case FireboltDecimal:
+		return v.Decimal, true
+	case *FireboltNullDecimal:
+		if !v.Valid {
+			return nil, true
+		}
+		return v.Decimal, true
+	case FireboltNullDecimal:
+		if !v.Valid {
+			return nil, true
+		}
+		return v.Decimal, true
+	case decimal.Decimal:
+		return v, true
+	}
+	return src, false
+}
+
+// convertValue recursively converts src into dv, which must be settable
+func convertValue(src interface{}, dv reflect.Value) error {
+	src = derefElement(src)
+	src, isDecimal := unwrapDecimal(src)
+
+	if src == nil {
+		switch dv.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			dv.Set(reflect.Zero(dv.Type()))
+			return nil
+		}
+		if dv.CanAddr() && dv.Addr().Type().Implements(scannerType) {
+			return dv.Addr().Interface().(sql.Scanner).Scan(nil)
+		}
+		return fmt.Errorf("cannot scan NULL into %s", dv.Type())
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dv.Type()) && sv.Kind() != reflect.Slice && sv.Kind() != reflect.Map {
+		dv.Set(sv)
+		return nil
+	}
+
+	switch dv.Kind() {
+	case reflect.Interface:
+		dv.Set(sv)
+		return nil
+	case reflect.Pointer:
+		elem := reflect.New(dv.Type().Elem())
+		if err := convertValue(src, elem.Elem()); err != nil {
+			return err
+		}
+		dv.Set(elem)
+		return nil
+	}
+
+	if isDecimal {
+		return convertDecimal(src.(decimal.Decimal), dv)
+	}
+
+	// Firebolt wrapper types and sql.Null* types know how to scan themselves
+	if dv.CanAddr() && dv.Addr().Type().Implements(scannerType) {
+		return dv.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+
+	switch dv.Kind() {
+	case reflect.Slice:
+		if b, ok := src.([]byte); ok && dv.Type().Elem().Kind() == reflect.Uint8 {
+			dv.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+			return fmt.Errorf("cannot scan %T into %s", src, dv.Type())
+		}
+		res := reflect.MakeSlice(dv.Type(), sv.Len(), sv.Len())
+		for i := 0; i < sv.Len(); i++ {
+			if err := convertValue(sv.Index(i).Interface(), res.Index(i)); err != nil {
+				return fmt.Errorf("error scanning array element %d: %w", i, err)
+			}
+		}
+		dv.Set(res)
+		return nil
+	case reflect.Array:
+		if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+			return fmt.Errorf("cannot scan %T into %s", src, dv.Type())
+		}
+		if sv.Len() != dv.Len() {
+			return fmt.Errorf("cannot scan array of length %d into %s", sv.Len(), dv.Type())
+		}
+		for i := 0; i < sv.Len(); i++ {
+			if err := convertValue(sv.Index(i).Interface(), dv.Index(i)); err != nil {
+				return fmt.Errorf("error scanning array element %d: %w", i, err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		return convertMap(sv, dv)
+	case reflect.Struct:
+		if dv.Type() == timeType {
+			return fmt.Errorf("cannot scan %T into %s", src, dv.Type())
+		}
+		return convertStruct(sv, dv)
+	}
+	return convertPrimitive(sv, dv)
+}
+
+func convertMap(sv reflect.Value, dv reflect.Value) error {
+	if sv.Kind() != reflect.Map {
+		return fmt.Errorf("cannot scan %s into %s", sv.Type(), dv.Type())
+	}
+	if dv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("cannot scan struct into %s, map key must be a string", dv.Type())
+	}
+	res := reflect.MakeMapWithSize(dv.Type(), sv.Len())
+	for _, key := range sv.MapKeys() {
+		elem := reflect.New(dv.Type().Elem()).Elem()
+		if err := convertValue(sv.MapIndex(key).Interface(), elem); err != nil {
+			return fmt.Errorf("error scanning struct field %s: %w", key.String(), err)
+		}
+		res.SetMapIndex(reflect.ValueOf(key.String()).Convert(dv.Type().Key()), elem)
+	}
+	dv.Set(res)
+	return nil
+}
+
+// StructFieldName returns the Firebolt field name a Go struct field is mapped to when it is scanned or bound,
+// or an empty string if the field should be skipped
+func StructFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	if tag, ok := field.Tag.Lookup(structFieldTag); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func convertStruct(sv reflect.Value, dv reflect.Value) error {
+	if sv.Kind() != reflect.Map || sv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("cannot scan %s into %s", sv.Type(), dv.Type())
+	}
+	for i := 0; i < dv.NumField(); i++ {
+		name := StructFieldName(dv.Type().Field(i))
+		if name == "" {
+			continue
+		}
+		value := sv.MapIndex(reflect.ValueOf(name))
+		if !value.IsValid() {
+			// Fall back to a case-insensitive match, e.g. field Name for value "name"
+			for _, key := range sv.MapKeys() {
+				if strings.EqualFold(key.String(), name) {
+					value = sv.MapIndex(key)
+					break
+				}
+			}
+		}
+		if !value.IsValid() {
+			continue
+		}
+		if err := convertValue(value.Interface(), dv.Field(i)); err != nil {
+			return fmt.Errorf("error scanning struct field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func convertDecimal(d decimal.Decimal, dv reflect.Value) error {
+	switch dv.Kind() {
+	case reflect.Struct:
+		switch dv.Type() {
+		case decimalType:
+			dv.Set(reflect.ValueOf(d))
+			return nil
+		case reflect.TypeOf(FireboltDecimal{}):
+			dv.Set(reflect.ValueOf(FireboltDecimal{Decimal: d}))
+			return nil
+		}
+	case reflect.String:
+		dv.SetString(d.String())
+		return nil
+	case reflect.Float32, reflect.Float64:
+		dv.SetFloat(d.InexactFloat64())
+		return nil
+	}
+	if dv.CanAddr() && dv.Addr().Type().Implements(scannerType) {
+		// e.g. FireboltNullDecimal or sql.NullString
+		return dv.Addr().Interface().(sql.Scanner).Scan(d.String())
+	}
+	return fmt.Errorf("cannot scan decimal into %s", dv.Type())
+}
+
+func convertPrimitive(sv reflect.Value, dv reflect.Value) error {
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v = sv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if sv.Uint() > 1<<63-1 {
+				return fmt.Errorf("value %d overflows %s", sv.Uint(), dv.Type())
+			}
+			v = int64(sv.Uint())
+		default:
+			return fmt.Errorf("cannot scan %s into %s", sv.Type(), dv.Type())
+		}
+		if dv.OverflowInt(v) {
+			return fmt.Errorf("value %d overflows %s", v, dv.Type())
+		}
+		dv.SetInt(v)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var v uint64
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if sv.Int() < 0 {
+				return fmt.Errorf("value %d overflows %s", sv.Int(), dv.Type())
+			}
+			v = uint64(sv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v = sv.Uint()
+		default:
+			return fmt.Errorf("cannot scan %s into %s", sv.Type(), dv.Type())
+		}
+		if dv.OverflowUint(v) {
+			return fmt.Errorf("value %d overflows %s", v, dv.Type())
+		}
+		dv.SetUint(v)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch sv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dv.SetFloat(sv.Float())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dv.SetFloat(float64(sv.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			dv.SetFloat(float64(sv.Uint()))
+		default:
+			return fmt.Errorf("cannot scan %s into %s", sv.Type(), dv.Type())
+		}
+		return nil
+	case reflect.String, reflect.Bool:
+		if sv.Kind() == dv.Kind() {
+			dv.Set(sv.Convert(dv.Type()))
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot scan %s into %s", sv.Type(), dv.Type())
+}
@@ -0,0 +1,213 @@
+package rows
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+	"github.com/shopspring/decimal"
+)
+
+func mustParseValue(t *testing.T, columnType string, value interface{}) interface{} {
+	parsed, err := parseValue(columnType, value, nil)
+	if err != nil {
+		t.Fatalf("Error parsing value of type %s: %v", columnType, err)
+	}
+	return parsed
+}
+
+func TestArrayValuesAreTypedSlices(t *testing.T) {
+	// database/sql stores values into destinations of the same type, e.g. a *[]int64 for array(bigint)
+	utils.AssertEqual(reflect.TypeOf(mustParseValue(t, "array(bigint)", []interface{}{1.0})), reflect.TypeOf([]int64{}), t, "wrong type for array(bigint)")
+	utils.AssertEqual(reflect.TypeOf(mustParseValue(t, "array(text)", []interface{}{"a"})), reflect.TypeOf([]string{}), t, "wrong type for array(text)")
+	utils.AssertEqual(reflect.TypeOf(mustParseValue(t, "array(array(int))", []interface{}{})), reflect.TypeOf([][]int32{}), t, "wrong type for array(array(int))")
+	utils.AssertEqual(reflect.TypeOf(mustParseValue(t, "array(Decimal(38, 2))", []interface{}{})), reflect.TypeOf([]decimal.Decimal{}), t, "wrong type for array(Decimal(38, 2))")
+	utils.AssertEqual(reflect.TypeOf(mustParseValue(t, "array(struct(a int))", []interface{}{})), reflect.TypeOf([]map[string]driver.Value{}), t, "wrong type for array(struct(a int))")
+
+	ints := mustParseValue(t, "array(bigint null)", []interface{}{1.0, nil}).([]*int64)
+	utils.AssertEqual(len(ints), 2, t, "Array length does not match")
+	utils.AssertEqual(*ints[0], int64(1), t, ARRAY_VALUES_DO_NOT_MATCH)
+	if ints[1] != nil {
+		t.Errorf("Expected NULL element to be a nil pointer, got %v", *ints[1])
+	}
+
+	nested := mustParseValue(t, "array(array(text) null)", []interface{}{[]interface{}{"a"}, nil}).([][]string)
+	utils.AssertEqual(nested[0], []string{"a"}, t, ARRAY_VALUES_DO_NOT_MATCH)
+	if nested[1] != nil {
+		t.Errorf("Expected NULL nested array to be a nil slice, got %v", nested[1])
+	}
+
+	// the values are stored into sql.Scanner destinations such as FireboltArray unchanged
+	var array FireboltArray
+	if err := array.Scan(ints); err != nil {
+		t.Fatalf("Error scanning array: %v", err)
+	}
+	utils.AssertEqual(array[0], int64(1), t, ARRAY_VALUES_DO_NOT_MATCH)
+	utils.AssertEqual(array[1], nil, t, ARRAY_VALUES_DO_NOT_MATCH)
+}
+
+func TestScanArrayPrimitives(t *testing.T) {
+	var ints []int64
+	if err := ScanArray(&ints).Scan(mustParseValue(t, "array(int)", []interface{}{1.0, 2.0, 3.0})); err != nil {
+		t.Fatalf("Error scanning int array: %v", err)
+	}
+	utils.AssertEqual(ints, []int64{1, 2, 3}, t, ARRAY_VALUES_DO_NOT_MATCH)
+
+	var strs []string
+	if err := ScanArray(&strs).Scan(mustParseValue(t, "array(text)", []interface{}{"a", "b"})); err != nil {
+		t.Fatalf("Error scanning text array: %v", err)
+	}
+	utils.AssertEqual(strs, []string{"a", "b"}, t, ARRAY_VALUES_DO_NOT_MATCH)
+
+	var floats []float64
+	if err := ScanArray(&floats).Scan(mustParseValue(t, "array(real)", []interface{}{1.5, "inf"})); err != nil {
+		t.Fatalf("Error scanning real array: %v", err)
+	}
+	utils.AssertEqual(floats[0], 1.5, t, ARRAY_VALUES_DO_NOT_MATCH)
+
+	var dates []time.Time
+	if err := ScanArray(&dates).Scan(mustParseValue(t, "array(date)", []interface{}{"2024-01-02"})); err != nil {
+		t.Fatalf("Error scanning date array: %v", err)
+	}
+	utils.AssertEqual(dates[0], time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), t, ARRAY_VALUES_DO_NOT_MATCH)
+}
+
+func TestScanArrayNullable(t *testing.T) {
+	var ints []*int64
+	if err := ScanArray(&ints).Scan(mustParseValue(t, "array(int null)", []interface{}{1.0, nil})); err != nil {
+		t.Fatalf("Error scanning nullable int array: %v", err)
+	}
+	utils.AssertEqual(len(ints), 2, t, "Array length does not match")
+	utils.AssertEqual(*ints[0], int64(1), t, ARRAY_VALUES_DO_NOT_MATCH)
+	if ints[1] != nil {
+		t.Errorf("Expected NULL element to be scanned as nil pointer, got %v", *ints[1])
+	}
+
+	var nullInts []sql.NullInt32
+	if err := ScanArray(&nullInts).Scan(mustParseValue(t, "array(int null)", []interface{}{nil, 2.0})); err != nil {
+		t.Fatalf("Error scanning nullable int array into sql.NullInt32: %v", err)
+	}
+	utils.AssertEqual(nullInts[0].Valid, false, t, ARRAY_VALUES_DO_NOT_MATCH)
+	utils.AssertEqual(nullInts[1].Int32, int32(2), t, ARRAY_VALUES_DO_NOT_MATCH)
+
+	if err := ScanArray(&ints).Scan(nil); err != nil {
+		t.Fatalf("Error scanning NULL array: %v", err)
+	}
+	if ints != nil {
+		t.Errorf("Expected NULL array to be scanned as nil slice, got %v", ints)
+	}
+
+	var notNullInts []int64
+	if err := ScanArray(&notNullInts).Scan([]interface{}{nil}); err == nil {
+		t.Errorf("Expected an error scanning NULL element into a non-pointer slice")
+	}
+}
+
+func TestScanArrayNested(t *testing.T) {
+	var nested [][]string
+	value := mustParseValue(t, "array(array(text))", []interface{}{[]interface{}{"a", "b"}, []interface{}{}})
+	if err := ScanArray(&nested).Scan(value); err != nil {
+		t.Fatalf("Error scanning nested array: %v", err)
+	}
+	utils.AssertEqual(len(nested), 2, t, "Array length does not match")
+	utils.AssertEqual(nested[0], []string{"a", "b"}, t, ARRAY_VALUES_DO_NOT_MATCH)
+	utils.AssertEqual(len(nested[1]), 0, t, "Nested array length does not match")
+}
+
+func TestScanArrayDecimal(t *testing.T) {
+	var decimals []decimal.Decimal
+	value := mustParseValue(t, "array(Decimal(38, 2) null)", []interface{}{1.25, nil})
+	if err := ScanArray(&decimals).Scan(value); err == nil {
+		t.Errorf("Expected an error scanning NULL decimal into decimal.Decimal")
+	}
+
+	var nullDecimals []*decimal.Decimal
+	if err := ScanArray(&nullDecimals).Scan(value); err != nil {
+		t.Fatalf("Error scanning decimal array: %v", err)
+	}
+	utils.AssertEqual(*nullDecimals[0], decimal.NewFromFloat(1.25), t, ARRAY_VALUES_DO_NOT_MATCH)
+	if nullDecimals[1] != nil {
+		t.Errorf("Expected NULL decimal to be scanned as nil pointer")
+	}
+}
+
+func TestScanArrayErrors(t *testing.T) {
+	var small []int8
+	if err := ScanArray(&small).Scan([]interface{}{int64(1000)}); err == nil {
+		t.Errorf("Expected an overflow error")
+	}
+	var strs []string
+	if err := ScanArray(&strs).Scan([]interface{}{int32(1)}); err == nil {
+		t.Errorf("Expected an error scanning int into string")
+	}
+	var notSlice int
+	if err := ScanArray(&notSlice).Scan([]interface{}{int32(1)}); err == nil {
+		t.Errorf("Expected an error scanning array into int")
+	}
+	if err := ScanArray(strs).Scan([]interface{}{}); err == nil {
+		t.Errorf("Expected an error scanning into a non-pointer")
+	}
+}
+
+type scanTestInner struct {
+	A []int32
+	B string
+}
+
+type scanTestStruct struct {
+	ID       int64         `firebolt:"id"`
+	Inner    scanTestInner `firebolt:"s"`
+	Optional *string       `firebolt:"opt"`
+	Ignored  string        `firebolt:"-"`
+}
+
+func TestScanStruct(t *testing.T) {
+	value := mustParseValue(t, "struct(id int, s struct(a array(int), b text), opt text null)", map[string]interface{}{
+		"id":  1.0,
+		"s":   map[string]interface{}{"a": []interface{}{1.0, 2.0}, "b": "text"},
+		"opt": nil,
+	})
+
+	res := scanTestStruct{Ignored: "keep"}
+	if err := ScanStruct(&res).Scan(value); err != nil {
+		t.Fatalf("Error scanning struct: %v", err)
+	}
+	utils.AssertEqual(res.ID, int64(1), t, STRUCT_VALUES_DO_NOT_MATCH)
+	utils.AssertEqual(res.Inner.A, []int32{1, 2}, t, STRUCT_VALUES_DO_NOT_MATCH)
+	utils.AssertEqual(res.Inner.B, "text", t, STRUCT_VALUES_DO_NOT_MATCH)
+	if res.Optional != nil {
+		t.Errorf("Expected NULL field to be scanned as nil pointer")
+	}
+	utils.AssertEqual(res.Ignored, "keep", t, STRUCT_VALUES_DO_NOT_MATCH)
+
+	var m map[string]interface{}
+	if err := ScanStruct(&m).Scan(value); err != nil {
+		t.Fatalf("Error scanning struct into map: %v", err)
+	}
+	utils.AssertEqual(m["id"], int32(1), t, STRUCT_VALUES_DO_NOT_MATCH)
+
+	if err := ScanStruct(&res).Scan(nil); err == nil {
+		t.Errorf("Expected an error scanning NULL into a struct")
+	}
+	var ptr *scanTestStruct
+	if err := ScanStruct(&ptr).Scan(nil); err != nil || ptr != nil {
+		t.Errorf("Expected NULL struct to be scanned as nil pointer, got %v, %v", ptr, err)
+	}
+}
+
+func TestScanArrayOfStructs(t *testing.T) {
+	value := mustParseValue(t, "array(struct(a array(int), b text))", []interface{}{
+		map[string]interface{}{"a": []interface{}{1.0}, "b": "x"},
+		map[string]interface{}{"a": []interface{}{}, "b": "y"},
+	})
+	var res []scanTestInner
+	if err := ScanArray(&res).Scan(value); err != nil {
+		t.Fatalf("Error scanning array of structs: %v", err)
+	}
+	utils.AssertEqual(len(res), 2, t, "Array length does not match")
+	utils.AssertEqual(res[0].A, []int32{1}, t, STRUCT_VALUES_DO_NOT_MATCH)
+	utils.AssertEqual(res[1].B, "y", t, STRUCT_VALUES_DO_NOT_MATCH)
+}
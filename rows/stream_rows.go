@@ -18,23 +18,44 @@ type StreamRows struct {
 	ColumnReader
 	responses         []*client.Response
 	resultSetPosition int
+	// statistics of every response, set once the response is read to the end
+	statistics []*types.Statistics
 	// current row
-	rowReader        *bufio.Reader
-	dataBuffer       [][]interface{}
-	dataBufferCursor int
+	rowReader  *bufio.Reader
+	lineBuffer []byte
+	// batch holds the current data message decoded column by column, it is reused between messages
+	batch            *columnarBatch
 	consumedResponse bool
 }
 
-func (r *StreamRows) readJsonLine() (types.JSONLinesRecord, error) {
+// readLine reads the next line of the current response. The returned slice is only valid until the next read
+func (r *StreamRows) readLine() ([]byte, error) {
 	reader := r.reader()
 
-	var record types.JSONLinesRecord
-	rawJsonLine, err := reader.ReadBytes('\n')
+	line, err := reader.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		// The line is longer than the reader buffer, collect it in a reusable buffer
+		r.lineBuffer = append(r.lineBuffer[:0], line...)
+		for errors.Is(err, bufio.ErrBufferFull) {
+			line, err = reader.ReadSlice('\n')
+			r.lineBuffer = append(r.lineBuffer, line...)
+		}
+		line = r.lineBuffer
+	}
 	if err != nil && err != io.EOF {
-		return record, errorUtils.ConstructNestedError("Error reading JSON line:", err)
+		return nil, errorUtils.ConstructNestedError("Error reading JSON line:", err)
+	}
+	if len(line) == 0 {
+		return nil, io.EOF
 	}
-	if len(rawJsonLine) == 0 {
-		return types.JSONLinesRecord{}, io.EOF
+	return line, nil
+}
+
+func (r *StreamRows) readJsonLine() (types.JSONLinesRecord, error) {
+	var record types.JSONLinesRecord
+	rawJsonLine, err := r.readLine()
+	if err != nil {
+		return record, err
 	}
 	err = json.Unmarshal(rawJsonLine, &record)
 	if err != nil {
@@ -64,8 +85,7 @@ func (r *StreamRows) Close() error {
 		}
 	}
 	r.resultSetPosition = len(r.responses)
-	r.dataBuffer = nil
-	r.dataBufferCursor = 0
+	r.batch = nil
 	r.consumedResponse = true
 	if closeErr != nil {
 		return errorUtils.ConstructNestedError("Error closing response body:", closeErr)
@@ -73,66 +93,80 @@ func (r *StreamRows) Close() error {
 	return nil
 }
 
-func (r *StreamRows) bufferHasMoreData() bool {
-	return r.dataBuffer != nil && r.dataBufferCursor < len(r.dataBuffer)
-}
-
-func (r *StreamRows) populateDataBuffer() error {
-	nextRecord, err := r.readJsonLine()
-	if err == io.EOF {
-		r.consumedResponse = true
-		r.dataBuffer = [][]interface{}{}
-		r.dataBufferCursor = 0
-		return nil
+// processControlRecord handles a record that doesn't contain data,
+// returns io.EOF if the result set was finished successfully
+func (r *StreamRows) processControlRecord(record types.JSONLinesRecord) error {
+	if record.Statistics != nil && r.resultSetPosition < len(r.statistics) {
+		r.statistics[r.resultSetPosition] = parseStatistics(*record.Statistics)
 	}
-	if err != nil {
-		return errorUtils.ConstructNestedError("Error reading JSON line:", err)
-	}
-	switch nextRecord.MessageType {
+	switch record.MessageType {
 	case types.MessageTypeError:
 		errors := make([]types.ErrorDetails, 0)
-		if nextRecord.Errors != nil {
-			errors = *nextRecord.Errors
+		if record.Errors != nil {
+			errors = *record.Errors
 		}
 		r.consumedResponse = true
 		return errorUtils.NewStructuredError(errors)
 	case types.MessageTypeSuccess:
 		r.consumedResponse = true
 		return io.EOF
-	default:
-		if nextRecord.MessageType != types.MessageTypeData {
-			return fmt.Errorf("unexpected message type returned from the server %s", nextRecord.MessageType)
-		}
-		r.dataBuffer = *nextRecord.Data
-		r.dataBufferCursor = 0
 	}
-	return nil
+	return fmt.Errorf("unexpected message type returned from the server %s", record.MessageType)
+}
+
+// populateBatch reads the next record and decodes its data directly into the typed column buffers of the batch
+func (r *StreamRows) populateBatch() error {
+	line, err := r.readLine()
+	if err == io.EOF {
+		r.consumedResponse = true
+		r.batch.reset()
+		return nil
+	}
+	if err != nil {
+		return errorUtils.ConstructNestedError("Error reading JSON line:", err)
+	}
+	messageType, err := r.batch.decodeLine(line)
+	if err != nil {
+		return errorUtils.ConstructNestedError("Error reading JSON line:", errorUtils.ConstructNestedError("JSON parse error:", err))
+	}
+	if messageType == types.MessageTypeData {
+		return nil
+	}
+	var record types.JSONLinesRecord
+	if err = json.Unmarshal(line, &record); err != nil {
+		return errorUtils.ConstructNestedError("Error reading JSON line:", errorUtils.ConstructNestedError("JSON parse error:", err))
+	}
+	return r.processControlRecord(record)
 }
 
 // Next fetches the values of the next row, returns io.EOF if it was the end
 func (r *StreamRows) Next(dest []driver.Value) error {
-	if r.consumedResponse {
-		return io.EOF
-	}
-	if !r.bufferHasMoreData() {
-		if err := r.populateDataBuffer(); err != nil {
-			r.consumedResponse = true
-			return err
-		}
+	batch, err := r.nextColumnarBatch()
+	if err != nil {
+		return err
 	}
-	// We populated the buffer, but it's still empty
-	if r.dataBufferCursor == len(r.dataBuffer) {
-		return io.EOF
+	if err = batch.next(dest); err != nil {
+		return errorUtils.ConstructNestedError("error during fetching Next result", err)
 	}
+	return nil
+}
 
-	for i, column := range r.columns {
-		var err error
-		if dest[i], err = parseValue(column.fbType.dbType, r.dataBuffer[r.dataBufferCursor][i]); err != nil {
-			return errorUtils.ConstructNestedError("error during fetching Next result", err)
+// nextColumnarBatch returns the batch holding the next rows of the current result set, reading and
+// decoding the next data message if all the decoded rows were read, returns io.EOF if it was the end
+func (r *StreamRows) nextColumnarBatch() (*columnarBatch, error) {
+	if r.batch == nil {
+		r.batch = newColumnarBatch(r.columns, r.location)
+	}
+	for !r.batch.hasMoreData() {
+		if r.consumedResponse {
+			return nil, io.EOF
+		}
+		if err := r.populateBatch(); err != nil {
+			r.consumedResponse = true
+			return nil, err
 		}
 	}
-	r.dataBufferCursor++
-	return nil
+	return r.batch, nil
 }
 
 // HasNextResultSet reports whether there is another result set available
@@ -154,21 +188,22 @@ func (r *StreamRows) fetchColumns() error {
 	}
 }
 
-// NextResultSet advances to the next result set, if it is available, otherwise returns io.EOF
+// NextResultSet advances to the next result set, if it is available, otherwise returns io.EOF.
+// The remaining rows of the current result set are discarded.
 func (r *StreamRows) NextResultSet() error {
-	err := r.responses[r.resultSetPosition].Body().Close()
-	if err != nil {
-		return errorUtils.ConstructNestedError("Error closing response body:", err)
+	if !r.HasNextResultSet() {
+		return io.EOF
 	}
 
-	if r.resultSetPosition+1 == len(r.responses) {
-		return io.EOF
+	if body := r.responses[r.resultSetPosition].Body(); body != nil {
+		if err := body.Close(); err != nil {
+			return errorUtils.ConstructNestedError("Error closing response body:", err)
+		}
 	}
 
 	r.resultSetPosition++
 	r.rowReader = nil
-	r.dataBuffer = nil
-	r.dataBufferCursor = 0
+	r.batch = nil
 	r.consumedResponse = false
 
 	return r.fetchColumns()
@@ -177,12 +212,75 @@ func (r *StreamRows) NextResultSet() error {
 // ProcessAndAppendResponse appends a response to the list of row streams
 func (r *StreamRows) ProcessAndAppendResponse(response *client.Response) error {
 	r.responses = append(r.responses, response)
+	r.statistics = append(r.statistics, nil)
 	if r.columns == nil {
 		return r.fetchColumns()
 	}
 	return nil
 }
 
+// Statistics returns the execution statistics of the current result set, they are only
+// available once the result set is read to the end
+func (r *StreamRows) Statistics() *types.Statistics {
+	if r.resultSetPosition >= len(r.statistics) {
+		return nil
+	}
+	return r.statistics[r.resultSetPosition]
+}
+
+// drainResultSet reads the current result set to the end without decoding its data,
+// returns the error reported by the server, if any
+func (r *StreamRows) drainResultSet() error {
+	for !r.consumedResponse {
+		line, err := r.readLine()
+		if err == io.EOF {
+			r.consumedResponse = true
+			return nil
+		}
+		if err != nil {
+			r.consumedResponse = true
+			return errorUtils.ConstructNestedError("Error reading JSON line:", err)
+		}
+		// data isn't decoded, only the fields of the control records
+		var record struct {
+			MessageType types.RecordMessageType `json:"message_type"`
+			Errors      *[]types.ErrorDetails   `json:"errors,omitempty"`
+			Statistics  *interface{}            `json:"statistics,omitempty"`
+		}
+		if err = json.Unmarshal(line, &record); err != nil {
+			r.consumedResponse = true
+			return errorUtils.ConstructNestedError("Error reading JSON line:", errorUtils.ConstructNestedError("JSON parse error:", err))
+		}
+		if record.MessageType == types.MessageTypeStart || record.MessageType == types.MessageTypeData {
+			continue
+		}
+		err = r.processControlRecord(types.JSONLinesRecord{MessageType: record.MessageType, Errors: record.Errors, Statistics: record.Statistics})
+		if err != io.EOF {
+			r.consumedResponse = true
+			return err
+		}
+	}
+	return nil
+}
+
+// Result reads all the remaining result sets to the end, so that errors reported by the server
+// after the start of a result set aren't lost, and returns the statistics of every statement.
+// The rows are closed afterward.
 func (r *StreamRows) Result() (driver.Result, error) {
-	return &FireboltResult{}, nil
+	for r.resultSetPosition < len(r.responses) {
+		if err := r.drainResultSet(); err != nil {
+			return nil, errors.Join(err, r.Close())
+		}
+		if !r.HasNextResultSet() {
+			break
+		}
+		if err := r.NextResultSet(); err != nil {
+			return nil, errors.Join(err, r.Close())
+		}
+	}
+	result := &FireboltResult{statistics: r.statistics}
+	if err := r.Close(); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
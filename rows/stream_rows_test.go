@@ -129,6 +129,10 @@ func TestStreamRowsDecimalType(t *testing.T) {
 	testRowsDecimalType(t, mockStreamRowsSingleValue)
 }
 
+func TestStreamRowsTimestamps(t *testing.T) {
+	testRowsTimestamps(t, mockStreamRowsSingleValue)
+}
+
 func TestStreamRowsError(t *testing.T) {
 	rows := &StreamRows{}
 	responseFile := "fixtures/error.jsonl"
@@ -198,3 +202,29 @@ func TestStreamRowsCloseAttemptsEveryResponse(t *testing.T) {
 		t.Fatalf("Close() calls = %d, %d; want 1, 1", first.closes, second.closes)
 	}
 }
+
+func TestStreamRowsNextSetConsistency(t *testing.T) {
+	testRowsNextSetConsistency(t, mockStreamRows)
+}
+
+func TestStreamRowsStatistics(t *testing.T) {
+	testRowsStatistics(t, mockStreamRows)
+}
+
+func TestStreamRowsResultReturnsLateErrors(t *testing.T) {
+	rows := &StreamRows{}
+	for _, responseFile := range []string{"fixtures/result2.jsonl", "fixtures/error.jsonl"} {
+		resultJson, err := os.ReadFile(responseFile)
+		if err != nil {
+			log.Fatalf("Error reading file: %v", err)
+		}
+		must(rows.ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(bytes.NewReader(resultJson)), 200, nil, nil)))
+	}
+
+	_, err := rows.Result()
+	var structuredErr *errors.StructuredError
+	if !stderrors.As(err, &structuredErr) || !strings.Contains(structuredErr.Message, "my error") {
+		t.Fatalf("Result() error = %v, want the error of the second statement", err)
+	}
+	utils.AssertEqual(rows.HasNextResultSet(), false, t, "rows should be closed after Result")
+}
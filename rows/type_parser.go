@@ -57,7 +57,7 @@ func parsePrimitiveType(columnType string) (fireboltType, error) {
 		primitiveType = reflect.TypeOf("")
 		length = math.MaxInt64
 	case geographyType:
-		primitiveType = reflect.TypeOf("")
+		primitiveType = reflect.TypeOf(Geography(""))
 	case dateType, pgDateType, timestampType, timestampNtzType, timestampTzType:
 		primitiveType = reflect.TypeOf(time.Time{})
 	case byteaType:
@@ -88,7 +88,7 @@ func parseNullablePrimitiveType(columnType string) (fireboltType, error) {
 		primitiveType = reflect.TypeOf(sql.NullString{})
 		length = math.MaxInt64
 	case geographyType:
-		primitiveType = reflect.TypeOf(sql.NullString{})
+		primitiveType = reflect.TypeOf(NullGeography{})
 	case dateType, pgDateType, timestampType, timestampNtzType, timestampTzType:
 		primitiveType = reflect.TypeOf(sql.NullTime{})
 	case byteaType:
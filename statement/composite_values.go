@@ -0,0 +1,393 @@
+package statement
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/rows"
+	"github.com/shopspring/decimal"
+	"github.com/twpayne/go-geom"
+)
+
+// decimalPrecision is the precision of NUMERIC literals created from decimal parameters
+const decimalPrecision = 38
+
+// ParameterConverter converts query arguments into values that can be bound to prepared statements.
+// On top of the values supported by driver.DefaultParameterConverter it keeps slices and arrays
+// (bound as ARRAY), Go structs and maps with string keys (bound as STRUCT) and decimals (bound as NUMERIC).
+var ParameterConverter driver.ValueConverter = parameterConverter{}
+
+type parameterConverter struct{}
+
+func (parameterConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	if _, _, ok := asDecimal(v); ok {
+		return v, nil
+	}
+	if _, ok := v.(geom.T); ok {
+		// geometries are bound as GEOGRAPHY
+		g, valid, err := rows.ToGeography(v)
+		if err != nil || !valid {
+			return nil, err
+		}
+		return g, nil
+	}
+	if isGeography(v) {
+		return v, nil
+	}
+	if isCompositeValue(v) {
+		return v, nil
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := callValuer(valuer)
+		if err != nil {
+			return nil, err
+		}
+		if isCompositeValue(value) {
+			return value, nil
+		}
+		return driver.DefaultParameterConverter.ConvertValue(value)
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// CheckParameter converts a query argument with ParameterConverter, and validates that
+// every element of arrays and every field of structs can be bound as well
+func CheckParameter(v interface{}) (driver.Value, error) {
+	converted, err := ParameterConverter.ConvertValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if !isCompositeValue(converted) {
+		return converted, nil
+	}
+	rv := reflect.ValueOf(converted)
+	if isStructValue(rv) {
+		for _, field := range structValues(rv) {
+			if _, err = CheckParameter(field.value); err != nil {
+				return nil, fmt.Errorf("struct field %s: %w", field.name, err)
+			}
+		}
+		return converted, nil
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if _, err = CheckParameter(rv.Index(i).Interface()); err != nil {
+			return nil, fmt.Errorf("array element %d: %w", i, err)
+		}
+	}
+	return converted, nil
+}
+
+// callValuer returns the value of valuer, treating nil pointers as NULL like database/sql does
+func callValuer(valuer driver.Valuer) (driver.Value, error) {
+	if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	value, err := valuer.Value()
+	if err != nil {
+		return nil, fmt.Errorf("error getting value of %T: %w", valuer, err)
+	}
+	return value, nil
+}
+
+// isCompositeValue returns true for values bound as ARRAY or STRUCT
+func isCompositeValue(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	switch v.(type) {
+	case []byte, time.Time, driver.Valuer, geom.T:
+		return false
+	}
+	if _, _, ok := asDecimal(v); ok || isGeography(v) {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return true
+	case reflect.Map:
+		return rv.Type().Key().Kind() == reflect.String
+	case reflect.Struct:
+		// structs without exported fields aren't bound
+		return len(structValues(rv)) > 0
+	}
+	return false
+}
+
+// isStructValue returns true for the composite values bound as STRUCT
+func isStructValue(rv reflect.Value) bool {
+	return rv.Kind() == reflect.Map || rv.Kind() == reflect.Struct
+}
+
+// asDecimal extracts a decimal from the supported decimal types, valid is false for NULL decimals
+func asDecimal(v interface{}) (d decimal.Decimal, valid bool, ok bool) {
+	switch value := v.(type) {
+	case decimal.Decimal:
+		return value, true, true
+	case *decimal.Decimal:
+		if value == nil {
+			return decimal.Decimal{}, false, true
+		}
+		return *value, true, true
+	case decimal.NullDecimal:
+		return value.Decimal, value.Valid, true
+	case *decimal.NullDecimal:
+		if value == nil {
+			return decimal.Decimal{}, false, true
+		}
+		return value.Decimal, value.Valid, true
+	case rows.FireboltDecimal:
+		return value.Decimal, true, true
+	case *rows.FireboltDecimal:
+		if value == nil {
+			return decimal.Decimal{}, false, true
+		}
+		return value.Decimal, true, true
+	case rows.FireboltNullDecimal:
+		return value.Decimal, value.Valid, true
+	case *rows.FireboltNullDecimal:
+		if value == nil {
+			return decimal.Decimal{}, false, true
+		}
+		return value.Decimal, value.Valid, true
+	}
+	return decimal.Decimal{}, false, false
+}
+
+// isGeography returns true for the GEOGRAPHY value types
+func isGeography(v interface{}) bool {
+	switch v.(type) {
+	case rows.Geography, *rows.Geography, rows.NullGeography, *rows.NullGeography:
+		return true
+	}
+	return false
+}
+
+// formatGeography formats a GEOGRAPHY literal from its hex-encoded (E)WKB
+func formatGeography(g rows.Geography, isServerSide bool) string {
+	if isServerSide {
+		return string(g)
+	}
+	res := strings.ReplaceAll(string(g), "\\", "\\\\")
+	res = strings.ReplaceAll(res, "'", "\\'")
+	return "'" + res + "'::GEOGRAPHY"
+}
+
+// formatDecimal formats a decimal as a NUMERIC literal, keeping its scale
+func formatDecimal(d decimal.Decimal, isServerSide bool) string {
+	if isServerSide {
+		return d.String()
+	}
+	scale := int32(0)
+	if d.Exponent() < 0 {
+		scale = -d.Exponent()
+	}
+	return fmt.Sprintf("'%s'::NUMERIC(%d, %d)", d.StringFixed(scale), decimalPrecision, scale)
+}
+
+// formatComposite formats slices and arrays as ARRAY, and Go structs and maps as STRUCT values
+func formatComposite(v interface{}, opts formatOptions) (string, error) {
+	rv := reflect.ValueOf(v)
+	if isStructValue(rv) {
+		return formatStruct(rv, opts)
+	}
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return "NULL", nil
+	}
+	elementOpts := opts
+	elementOpts.targetType = arrayElementType(opts.targetType)
+	elements := make([]string, rv.Len())
+	for i := range elements {
+		var err error
+		if elements[i], err = formatElement(rv.Index(i).Interface(), elementOpts, serverSideArrayQuote); err != nil {
+			return "", fmt.Errorf("error formatting array element %d: %w", i, err)
+		}
+	}
+	if opts.isServerSide {
+		return "{" + strings.Join(elements, ",") + "}", nil
+	}
+	return "[" + strings.Join(elements, ", ") + "]", nil
+}
+
+// arrayElementType returns the element type of a normalized array type, or an empty string
+// if the type isn't an array type
+func arrayElementType(targetType string) string {
+	if strings.HasPrefix(targetType, "array(") && strings.HasSuffix(targetType, ")") {
+		return normalizeType(targetType[len("array(") : len(targetType)-1])
+	}
+	return ""
+}
+
+// formatStruct formats a Go struct or a map as a STRUCT value, with the fields in the order
+// and with the types of the STRUCT type of the parameter, see bindStructFields
+func formatStruct(rv reflect.Value, opts formatOptions) (string, error) {
+	if rv.Kind() == reflect.Map && rv.IsNil() {
+		return "NULL", nil
+	}
+	fieldValues, err := bindStructFields(rv, opts.targetType)
+	if err != nil {
+		return "", err
+	}
+	fields := make([]string, len(fieldValues))
+	for i, field := range fieldValues {
+		fieldOpts := opts
+		fieldOpts.targetType = field.fieldType
+		if fields[i], err = formatElement(field.value, fieldOpts, serverSideStructQuote); err != nil {
+			return "", fmt.Errorf("error formatting struct field %s: %w", field.name, err)
+		}
+	}
+	if opts.isServerSide {
+		return "(" + strings.Join(fields, ",") + ")", nil
+	}
+	return "ROW(" + strings.Join(fields, ", ") + ")", nil
+}
+
+// structField is a field of a value bound as STRUCT
+type structField struct {
+	name  string
+	value interface{}
+	// fieldType is the normalized type of the field, it is empty if the type isn't known
+	fieldType string
+}
+
+// structValues returns the fields of a Go struct in the order they are declared, or the entries of a map
+// in the order of their keys. Go struct fields are named with the firebolt tag, like rows.ScanStruct does.
+func structValues(rv reflect.Value) []structField {
+	var fields []structField
+	if rv.Kind() == reflect.Map {
+		keys := make([]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())).Interface()
+			fields = append(fields, structField{name: key, value: value})
+		}
+		return fields
+	}
+	for i := 0; i < rv.NumField(); i++ {
+		if name := rows.StructFieldName(rv.Type().Field(i)); name != "" {
+			fields = append(fields, structField{name: name, value: rv.Field(i).Interface()})
+		}
+	}
+	return fields
+}
+
+// bindStructFields matches the fields of a Go struct or a map to the STRUCT type of the parameter, and returns
+// them in the order of the type, with their types. Every field of the type needs a value, and every value
+// needs a field. When the type of the parameter isn't known, the fields of Go structs are bound in the order
+// they are declared, while maps are rejected since their fields can't be ordered.
+func bindStructFields(rv reflect.Value, targetType string) ([]structField, error) {
+	values := structValues(rv)
+	targetFields, err := rows.ParseStructFields(targetType)
+	if err != nil {
+		return nil, err
+	}
+	if targetFields == nil {
+		if rv.Kind() == reflect.Map {
+			return nil, fmt.Errorf("a map can only be bound to a parameter of a known STRUCT type, since its fields are unordered, bind a Go struct instead")
+		}
+		return values, nil
+	}
+
+	fields := make([]structField, len(targetFields))
+	bound := make([]bool, len(values))
+	for i, targetField := range targetFields {
+		j := findStructField(values, targetField.Name)
+		if j < 0 {
+			return nil, fmt.Errorf("no value is provided for struct field %s", targetField.Name)
+		}
+		bound[j] = true
+		fields[i] = structField{name: values[j].name, value: values[j].value, fieldType: normalizeType(targetField.Type)}
+	}
+	for j, isBound := range bound {
+		if !isBound {
+			return nil, fmt.Errorf("%s isn't a field of %s", values[j].name, targetType)
+		}
+	}
+	return fields, nil
+}
+
+// findStructField returns the index of the field with the name, falling back to a case-insensitive match
+// since the described types are lower case, or -1 if there is no such field
+func findStructField(fields []structField, name string) int {
+	for i, field := range fields {
+		if field.name == name {
+			return i
+		}
+	}
+	for i, field := range fields {
+		if strings.EqualFold(field.name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatElement formats an element of an ARRAY or a field of a STRUCT. Server-side values are
+// sent as text, so the elements use the text representation of arrays and structs: {a,b} and (a,b)
+func formatElement(v interface{}, opts formatOptions, quote func(text string, isNull bool, isArray bool) string) (string, error) {
+	converted, err := ParameterConverter.ConvertValue(v)
+	if err != nil {
+		return "", err
+	}
+	if !opts.isServerSide {
+		return internalFormatValue(converted, opts)
+	}
+	if str, ok := converted.(string); ok {
+		// elements are quoted below, so strings are taken as is
+		return quote(str, false, false), nil
+	}
+	text, err := internalFormatValue(converted, opts)
+	if err != nil {
+		return "", err
+	}
+	isArray := isCompositeValue(converted) && !isStructValue(reflect.ValueOf(converted))
+	return quote(text, isNullValue(converted), isArray), nil
+}
+
+func isNullValue(v driver.Value) bool {
+	if v == nil {
+		return true
+	}
+	if _, valid, ok := asDecimal(v); ok {
+		return !valid
+	}
+	if isGeography(v) {
+		_, valid, _ := rows.ToGeography(v)
+		return !valid
+	}
+	rv := reflect.ValueOf(v)
+	return (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.IsNil()
+}
+
+// quoteElementText double-quotes an element of the text representation, escaping quotes and backslashes
+func quoteElementText(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "\"", "\\\"")
+	return "\"" + text + "\""
+}
+
+// serverSideArrayQuote quotes array elements, nested arrays are not quoted
+func serverSideArrayQuote(text string, isNull bool, isArray bool) string {
+	if isNull {
+		return "NULL"
+	}
+	if isArray {
+		return text
+	}
+	return quoteElementText(text)
+}
+
+// serverSideStructQuote quotes struct fields, NULL is represented by an empty field
+func serverSideStructQuote(text string, isNull bool, _ bool) string {
+	if isNull {
+		return ""
+	}
+	return quoteElementText(text)
+}
@@ -0,0 +1,201 @@
+package statement
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/rows"
+	"github.com/shopspring/decimal"
+	"github.com/twpayne/go-geom"
+)
+
+type testValuer struct {
+	value driver.Value
+	err   error
+}
+
+func (v testValuer) Value() (driver.Value, error) {
+	return v.value, v.err
+}
+
+type namedInt int
+
+type testStruct struct {
+	B      string `firebolt:"b"`
+	A      []int
+	hidden int
+	Skip   int `firebolt:"-"`
+}
+
+func runTestFormatValueServerSide(t *testing.T, value driver.Value, expected *string) {
+	res, err := formatValueServerSide(value, formatOptions{})
+	if err != nil {
+		t.Errorf("formatValueServerSide shouldn't return an error, but it did: %v", err)
+		return
+	}
+	if (res == nil) != (expected == nil) || (res != nil && *res != *expected) {
+		t.Errorf("formatValueServerSide real and expected results are different: '%v' != '%v'", res, expected)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestFormatCompositeValue(t *testing.T) {
+	runTestFormatValue(t, []int64{1, 2, 3}, "[1, 2, 3]")
+	runTestFormatValue(t, []string{"a", "it's"}, "['a', 'it\\'s']")
+	runTestFormatValue(t, [][]int32{{1}, {}, nil}, "[[1], [], NULL]")
+	runTestFormatValue(t, []interface{}{1, nil, "a"}, "[1, NULL, 'a']")
+	runTestFormatValue(t, []*int{nil}, "[NULL]")
+	runTestFormatValue(t, []namedInt{1, 2}, "[1, 2]")
+	runTestFormatValue(t, [2]bool{true, false}, "[true, false]")
+	runTestFormatValue(t, []int64(nil), "NULL")
+	runTestFormatValue(t, []time.Time{time.Date(2022, 01, 10, 0, 0, 0, 0, time.UTC)}, "['2022-01-10 00:00:00']")
+
+	runTestFormatValue(t, decimal.RequireFromString("1.250"), "'1.250'::NUMERIC(38, 3)")
+	runTestFormatValue(t, decimal.RequireFromString("-12"), "'-12'::NUMERIC(38, 0)")
+	runTestFormatValue(t, &rows.FireboltDecimal{Decimal: decimal.RequireFromString("0.5")}, "'0.5'::NUMERIC(38, 1)")
+	runTestFormatValue(t, decimal.NullDecimal{}, "NULL")
+	runTestFormatValue(t, []decimal.Decimal{decimal.RequireFromString("1.5")}, "['1.5'::NUMERIC(38, 1)]")
+
+	runTestFormatValue(t, rows.Geography("0101000000000000000000F03F000000000000F03F"), "'0101000000000000000000F03F000000000000F03F'::GEOGRAPHY")
+	runTestFormatValue(t, rows.NullGeography{}, "NULL")
+	runTestFormatValue(t, geom.NewPointFlat(geom.XY, []float64{1, 1}), "'0101000000000000000000F03F000000000000F03F'::GEOGRAPHY")
+	runTestFormatValue(t, []rows.Geography{"0101000000000000000000F03F000000000000F03F"}, "['0101000000000000000000F03F000000000000F03F'::GEOGRAPHY]")
+
+	runTestFormatValue(t, testStruct{B: "x", A: []int{1}}, "ROW('x', [1])")
+	runTestFormatValue(t, []testStruct{{B: "x"}}, "[ROW('x', NULL)]")
+	runTestFormatValue(t, map[string]interface{}(nil), "NULL")
+
+	runTestFormatValue(t, testValuer{value: "valuer"}, "'valuer'")
+	runTestFormatValue(t, (*decimal.Decimal)(nil), "NULL")
+	runTestFormatValue(t, []driver.Valuer{testValuer{value: int64(1)}, testValuer{}}, "[1, NULL]")
+}
+
+func TestFormatCompositeValueServerSide(t *testing.T) {
+	runTestFormatValueServerSide(t, []int64{1, 2, 3}, strPtr("{\"1\",\"2\",\"3\"}"))
+	runTestFormatValueServerSide(t, []string{"a\"b", "c\\d", "NULL", "x,y"}, strPtr(`{"a\"b","c\\d","NULL","x,y"}`))
+	runTestFormatValueServerSide(t, [][]interface{}{{1, nil}, {}}, strPtr(`{{"1",NULL},{}}`))
+	runTestFormatValueServerSide(t, [][]byte{[]byte("ab")}, strPtr(`{"\\x6162"}`))
+	runTestFormatValueServerSide(t, []int64(nil), nil)
+
+	runTestFormatValueServerSide(t, decimal.RequireFromString("1.250"), strPtr("1.25"))
+	runTestFormatValueServerSide(t, decimal.NullDecimal{}, nil)
+
+	runTestFormatValueServerSide(t, rows.Geography("0101000000000000000000F03F000000000000F03F"), strPtr("0101000000000000000000F03F000000000000F03F"))
+	runTestFormatValueServerSide(t, &rows.NullGeography{}, nil)
+
+	runTestFormatValueServerSide(t, struct {
+		A int
+		B interface{}
+		C []string
+		D string
+	}{1, nil, []string{"x"}, "it's"}, strPtr(`("1",,"{\"x\"}","it's")`))
+	runTestFormatValueServerSide(t, testValuer{value: []int64{1}}, strPtr(`{"1"}`))
+}
+
+func TestFormatCompositeValueErrors(t *testing.T) {
+	for _, value := range []driver.Value{
+		[]struct{}{{}},
+		map[int]string{1: "a"},
+		testValuer{err: errors.New("valuer error")},
+		[]driver.Valuer{testValuer{err: errors.New("valuer error")}},
+		struct{}{},
+		map[string]interface{}{"a": 1},
+	} {
+		if _, err := formatValue(value, formatOptions{}); err == nil {
+			t.Errorf("formatValue should return an error for %v", value)
+		}
+		if _, err := formatValueServerSide(value, formatOptions{}); err == nil {
+			t.Errorf("formatValueServerSide should return an error for %v", value)
+		}
+	}
+}
+
+// TestFormatStructForTargetType checks that struct fields are bound in the order and with the types of the STRUCT type
+func TestFormatStructForTargetType(t *testing.T) {
+	targetType := normalizeType("struct(b text, a array(int), `C` date) null")
+	date := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	for _, value := range []interface{}{
+		map[string]interface{}{"a": []int{1}, "b": "x", "c": date},
+		struct {
+			A []int
+			C time.Time
+			B string
+		}{[]int{1}, date, "x"},
+	} {
+		res, err := formatValue(value, formatOptions{targetType: targetType})
+		if err != nil {
+			t.Errorf("formatValue returned an error for %v: %v", value, err)
+		}
+		if expected := "ROW('x', [1], '2022-01-10')"; res != expected {
+			t.Errorf("formatValue real and expected results are different: '%s' != '%s'", res, expected)
+		}
+		serverSide, err := formatValueServerSide(value, formatOptions{targetType: targetType})
+		if err != nil {
+			t.Errorf("formatValueServerSide returned an error for %v: %v", value, err)
+		}
+		if expected := `("x","{\"1\"}","2022-01-10")`; serverSide == nil || *serverSide != expected {
+			t.Errorf("formatValueServerSide real and expected results are different: '%v' != '%s'", serverSide, expected)
+		}
+	}
+
+	for _, value := range []interface{}{
+		map[string]interface{}{"a": []int{1}, "b": "x"},
+		map[string]interface{}{"a": []int{1}, "b": "x", "c": date, "d": 1},
+	} {
+		if _, err := formatValue(value, formatOptions{targetType: targetType}); err == nil {
+			t.Errorf("formatValue should return an error for %v", value)
+		}
+	}
+}
+
+func TestParameterConverter(t *testing.T) {
+	for _, tc := range []struct {
+		value    interface{}
+		expected string
+	}{
+		{[]int64{1}, "[]int64"},
+		{map[string]int{"a": 1}, "map[string]int"},
+		{testStruct{}, "statement.testStruct"},
+		{decimal.Zero, "decimal.Decimal"},
+		{namedInt(1), "int64"},
+		{testValuer{value: "a"}, "string"},
+		{testValuer{value: []string{"a"}}, "[]string"},
+		{[]byte("a"), "[]uint8"},
+		{rows.NullGeography{}, "rows.NullGeography"},
+		{geom.NewPointFlat(geom.XY, []float64{1, 1}), "rows.Geography"},
+	} {
+		converted, err := ParameterConverter.ConvertValue(tc.value)
+		if err != nil {
+			t.Errorf("ConvertValue(%v) returned an error: %v", tc.value, err)
+			continue
+		}
+		if actual := fmt.Sprintf("%T", converted); actual != tc.expected {
+			t.Errorf("ConvertValue(%v) returned %s, expected %s", tc.value, actual, tc.expected)
+		}
+	}
+	if _, err := ParameterConverter.ConvertValue(struct{}{}); err == nil {
+		t.Errorf("ConvertValue should return an error for an unsupported type")
+	}
+}
+
+func TestCheckParameter(t *testing.T) {
+	if _, err := CheckParameter([]interface{}{1, []interface{}{"a", decimal.Zero}, map[string]interface{}{"a": nil}}); err != nil {
+		t.Errorf("CheckParameter returned an error for a supported value: %v", err)
+	}
+	for _, value := range []interface{}{
+		[]interface{}{1, struct{}{}},
+		[][]interface{}{{make(chan int)}},
+		map[string]interface{}{"a": []interface{}{struct{}{}}},
+		map[int]int{1: 1},
+	} {
+		if _, err := CheckParameter(value); err == nil {
+			t.Errorf("CheckParameter should return an error for %v", value)
+		}
+	}
+}
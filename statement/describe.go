@@ -0,0 +1,212 @@
+package statement
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/errors"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
+)
+
+// ReadDescribeResult reads the result of a query executed with the describe context, and closes the rows
+func ReadDescribeResult(queryRows driver.Rows) (result *types.DescribeResult, err error) {
+	defer func() {
+		if closeErr := queryRows.Close(); closeErr != nil {
+			result = nil
+			err = stdErrors.Join(err, errors.ConstructNestedError("error closing describe rows", closeErr))
+		}
+	}()
+
+	// describe returns a single JSON column
+	dest := make([]driver.Value, 1)
+	if err = queryRows.Next(dest); err != nil {
+		return nil, errors.ConstructNestedError("error reading describe result", err)
+	}
+
+	jsonResult, ok := dest[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("describe result is not a string")
+	}
+
+	var describeResult types.DescribeResult
+	if err = json.Unmarshal([]byte(jsonResult), &describeResult); err != nil {
+		return nil, errors.ConstructNestedError("error unmarshalling describe result", err)
+	}
+	return &describeResult, nil
+}
+
+// maxDescribeCacheEntries bounds the number of queries a DescribeCache keeps the describe outcome of
+const maxDescribeCacheEntries = 256
+
+// DescribeCache keeps the outcome of describing queries executed once, so that a query is only described
+// on its first execution. Failed describes are kept as well, the query is then executed without the parameter
+// types. The zero value is ready to use.
+type DescribeCache struct {
+	mu       sync.Mutex
+	outcomes map[string]describeOutcome
+}
+
+type describeOutcome struct {
+	result *types.DescribeResult
+	err    error
+}
+
+func (c *DescribeCache) load(key string) (describeOutcome, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	outcome, ok := c.outcomes[key]
+	return outcome, ok
+}
+
+func (c *DescribeCache) store(key string, outcome describeOutcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.outcomes) >= maxDescribeCacheEntries {
+		// the queries of an application rarely change, start over rather than tracking their use
+		c.outcomes = nil
+	}
+	if c.outcomes == nil {
+		c.outcomes = make(map[string]describeOutcome)
+	}
+	c.outcomes[key] = outcome
+}
+
+// resultColumns returns the result columns of a describe result
+func resultColumns(result *types.DescribeResult) []types.Column {
+	columns := make([]types.Column, len(result.ResultColumns))
+	for i, column := range result.ResultColumns {
+		columns[i] = types.Column(column)
+	}
+	return columns
+}
+
+// validateParameters checks that the arguments of a server-side statement can be bound
+// to the parameter types reported by describe
+func validateParameters(parameterTypes map[string]string, args []driver.NamedValue) error {
+	for _, arg := range args {
+		name := fmt.Sprintf("$%d", arg.Ordinal)
+		parameterType, ok := parameterTypes[name]
+		if !ok {
+			continue
+		}
+		if err := checkParameterType(parameterType, arg.Value); err != nil {
+			return fmt.Errorf("invalid value for parameter %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkParameterType checks that the value can be bound to a parameter of the Firebolt type.
+// NULL can be bound to any type, and types without known Go counterparts aren't checked.
+func checkParameterType(parameterType string, value driver.Value) error {
+	value, err := ParameterConverter.ConvertValue(value)
+	if err != nil {
+		return err
+	}
+	if isNullValue(value) {
+		return nil
+	}
+	parameterType = normalizeType(parameterType)
+
+	if strings.HasPrefix(parameterType, "array(") && strings.HasSuffix(parameterType, ")") {
+		if !isCompositeValue(value) || isStructValue(reflect.ValueOf(value)) {
+			return fmt.Errorf("a value of type %T can't be bound to %s", value, parameterType)
+		}
+		elementType := parameterType[len("array(") : len(parameterType)-1]
+		rv := reflect.ValueOf(value)
+		for i := 0; i < rv.Len(); i++ {
+			if err = checkParameterType(elementType, rv.Index(i).Interface()); err != nil {
+				return fmt.Errorf("array element %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(parameterType, "struct(") && strings.HasSuffix(parameterType, ")") {
+		if !isCompositeValue(value) || !isStructValue(reflect.ValueOf(value)) {
+			return fmt.Errorf("a value of type %T can't be bound to %s", value, parameterType)
+		}
+		fields, err := bindStructFields(reflect.ValueOf(value), parameterType)
+		if err != nil {
+			return err
+		}
+		for _, field := range fields {
+			if err = checkParameterType(field.fieldType, field.value); err != nil {
+				return fmt.Errorf("struct field %s: %w", field.name, err)
+			}
+		}
+		return nil
+	}
+
+	var compatible bool
+	_, _, isDecimal := asDecimal(value)
+	switch kind := reflect.ValueOf(value).Kind(); {
+	case parameterType == "int" || parameterType == "integer" || parameterType == "long" || parameterType == "bigint":
+		compatible = isIntegerKind(kind)
+	case parameterType == "float" || parameterType == "real" || parameterType == "double" || parameterType == "double precision":
+		compatible = isIntegerKind(kind) || kind == reflect.Float32 || kind == reflect.Float64 || isDecimal
+	case strings.HasPrefix(parameterType, "numeric(") || strings.HasPrefix(parameterType, "decimal("):
+		compatible = isIntegerKind(kind) || kind == reflect.Float32 || kind == reflect.Float64 || kind == reflect.String || isDecimal
+	case parameterType == "text":
+		compatible = kind == reflect.String
+	case parameterType == "boolean":
+		compatible = kind == reflect.Bool
+	case parameterType == "bytea":
+		_, isBytes := value.([]byte)
+		compatible = isBytes || kind == reflect.String
+	case parameterType == "date" || parameterType == "pgdate" || strings.HasPrefix(parameterType, "timestamp"):
+		_, isTime := value.(time.Time)
+		compatible = isTime || kind == reflect.String
+	default:
+		compatible = true
+	}
+	if !compatible {
+		return fmt.Errorf("a value of type %T can't be bound to %s", value, parameterType)
+	}
+	return nil
+}
+
+// needsParameterType returns true for the values formatted differently depending on the parameter type:
+// time values, Go structs and maps, and arrays containing them
+func needsParameterType(value driver.Value) bool {
+	value, err := ParameterConverter.ConvertValue(value)
+	if err != nil {
+		return false
+	}
+	if _, isTime := value.(time.Time); isTime {
+		return true
+	}
+	if !isCompositeValue(value) {
+		return false
+	}
+	rv := reflect.ValueOf(value)
+	if isStructValue(rv) {
+		return true
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if needsParameterType(rv.Index(i).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeType returns the lower case type without the nullable suffix
+func normalizeType(parameterType string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(parameterType)), " null")
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+package statement
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/firebolt-db/firebolt-go-sdk/errors"
+)
+
+// ParsingError is returned when a query can't be parsed, it points to the position of the problem in the query
+type ParsingError struct {
+	Message string
+	// Offset is the byte offset of the problem in the query
+	Offset int
+	// Line and Column are 1-based, the column is counted in characters
+	Line   int
+	Column int
+}
+
+func (e *ParsingError) Error() string {
+	return fmt.Sprintf("%s at line %d, column %d", e.Message, e.Line, e.Column)
+}
+
+// newParsingError creates a ParsingError wrapped into errors.QueryParsingError
+func newParsingError(query string, offset int, message string) error {
+	line := strings.Count(query[:offset], "\n") + 1
+	lineStart := strings.LastIndexByte(query[:offset], '\n') + 1
+	column := utf8.RuneCountInString(query[lineStart:offset]) + 1
+	return errors.Wrap(errors.QueryParsingError, &ParsingError{Message: message, Offset: offset, Line: line, Column: column})
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuotedIdentifier
+	tokenString
+	tokenNumber
+	tokenPositionalPlaceholder
+	tokenNamedPlaceholder
+	tokenNumberedPlaceholder
+	tokenSemicolon
+	tokenOther
+)
+
+// token is a lexical unit of a query, start and end are byte offsets in the query
+type token struct {
+	kind  tokenKind
+	start int
+	end   int
+}
+
+// lexer splits a query into tokens following the Firebolt (PostgreSQL) lexical rules:
+// single-quoted strings where quotes are escaped by doubling them, E-prefixed strings with backslash escapes,
+// $tag$ dollar-quoted strings, "" quoted identifiers, -- line comments and nested /* */ comments.
+// Whitespace and comments are skipped.
+type lexer struct {
+	query string
+	pos   int
+}
+
+func isWordStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= utf8.RuneSelf
+}
+
+func isWordChar(c byte) bool {
+	return isWordStart(c) || c >= '0' && c <= '9' || c == '$'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (l *lexer) peekAt(offset int) byte {
+	if l.pos+offset >= len(l.query) {
+		return 0
+	}
+	return l.query[l.pos+offset]
+}
+
+func (l *lexer) errorAt(offset int, message string) error {
+	return newParsingError(l.query, offset, message)
+}
+
+// skipWhitespaceAndComments moves the position to the start of the next token
+func (l *lexer) skipWhitespaceAndComments() error {
+	for l.pos < len(l.query) {
+		switch c := l.query[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			l.pos++
+		case c == '-' && l.peekAt(1) == '-':
+			if end := strings.IndexByte(l.query[l.pos:], '\n'); end >= 0 {
+				l.pos += end + 1
+			} else {
+				l.pos = len(l.query)
+			}
+		case c == '/' && l.peekAt(1) == '*':
+			if err := l.skipBlockComment(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// skipBlockComment skips a block comment, block comments can be nested
+func (l *lexer) skipBlockComment() error {
+	start := l.pos
+	depth := 0
+	for l.pos < len(l.query) {
+		switch {
+		case l.query[l.pos] == '/' && l.peekAt(1) == '*':
+			depth++
+			l.pos += 2
+		case l.query[l.pos] == '*' && l.peekAt(1) == '/':
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return nil
+			}
+		default:
+			l.pos++
+		}
+	}
+	return l.errorAt(start, "unterminated block comment")
+}
+
+// readQuoted reads a string or an identifier enclosed in quote, where the quote is escaped by doubling it.
+// With backslashEscapes a backslash escapes the following character as well.
+func (l *lexer) readQuoted(start int, quote byte, backslashEscapes bool, what string) error {
+	for l.pos < len(l.query) {
+		c := l.query[l.pos]
+		switch {
+		case backslashEscapes && c == '\\':
+			l.pos += 2
+			continue
+		case c == quote && l.peekAt(1) == quote:
+			l.pos += 2
+			continue
+		case c == quote:
+			l.pos++
+			return nil
+		}
+		l.pos++
+	}
+	return l.errorAt(start, "unterminated "+what)
+}
+
+// dollarTag returns the $tag$ starting at the current position, or an empty string if there is none
+func (l *lexer) dollarTag() string {
+	end := l.pos + 1
+	if end < len(l.query) && isWordStart(l.query[end]) {
+		for end < len(l.query) && isWordChar(l.query[end]) && l.query[end] != '$' {
+			end++
+		}
+	}
+	if end < len(l.query) && l.query[end] == '$' {
+		return l.query[l.pos : end+1]
+	}
+	return ""
+}
+
+func (l *lexer) readWord() {
+	for l.pos < len(l.query) && isWordChar(l.query[l.pos]) {
+		l.pos++
+	}
+}
+
+func (l *lexer) readNumber() {
+	for l.pos < len(l.query) {
+		c := l.query[l.pos]
+		switch {
+		case isDigit(c) || c == '.' || c == '_':
+			l.pos++
+		case (c == 'e' || c == 'E') && (isDigit(l.peekAt(1)) || (l.peekAt(1) == '-' || l.peekAt(1) == '+') && isDigit(l.peekAt(2))):
+			l.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next token, ok is false at the end of the query
+func (l *lexer) next() (t token, ok bool, err error) {
+	if err = l.skipWhitespaceAndComments(); err != nil || l.pos >= len(l.query) {
+		return token{}, false, err
+	}
+	start := l.pos
+	kind := tokenOther
+	c := l.query[l.pos]
+	switch {
+	case c == '\'':
+		l.pos++
+		kind, err = tokenString, l.readQuoted(start, '\'', false, "string")
+	case (c == 'e' || c == 'E') && l.peekAt(1) == '\'':
+		l.pos += 2
+		kind, err = tokenString, l.readQuoted(start, '\'', true, "string")
+	case c == '"':
+		l.pos++
+		kind, err = tokenQuotedIdentifier, l.readQuoted(start, '"', false, "quoted identifier")
+	case c == '$' && l.dollarTag() != "":
+		tag := l.dollarTag()
+		end := strings.Index(l.query[l.pos+len(tag):], tag)
+		if end < 0 {
+			return token{}, false, l.errorAt(start, "unterminated dollar-quoted string")
+		}
+		l.pos += len(tag) + end + len(tag)
+		kind = tokenString
+	case c == '$' && isDigit(l.peekAt(1)):
+		// server-side $n placeholder
+		l.pos++
+		l.readNumber()
+		kind = tokenNumberedPlaceholder
+	case isWordStart(c):
+		l.readWord()
+		kind = tokenWord
+	case isDigit(c) || c == '.' && isDigit(l.peekAt(1)):
+		l.readNumber()
+		kind = tokenNumber
+	case c == ';':
+		l.pos++
+		kind = tokenSemicolon
+	case c == '?' && (l.peekAt(1) == '|' || l.peekAt(1) == '&'):
+		// ?| and ?& JSON operators
+		l.pos += 2
+	case c == '?':
+		l.pos++
+		kind = tokenPositionalPlaceholder
+	case c == ':' && l.peekAt(1) == ':':
+		// type cast
+		l.pos += 2
+	case c == '@' && l.peekAt(1) == '?':
+		// @? JSON path operator
+		l.pos += 2
+	case c == '@' && l.peekAt(1) == '@':
+		// @@variable
+		l.pos += 2
+		l.readWord()
+	case (c == ':' || c == '@') && isWordStart(l.peekAt(1)):
+		l.pos++
+		l.readWord()
+		kind = tokenNamedPlaceholder
+	default:
+		l.pos++
+	}
+	if err != nil {
+		return token{}, false, err
+	}
+	return token{kind: kind, start: start, end: l.pos}, true, nil
+}
+
+// parsedStatement is a single statement of a query
+type parsedStatement struct {
+	text string
+	// offset is the position of text in the query
+	offset int
+	isSet  bool
+	// placeholders are relative to text
+	placeholders []placeholder
+	// hasNumberedPlaceholders is true if the statement uses server-side $n placeholders
+	hasNumberedPlaceholders bool
+}
+
+// parseStatements splits a query into statements separated by semicolons, and finds the placeholders
+// in every statement. Statements that only contain whitespace and comments are skipped.
+func parseStatements(query string) ([]parsedStatement, error) {
+	var statements []parsedStatement
+	l := lexer{query: query}
+	segmentStart, numTokens, firstTokenStart := 0, 0, 0
+	var current parsedStatement
+
+	finishStatement := func(end int) {
+		if numTokens > 0 {
+			text := query[segmentStart:end]
+			if current.isSet {
+				// comments before SET are dropped, SET statements aren't sent to the server as is
+				text = query[firstTokenStart:end]
+				segmentStart = firstTokenStart
+			}
+			trimmed := strings.TrimLeft(text, " \t\r\n")
+			current.offset = segmentStart + len(text) - len(trimmed)
+			current.text = strings.TrimRight(trimmed, " \t\r\n")
+			for i := range current.placeholders {
+				current.placeholders[i].start -= current.offset
+				current.placeholders[i].end -= current.offset
+			}
+			statements = append(statements, current)
+		}
+		current = parsedStatement{}
+		numTokens = 0
+	}
+
+	for {
+		t, ok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			finishStatement(len(query))
+			break
+		}
+		switch t.kind {
+		case tokenSemicolon:
+			finishStatement(t.start)
+			segmentStart = t.end
+			continue
+		case tokenWord:
+			if numTokens == 0 {
+				firstTokenStart = t.start
+				current.isSet = strings.EqualFold(query[t.start:t.end], "SET")
+			}
+		case tokenPositionalPlaceholder:
+			current.placeholders = append(current.placeholders, placeholder{t.start, t.end, ""})
+		case tokenNamedPlaceholder:
+			current.placeholders = append(current.placeholders, placeholder{t.start, t.end, query[t.start+1 : t.end]})
+		case tokenNumberedPlaceholder:
+			current.hasNumberedPlaceholders = true
+		}
+		numTokens++
+	}
+	return statements, nil
+}
+
+// checkPlaceholders returns an error pointing to the first placeholder that doesn't match
+// the style of the first one, since positional and named placeholders can't be mixed
+func checkPlaceholders(query string, statement parsedStatement) error {
+	for _, p := range statement.placeholders {
+		if (p.name == "") != (statement.placeholders[0].name == "") {
+			return newParsingError(query, statement.offset+p.start, "mixing positional and named parameters in one query is not supported")
+		}
+	}
+	return nil
+}
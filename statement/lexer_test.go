@@ -0,0 +1,72 @@
+package statement
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
+	errorUtils "github.com/firebolt-db/firebolt-go-sdk/errors"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
+)
+
+func TestSplitStatementsLexicalRules(t *testing.T) {
+	runSplitStatement(t, "SELECT $$a; b$$; SELECT $fn$ ; $$ ; $fn$", []string{"SELECT $$a; b$$", "SELECT $fn$ ; $$ ; $fn$"})
+	runSplitStatement(t, `SELECT E'it\'s; ok'; SELECT 'C:\'; SELECT 'it''s; ok'`, []string{`SELECT E'it\'s; ok'`, `SELECT 'C:\'`, `SELECT 'it''s; ok'`})
+	runSplitStatement(t, "SELECT 1 /* outer /* inner; */ still; comment */; SELECT 2", []string{"SELECT 1 /* outer /* inner; */ still; comment */", "SELECT 2"})
+	runSplitStatement(t, `SELECT "a;""b" FROM t; SELECT 2`, []string{`SELECT "a;""b" FROM t`, "SELECT 2"})
+	runSplitStatement(t, "SELECT 1 -- comment; with a semicolon\n; SELECT 2", []string{"SELECT 1 -- comment; with a semicolon", "SELECT 2"})
+	runSplitStatement(t, "SELECT 1; -- trailing comment\n/* and another one */", []string{"SELECT 1"})
+	runSplitStatement(t, "-- only a comment", []string{""})
+}
+
+func TestPrepareStatementLexicalRules(t *testing.T) {
+	runPrepareStatementSuccess(t, "select $$?$$, E'\\'?', \"?\", ? from t -- ?", []driver.Value{1}, "select $$?$$, E'\\'?', \"?\", 1 from t -- ?")
+	runPrepareStatementSuccess(t, "select * from t where j ?| array['a'] and j ?& array['b'] and j @? '$.a' and id = ?", []driver.Value{1},
+		"select * from t where j ?| array['a'] and j ?& array['b'] and j @? '$.a' and id = 1")
+	runPrepareStatementSuccess(t, "select ?::int, x::text", []driver.Value{1}, "select 1::int, x::text")
+}
+
+func TestPrepareQuerySetDetection(t *testing.T) {
+	res, err := prepareQuery("SELECT * FROM SETTINGS_TABLE; SETTINGS_TABLE; /* comment */ set a = b", contextUtils.PreparedStatementsStyleNative)
+	if err != nil {
+		t.Fatalf("prepareQuery returned an error: %v", err)
+	}
+	utils.AssertEqual(len(res), 3, t, "number of statements doesn't match")
+	if _, ok := res[0].(*SingleStatement); !ok {
+		t.Errorf("select from SETTINGS_TABLE shouldn't be a SET statement")
+	}
+	if _, ok := res[1].(*SingleStatement); !ok {
+		t.Errorf("SETTINGS_TABLE shouldn't be a SET statement")
+	}
+	if set, ok := res[2].(*SetStatement); !ok || set.key != "a" || set.value != "b" {
+		t.Errorf("set statement after a comment wasn't detected: %v", res[2])
+	}
+}
+
+func runParsingError(t *testing.T, query string, expectedLine, expectedColumn int) {
+	_, err := prepareQuery(query, contextUtils.PreparedStatementsStyleNative)
+	if err == nil {
+		t.Errorf("prepareQuery should return an error for query '%s'", query)
+		return
+	}
+	if !errors.Is(err, errorUtils.QueryParsingError) {
+		t.Errorf("error should be a QueryParsingError, got %v", err)
+	}
+	var parsingError *ParsingError
+	if !errors.As(err, &parsingError) {
+		t.Errorf("error should contain a ParsingError, got %v", err)
+		return
+	}
+	utils.AssertEqual(parsingError.Line, expectedLine, t, "error line doesn't match for "+query)
+	utils.AssertEqual(parsingError.Column, expectedColumn, t, "error column doesn't match for "+query)
+}
+
+func TestParsingErrorPositions(t *testing.T) {
+	runParsingError(t, "SELECT 'abc", 1, 8)
+	runParsingError(t, "SELECT 1;\nSELECT 'é', E'abc\\'", 2, 13)
+	runParsingError(t, "SELECT \"abc", 1, 8)
+	runParsingError(t, "SELECT $tag$ abc $tag", 1, 8)
+	runParsingError(t, "SELECT 1\n  /* a /* b */", 2, 3)
+	runParsingError(t, "SELECT 1;\nSELECT :id,\n  ?", 3, 3)
+}
@@ -4,36 +4,73 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/firebolt-db/firebolt-go-sdk/client"
 	"github.com/firebolt-db/firebolt-go-sdk/context"
-	"github.com/firebolt-db/firebolt-go-sdk/errors"
-	"github.com/xwb1989/sqlparser"
+	"github.com/firebolt-db/firebolt-go-sdk/rows"
 )
 
 type PreparedQuery interface {
 	GetNumParams() int
-	Format(args []driver.NamedValue) (string, map[string]string, error)
+	// Format returns the query and the additional query parameters. Time values are formatted
+	// for the session time zone location, if it is not nil.
+	Format(args []driver.NamedValue, location *time.Location) (string, map[string]string, error)
 	OnSuccess(control client.ConnectionControl)
 }
 
+// placeholder is the position of a parameter placeholder in a query.
+// name is empty for positional ? placeholders, and holds the name of :name and @name placeholders
+type placeholder struct {
+	start int
+	end   int
+	name  string
+}
+
 type SingleStatement struct {
 	query           string
-	paramsPositions []int
+	paramsPositions []placeholder
 	parametersStyle context.PreparedStatementsStyle
+	// parameterTypes are the types of the $n parameters, once the statement is described. Placeholders
+	// of the native style are numbered the way describeStatement numbers them
+	parameterTypes map[string]string
 }
 
 func (s *SingleStatement) GetNumParams() int {
-	if s.parametersStyle == context.PreparedStatementsStyleFbNumeric {
-		// We don't know the number of parameters in the query
+	if s.parametersStyle == context.PreparedStatementsStyleFbNumeric || hasNamedPlaceholders(s.paramsPositions) {
+		// We don't know the number of parameters in the query,
+		// named parameters can be used several times
 		return -1
 	}
 	return len(s.paramsPositions)
 }
 
-func makeQueryParameters(args []driver.NamedValue) (map[string]string, error) {
+func hasNamedPlaceholders(placeholders []placeholder) bool {
+	return len(placeholders) > 0 && placeholders[0].name != ""
+}
+
+func hasNamedArgs(args []driver.NamedValue) bool {
+	for _, arg := range args {
+		if arg.Name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// usesNamedPlaceholders returns true if the named placeholders of the statement are bound to the arguments.
+// Server-side statements only use them with sql.Named arguments, otherwise :name is left as is,
+// e.g. in array slices like arr[1:n]
+func (s *SingleStatement) usesNamedPlaceholders(args []driver.NamedValue) bool {
+	if !hasNamedPlaceholders(s.paramsPositions) {
+		return false
+	}
+	return s.parametersStyle != context.PreparedStatementsStyleFbNumeric || hasNamedArgs(args)
+}
+
+func makeQueryParameters(args []driver.NamedValue, parameterTypes map[string]string, location *time.Location) (map[string]string, error) {
 	if len(args) == 0 {
 		return nil, nil
 	}
@@ -41,10 +78,10 @@ func makeQueryParameters(args []driver.NamedValue) (map[string]string, error) {
 	for i, arg := range args {
 		var key string
 		if arg.Name != "" {
-			return nil, fmt.Errorf("named parameters are not supported for server-side prepared statements: %s", arg.Name)
+			return nil, fmt.Errorf("named parameter '%s' is provided, but the query doesn't use named parameters", arg.Name)
 		}
 		key = fmt.Sprintf("$%d", arg.Ordinal)
-		value, err := formatValueServerSide(arg.Value)
+		value, err := formatValueServerSide(arg.Value, formatOptions{location: location, targetType: normalizeType(parameterTypes[key])})
 		if err != nil {
 			return nil, err
 		}
@@ -55,19 +92,43 @@ func makeQueryParameters(args []driver.NamedValue) (map[string]string, error) {
 	return map[string]string{"query_parameters": string(queryParametersJSON)}, err
 }
 
-func (s *SingleStatement) Format(args []driver.NamedValue) (string, map[string]string, error) {
+func (s *SingleStatement) Format(args []driver.NamedValue, location *time.Location) (string, map[string]string, error) {
 	if s.parametersStyle == context.PreparedStatementsStyleFbNumeric {
+		query := s.query
+		if s.usesNamedPlaceholders(args) {
+			var err error
+			if query, args, err = numberNamedParameters(query, s.paramsPositions, args); err != nil {
+				return "", nil, err
+			}
+		}
 		// Don't replace the parameters in the query, send them as `query_parameters`
-		queryParameters, err := makeQueryParameters(args)
-		return s.query, queryParameters, err
+		queryParameters, err := makeQueryParameters(args, s.parameterTypes, location)
+		return query, queryParameters, err
 	}
-	query, err := formatStatement(s.query, s.paramsPositions, args)
+	query, err := formatStatement(s.query, s.paramsPositions, args, location, s.parameterTypes)
 	return query, map[string]string{}, err
 
 }
 
 func (s *SingleStatement) OnSuccess(control client.ConnectionControl) {}
 
+// serverSideArgs returns the arguments the way they are sent to the server,
+// with named arguments numbered the same way as their placeholders
+func (s *SingleStatement) serverSideArgs(args []driver.NamedValue) ([]driver.NamedValue, error) {
+	if !s.usesNamedPlaceholders(args) {
+		return args, nil
+	}
+	_, numberedArgs, err := numberNamedParameters(s.query, s.paramsPositions, args)
+	return numberedArgs, err
+}
+
+// describeStatement returns the statement sent to describe the query. Positional and named placeholders
+// are numbered, since the server only knows $n parameters
+func (s *SingleStatement) describeStatement() *SingleStatement {
+	query, _ := numberPlaceholders(s.query, s.paramsPositions)
+	return &SingleStatement{query: query, parametersStyle: context.PreparedStatementsStyleFbNumeric}
+}
+
 type SetStatement struct {
 	key   string
 	value string
@@ -77,7 +138,7 @@ func (s *SetStatement) GetNumParams() int {
 	return -1
 }
 
-func (s *SetStatement) Format(args []driver.NamedValue) (string, map[string]string, error) {
+func (s *SetStatement) Format(args []driver.NamedValue, _ *time.Location) (string, map[string]string, error) {
 	if len(args) != 0 {
 		return "", map[string]string{}, fmt.Errorf("parameters are not supported in SET statements")
 	}
@@ -91,30 +152,41 @@ func (s *SetStatement) OnSuccess(control client.ConnectionControl) {
 
 // prepareQuery parses a query and returns a PreparedQuery object
 func prepareQuery(query string, style context.PreparedStatementsStyle) ([]PreparedQuery, error) {
-	queries, err := splitStatements(query)
+	statements, err := parseStatements(query)
 	if err != nil {
-		return nil, errors.ConstructNestedError("error during splitting query", err)
+		return nil, err
 	}
-	preparedQueries := make([]PreparedQuery, len(queries))
-	for i, singleQuery := range queries {
-		if strings.HasPrefix(strings.ToUpper(singleQuery), "SET") {
-			key, value, err := parseSetStatement(singleQuery)
+	if len(statements) == 0 {
+		// Parser stripped all the symbols and found no meaningful query
+		// Consider it as empty query
+		return []PreparedQuery{&SingleStatement{query: "", parametersStyle: style}}, nil
+	}
+	preparedQueries := make([]PreparedQuery, len(statements))
+	for i, statement := range statements {
+		if statement.isSet {
+			key, value, err := parseSetStatement(statement.text)
 			if err != nil {
 				return nil, err
 			}
 			preparedQueries[i] = &SetStatement{key, value}
-		} else {
-			var positions []int
-			if style == context.PreparedStatementsStyleNative {
-				positions, err = prepareStatement(singleQuery)
-				if err != nil {
-					return nil, err
+			continue
+		}
+		var positions []placeholder
+		if style == context.PreparedStatementsStyleNative {
+			if err = checkPlaceholders(query, statement); err != nil {
+				return nil, err
+			}
+			positions = statement.placeholders
+		} else if !statement.hasNumberedPlaceholders {
+			// Server-side statements use $n placeholders, named ones are numbered on execution with named arguments.
+			// They can't be mixed, so :name in a statement with $n placeholders is something else, e.g. an array slice
+			for _, p := range statement.placeholders {
+				if p.name != "" {
+					positions = append(positions, p)
 				}
-			} else {
-				positions = nil
 			}
-			preparedQueries[i] = &SingleStatement{singleQuery, positions, style}
 		}
+		preparedQueries[i] = &SingleStatement{query: statement.text, paramsPositions: positions, parametersStyle: style}
 	}
 
 	return preparedQueries, nil
@@ -124,7 +196,7 @@ func prepareQuery(query string, style context.PreparedStatementsStyle) ([]Prepar
 // or returns an error, if it isn't a set statement
 func parseSetStatement(query string) (string, string, error) {
 	query = strings.TrimSpace(query)
-	if strings.HasPrefix(strings.ToUpper(query), "SET") {
+	if strings.HasPrefix(strings.ToUpper(query), "SET") && (len(query) == len("SET") || !isWordChar(query[len("SET")])) {
 		query = strings.TrimSpace(query[len("SET"):])
 		values := strings.Split(query, "=")
 		if len(values) < 2 {
@@ -145,96 +217,242 @@ func parseSetStatement(query string) (string, string, error) {
 	return "", "", fmt.Errorf("not a set statement")
 }
 
-// prepareStatement parses a query and finds all the positions of the value arguments
-func prepareStatement(query string) ([]int, error) {
-	r := sqlparser.NewStringTokenizer(query)
-	var positions []int
-
-	for {
-		tokenId, _ := r.Scan()
+// prepareStatement parses a single statement and finds all the positions of the value arguments:
+// positional ? placeholders, or named :name and @name placeholders
+func prepareStatement(query string) ([]placeholder, error) {
+	statements, err := parseStatements(query)
+	if err != nil {
+		return []placeholder{}, err
+	}
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	if len(statements) > 1 {
+		return []placeholder{}, newParsingError(query, statements[1].offset, "expected a single statement")
+	}
+	if err = checkPlaceholders(query, statements[0]); err != nil {
+		return []placeholder{}, err
+	}
+	// positions are relative to the query, not to the trimmed statement
+	positions := statements[0].placeholders
+	for i := range positions {
+		positions[i].start += statements[0].offset
+		positions[i].end += statements[0].offset
+	}
+	return positions, nil
+}
 
-		if r.LastError != nil {
-			return []int{}, errors.ConstructNestedError("error during parsing query", r.LastError)
+// bindNamedParameters matches named placeholders to the values of named arguments
+func bindNamedParameters(placeholders []placeholder, params []driver.NamedValue) (map[string]driver.Value, error) {
+	values := make(map[string]driver.Value, len(params))
+	for _, param := range params {
+		if param.Name == "" {
+			return nil, fmt.Errorf("query uses named parameters, but positional argument %d is provided, use sql.Named to name it", param.Ordinal)
 		}
-
-		if tokenId == 0 {
-			break
+		values[param.Name] = param.Value
+	}
+	used := make(map[string]bool, len(values))
+	for _, p := range placeholders {
+		if _, ok := values[p.name]; !ok {
+			return nil, fmt.Errorf("no value is provided for parameter '%s'", p.name)
 		}
-
-		if tokenId == sqlparser.VALUE_ARG {
-			positions = append(positions, r.Position-1)
+		used[p.name] = true
+	}
+	for _, param := range params {
+		if !used[param.Name] {
+			return nil, fmt.Errorf("parameter '%s' is provided, but not used in the query", param.Name)
 		}
 	}
-
-	return positions, nil
+	return values, nil
 }
 
-// formatStatement replaces the value arguments in the query with the actual values
-func formatStatement(query string, positions []int, params []driver.NamedValue) (string, error) {
-	if len(positions) != len(params) {
-		return "", fmt.Errorf("found '%d' value args in query, but '%d' arguments are provided", len(positions), len(params))
+// formatStatement replaces the value arguments in the query with the actual values, formatted for the types
+// of the parameters if they are known
+func formatStatement(query string, positions []placeholder, params []driver.NamedValue, location *time.Location, parameterTypes map[string]string) (string, error) {
+	var namedValues map[string]driver.Value
+	if hasNamedPlaceholders(positions) {
+		var err error
+		if namedValues, err = bindNamedParameters(positions, params); err != nil {
+			return "", err
+		}
+	} else {
+		if len(positions) != len(params) {
+			return "", fmt.Errorf("found '%d' value args in query, but '%d' arguments are provided", len(positions), len(params))
+		}
+		for _, param := range params {
+			if param.Name != "" {
+				return "", fmt.Errorf("named parameter '%s' is provided, but the query uses positional parameters", param.Name)
+			}
+		}
 	}
 
+	numbers, _ := placeholderNumbers(positions)
 	for i := len(positions) - 1; i >= 0; i -= 1 {
-		res, err := formatValue(params[i].Value)
+		var value driver.Value
+		if namedValues != nil {
+			value = namedValues[positions[i].name]
+		} else {
+			value = params[i].Value
+		}
+		targetType := normalizeType(parameterTypes[fmt.Sprintf("$%d", numbers[i])])
+		res, err := formatValue(value, formatOptions{location: location, targetType: targetType})
 		if err != nil {
 			return "", err
 		}
-		query = query[:positions[i]-1] + res + query[positions[i]:]
+		query = query[:positions[i].start] + res + query[positions[i].end:]
 	}
 
 	return query, nil
 }
 
-// splitStatements split multiple statements into a list of statements
-func splitStatements(sql string) ([]string, error) {
-	var queries []string
+// numberNamedParameters replaces named placeholders with $n ones, numbering the names in the order
+// of their first appearance, and returns the arguments with the corresponding ordinals
+func numberNamedParameters(query string, positions []placeholder, params []driver.NamedValue) (string, []driver.NamedValue, error) {
+	namedValues, err := bindNamedParameters(positions, params)
+	if err != nil {
+		return "", nil, err
+	}
+	query, names := numberPlaceholders(query, positions)
+	numberedArgs := make([]driver.NamedValue, len(names))
+	for i, name := range names {
+		numberedArgs[i] = driver.NamedValue{Ordinal: i + 1, Value: namedValues[name]}
+	}
+	return query, numberedArgs, nil
+}
 
-	for sql != "" {
-		var err error
-		var query string
+// numberPlaceholders replaces placeholders with $n ones, and returns the names in the order of their numbers
+func numberPlaceholders(query string, positions []placeholder) (string, []string) {
+	numbers, names := placeholderNumbers(positions)
+	for i := len(positions) - 1; i >= 0; i -= 1 {
+		query = query[:positions[i].start] + fmt.Sprintf("$%d", numbers[i]) + query[positions[i].end:]
+	}
+	return query, names
+}
 
-		query, sql, err = sqlparser.SplitStatement(sql)
-		if err != nil {
-			return nil, errors.ConstructNestedError("error during splitting query", err)
-		}
-		if strings.Trim(query, " \t\n") == "" {
+// placeholderNumbers returns the $n number of every placeholder, and the names in the order of their numbers.
+// Positional placeholders are numbered in order, named ones in the order of the first appearance of their names.
+func placeholderNumbers(positions []placeholder) ([]int, []string) {
+	numbers := make([]int, len(positions))
+	ordinals := make(map[string]int)
+	var names []string
+	for i, p := range positions {
+		if p.name == "" {
+			names = append(names, "")
+			numbers[i] = len(names)
 			continue
 		}
-		queries = append(queries, strings.Trim(query, " \t\n"))
+		if _, ok := ordinals[p.name]; !ok {
+			names = append(names, p.name)
+			ordinals[p.name] = len(names)
+		}
+		numbers[i] = ordinals[p.name]
 	}
+	return numbers, names
+}
 
-	if len(queries) == 0 {
+// splitStatements split multiple statements into a list of statements
+func splitStatements(sql string) ([]string, error) {
+	statements, err := parseStatements(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(statements) == 0 {
 		// Parser stripped all the symbols and found no meaningfully query
 		// Consider it as empty query
 		return []string{""}, nil
 	}
-
+	queries := make([]string, len(statements))
+	for i, statement := range statements {
+		queries[i] = statement.text
+	}
 	return queries, nil
 }
 
-func formatValueServerSide(value driver.Value) (*string, error) {
+// formatOptions describe how a parameter value is formatted
+type formatOptions struct {
+	// isServerSide is true for values sent as query parameters, and false for literals in the query text
+	isServerSide bool
+	// location is the session time zone, TIMESTAMPTZ values keep their own location if it is nil
+	location *time.Location
+	// targetType is the normalized type of the parameter, it is empty if the type isn't known
+	targetType string
+}
+
+func formatValueServerSide(value driver.Value, opts formatOptions) (*string, error) {
 	// Server-side prepared statements don't support parameters
 	// So we need to convert all the values to strings
-	res, err := internalFormatValue(value, true)
+	opts.isServerSide = true
+	res, err := internalFormatValue(value, opts)
 	if res == "NULL" {
 		return nil, err
 	}
 	return &res, err
 }
 
-func formatValue(value driver.Value) (string, error) {
-	return internalFormatValue(value, false)
+func formatValue(value driver.Value, opts formatOptions) (string, error) {
+	opts.isServerSide = false
+	return internalFormatValue(value, opts)
+}
+
+const (
+	dateLayout = "2006-01-02"
+	// timestampLayout keeps the nanoseconds of the value, without trailing zeros
+	timestampLayout = "2006-01-02 15:04:05.999999999"
+)
+
+// formatTime formats a time value for the target type of the parameter. Dates and timestamps without
+// time zone take the wall clock of the value. TIMESTAMPTZ values are formatted in the session time zone,
+// always with the offset, so that they keep the same instant. When the target type isn't known, the value
+// is formatted as a timestamp, with the offset if it has one.
+func formatTime(t time.Time, opts formatOptions) string {
+	switch opts.targetType {
+	case "date", "pgdate":
+		return t.Format(dateLayout)
+	case "timestamp", "timestampntz":
+		return t.Format(timestampLayout)
+	case "timestamptz":
+		if opts.location != nil {
+			t = t.In(opts.location)
+		}
+		return t.Format(timestampLayout + offsetLayout(t))
+	}
+	if _, offset := t.Zone(); offset != 0 {
+		return t.Format(timestampLayout + offsetLayout(t))
+	}
+	return t.Format(timestampLayout)
 }
 
-func internalFormatValue(value driver.Value, isServerSide bool) (string, error) {
+// offsetLayout returns the layout of the offset of the value, seconds are only added if the offset has them
+func offsetLayout(t time.Time) string {
+	if _, offset := t.Zone(); offset%60 != 0 {
+		return "-07:00:00"
+	}
+	return "-07:00"
+}
+
+func internalFormatValue(value driver.Value, opts formatOptions) (string, error) {
 	quote := func(s string) string {
-		if isServerSide {
+		if opts.isServerSide {
 			return s
 		}
 		return "'" + s + "'"
 	}
 
+	if d, valid, ok := asDecimal(value); ok {
+		if !valid {
+			return "NULL", nil
+		}
+		return formatDecimal(d, opts.isServerSide), nil
+	}
+
+	if isGeography(value) {
+		g, valid, err := rows.ToGeography(value)
+		if err != nil || !valid {
+			return "NULL", err
+		}
+		return formatGeography(g, opts.isServerSide), nil
+	}
+
 	switch v := value.(type) {
 	case string:
 		res := value.(string)
@@ -252,21 +470,11 @@ func internalFormatValue(value driver.Value, isServerSide bool) (string, error)
 			return "false", nil
 		}
 	case time.Time:
-		timeValue := value.(time.Time)
-		layout := "2006-01-02 15:04:05.000000"
-		// Subtract date part from value and check if remaining time part is zero
-		// If it is, use date only format
-		if timeValue.Sub(timeValue.Truncate(time.Hour*24)) == 0 {
-			layout = "2006-01-02"
-		} else if _, offset := timeValue.Zone(); offset != 0 {
-			// If we have a timezone info, add it to format
-			layout = "2006-01-02 15:04:05.000000-07:00"
-		}
-		return quote(timeValue.Format(layout)), nil
+		return quote(formatTime(v, opts)), nil
 	case []byte:
 		byteValue := value.([]byte)
 		parts := make([]string, len(byteValue))
-		if isServerSide {
+		if opts.isServerSide {
 			for i, b := range byteValue {
 				parts[i] = fmt.Sprintf("%02x", b)
 			}
@@ -280,7 +488,15 @@ func internalFormatValue(value driver.Value, isServerSide bool) (string, error)
 	case nil:
 		return "NULL", nil
 	default:
-		return "", fmt.Errorf("not supported type: %v", v)
+		if isCompositeValue(value) {
+			return formatComposite(value, opts)
+		}
+		// Valuers, pointers and named types of the supported kinds are converted to the supported types
+		converted, err := ParameterConverter.ConvertValue(value)
+		if err != nil || reflect.TypeOf(converted) == reflect.TypeOf(value) {
+			return "", fmt.Errorf("not supported type: %v", v)
+		}
+		return internalFormatValue(converted, opts)
 	}
 }
 
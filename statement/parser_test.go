@@ -2,12 +2,14 @@ package statement
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
+	"github.com/firebolt-db/firebolt-go-sdk/utils"
 )
 
 func runParseSetStatementSuccess(t *testing.T, value, expectedKey, expectedValue string) {
@@ -59,7 +61,7 @@ func runPrepareStatementSuccess(t *testing.T, query string, params []driver.Valu
 	if err != nil {
 		t.Errorf("function returned an error, but it shouldn't: %v", err)
 	}
-	res, err := formatStatement(query, positions, namedParams)
+	res, err := formatStatement(query, positions, namedParams, nil, nil)
 	if err != nil {
 		t.Errorf("function returned an error, but it shouldn't: %v", err)
 	}
@@ -75,7 +77,7 @@ func runPrepareStatementFail(t *testing.T, query string, params []driver.Value)
 	}
 
 	positions, err1 := prepareStatement(query)
-	_, err2 := formatStatement(query, positions, namedParams)
+	_, err2 := formatStatement(query, positions, namedParams, nil, nil)
 	if err1 == nil && err2 == nil {
 		t.Errorf("function didn't return an error, but it should")
 	}
@@ -94,8 +96,86 @@ func TestPrepareStatement(t *testing.T) {
 	runPrepareStatementFail(t, "?", []driver.Value{1, 2})
 }
 
+func runNamedStatement(query string, style contextUtils.PreparedStatementsStyle, args []driver.NamedValue) (string, map[string]string, error) {
+	queries, err := prepareQuery(query, style)
+	if err != nil {
+		return "", nil, err
+	}
+	return queries[0].Format(args, nil)
+}
+
+func TestNamedParameters(t *testing.T) {
+	args := []driver.NamedValue{{Name: "id", Ordinal: 1, Value: 1}, {Name: "name", Ordinal: 2, Value: "it's"}}
+
+	res, _, err := runNamedStatement("select * from t where id = :id and name = @name or parent = :id", contextUtils.PreparedStatementsStyleNative, args)
+	if err != nil {
+		t.Fatalf("formatting named parameters failed: %v", err)
+	}
+	utils.AssertEqual(res, "select * from t where id = 1 and name = 'it\\'s' or parent = 1", t, "formatted query doesn't match")
+
+	res, _, err = runNamedStatement("select ':id', x::int, @@version, \"@name\" from t where id = :id", contextUtils.PreparedStatementsStyleNative, args[:1])
+	if err != nil {
+		t.Fatalf("formatting named parameters failed: %v", err)
+	}
+	utils.AssertEqual(res, "select ':id', x::int, @@version, \"@name\" from t where id = 1", t, "only placeholders should be replaced")
+
+	res, params, err := runNamedStatement("select * from t where name = @name and id = :id or parent = :id", contextUtils.PreparedStatementsStyleFbNumeric, args)
+	if err != nil {
+		t.Fatalf("numbering named parameters failed: %v", err)
+	}
+	utils.AssertEqual(res, "select * from t where name = $1 and id = $2 or parent = $2", t, "numbered query doesn't match")
+	utils.AssertEqual(params["query_parameters"], `[{"name":"$1","value":"it\\'s"},{"name":"$2","value":"1"}]`, t, "query parameters don't match")
+
+	res, params, err = runNamedStatement("select $1, '?'", contextUtils.PreparedStatementsStyleFbNumeric, []driver.NamedValue{{Ordinal: 1, Value: 1}})
+	if err != nil {
+		t.Fatalf("server-side positional parameters failed: %v", err)
+	}
+	utils.AssertEqual(res, "select $1, '?'", t, "query without named parameters shouldn't change")
+	utils.AssertEqual(params["query_parameters"], `[{"name":"$1","value":"1"}]`, t, "query parameters don't match")
+}
+
+func TestNamedParametersErrors(t *testing.T) {
+	native, fbNumeric := contextUtils.PreparedStatementsStyleNative, contextUtils.PreparedStatementsStyleFbNumeric
+	for _, tc := range []struct {
+		query string
+		style contextUtils.PreparedStatementsStyle
+		args  []driver.NamedValue
+	}{
+		{"select :id, ?", native, nil},
+		{"select :id", native, []driver.NamedValue{{Ordinal: 1, Value: 1}}},
+		{"select :id", native, []driver.NamedValue{{Name: "other", Ordinal: 1, Value: 1}}},
+		{"select :id", native, []driver.NamedValue{{Name: "id", Ordinal: 1, Value: 1}, {Name: "unused", Ordinal: 2, Value: 1}}},
+		{"select ?", native, []driver.NamedValue{{Name: "id", Ordinal: 1, Value: 1}}},
+		{"select :id", fbNumeric, []driver.NamedValue{{Name: "other", Ordinal: 1, Value: 1}}},
+		{"select $1", fbNumeric, []driver.NamedValue{{Name: "id", Ordinal: 1, Value: 1}}},
+	} {
+		if _, _, err := runNamedStatement(tc.query, tc.style, tc.args); err == nil {
+			t.Errorf("expected an error for query '%s' with args %v", tc.query, tc.args)
+		}
+	}
+}
+
+// TestServerSideArraySlices checks that :name in array slices isn't taken for a named placeholder in server-side statements
+func TestServerSideArraySlices(t *testing.T) {
+	fbNumeric := contextUtils.PreparedStatementsStyleFbNumeric
+	res, params, err := runNamedStatement("select arr[1:n] from t where id = $1", fbNumeric, []driver.NamedValue{{Ordinal: 1, Value: 1}})
+	if err != nil {
+		t.Fatalf("formatting a query with an array slice failed: %v", err)
+	}
+	utils.AssertEqual(res, "select arr[1:n] from t where id = $1", t, "array slice shouldn't be replaced")
+	utils.AssertEqual(params["query_parameters"], `[{"name":"$1","value":"1"}]`, t, "query parameters don't match")
+
+	res, params, err = runNamedStatement("select arr[1:n] from t", fbNumeric, nil)
+	if err != nil {
+		t.Fatalf("formatting a query with an array slice failed: %v", err)
+	}
+	utils.AssertEqual(res, "select arr[1:n] from t", t, "array slice shouldn't be replaced without named arguments")
+	utils.AssertEqual(len(params), 0, t, "query parameters should be empty")
+
+}
+
 func runTestFormatValue(t *testing.T, value driver.Value, expected string) {
-	res, err := formatValue(value)
+	res, err := formatValue(value, formatOptions{})
 	if err != nil {
 		t.Errorf("formatValue shouldn't return an error, but it did: %v", err)
 	}
@@ -125,10 +205,52 @@ func TestFormatValue(t *testing.T) {
 	// Time
 	runTestFormatValue(t, time.Date(2022, 01, 10, 1, 3, 2, 123000, time.UTC), "'2022-01-10 01:03:02.000123'")
 	runTestFormatValue(t, time.Date(2022, 01, 10, 1, 3, 2, 123000, time.FixedZone("", 0)), "'2022-01-10 01:03:02.000123'")
-	runTestFormatValue(t, time.Date(2022, 01, 10, 0, 0, 0, 0, time.UTC), "'2022-01-10'")
-	runTestFormatValue(t, time.Date(2022, 01, 10, 0, 0, 0, 0, loc), "'2022-01-10 00:00:00.000000+01:00'")
+	runTestFormatValue(t, time.Date(2022, 01, 10, 0, 0, 0, 0, time.UTC), "'2022-01-10 00:00:00'")
+	runTestFormatValue(t, time.Date(2022, 01, 10, 1, 3, 2, 123456789, time.UTC), "'2022-01-10 01:03:02.123456789'")
+	runTestFormatValue(t, time.Date(2022, 01, 10, 0, 0, 0, 0, loc), "'2022-01-10 00:00:00+01:00'")
 	runTestFormatValue(t, time.Date(2022, 01, 10, 1, 3, 2, 123000, loc), "'2022-01-10 01:03:02.000123+01:00'")
+	runTestFormatValue(t, time.Date(2022, 01, 10, 1, 3, 2, 100000000, time.UTC), "'2022-01-10 01:03:02.1'")
+	runTestFormatValue(t, time.Date(2022, 01, 10, 1, 3, 2, 0, time.FixedZone("", 5*60*60+30*60+15)), "'2022-01-10 01:03:02+05:30:15'")
+
+}
+
+func TestFormatTimeForTargetType(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	kolkata := time.FixedZone("IST", 5*60*60+30*60)
+	midnight := time.Date(2022, 01, 10, 0, 0, 0, 0, time.UTC)
+	value := time.Date(2022, 01, 10, 1, 3, 2, 123456789, kolkata)
+
+	for _, tc := range []struct {
+		value      time.Time
+		targetType string
+		location   *time.Location
+		expected   string
+	}{
+		{midnight, "date", nil, "2022-01-10"},
+		{midnight, "timestamp", nil, "2022-01-10 00:00:00"},
+		{midnight, "timestampntz", berlin, "2022-01-10 00:00:00"},
+		{midnight, "timestamptz", nil, "2022-01-10 00:00:00+00:00"},
+		{midnight, "timestamptz", berlin, "2022-01-10 01:00:00+01:00"},
+		{value, "date", berlin, "2022-01-10"},
+		{value, "timestamp", berlin, "2022-01-10 01:03:02.123456789"},
+		{value, "timestamptz", nil, "2022-01-10 01:03:02.123456789+05:30"},
+		{value, "timestamptz", time.UTC, "2022-01-09 19:33:02.123456789+00:00"},
+	} {
+		res, err := formatValue(tc.value, formatOptions{targetType: tc.targetType, location: tc.location})
+		utils.AssertEqual(err, nil, t, "formatValue returned an error")
+		utils.AssertEqual(res, "'"+tc.expected+"'", t, fmt.Sprintf("%s value formatted for %s doesn't match", tc.value, tc.targetType))
+
+		serverSide, err := formatValueServerSide(tc.value, formatOptions{targetType: tc.targetType, location: tc.location})
+		utils.AssertEqual(err, nil, t, "formatValueServerSide returned an error")
+		utils.AssertEqual(*serverSide, tc.expected, t, fmt.Sprintf("%s value formatted server-side for %s doesn't match", tc.value, tc.targetType))
+	}
 
+	res, err := formatValue([]time.Time{midnight}, formatOptions{targetType: "array(timestamptz null)", location: berlin})
+	utils.AssertEqual(err, nil, t, "formatValue returned an error")
+	utils.AssertEqual(res, "['2022-01-10 01:00:00+01:00']", t, "array elements should be formatted for the element type")
 }
 
 func runSplitStatement(t *testing.T, value string, expected []string) {
@@ -236,7 +358,7 @@ func TestPrepareQuery(t *testing.T) {
 	runPrepareQuerySuccess(t, "SELECT ?, ?", contextUtils.PreparedStatementsStyleNative,
 		[]PreparedQuery{&SingleStatement{
 			query:           "SELECT ?, ?",
-			paramsPositions: []int{8, 11},
+			paramsPositions: []placeholder{{7, 8, ""}, {10, 11, ""}},
 			parametersStyle: contextUtils.PreparedStatementsStyleNative,
 		}})
 	runPrepareQuerySuccess(t, "SET timezone=America/New_York", contextUtils.PreparedStatementsStyleNative,
@@ -3,8 +3,12 @@ package statement
 import (
 	"context"
 	"database/sql/driver"
+	"fmt"
 
+	"github.com/firebolt-db/firebolt-go-sdk/errors"
+	"github.com/firebolt-db/firebolt-go-sdk/logging"
 	"github.com/firebolt-db/firebolt-go-sdk/rows"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
 
 	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
 )
@@ -16,6 +20,12 @@ type Executor interface {
 type fireboltStmt struct {
 	Queries  []PreparedQuery
 	executor Executor
+	style    contextUtils.PreparedStatementsStyle
+	// describeResult is cached on the first Describe call or execution, and used to validate the arguments
+	describeResult *types.DescribeResult
+	// describeErr is the error of a failed describe, returned by the next Describe calls instead of describing
+	// the statement again
+	describeErr error
 }
 
 func MakeStmt(executor Executor, query string, style contextUtils.PreparedStatementsStyle) (*fireboltStmt, error) {
@@ -27,6 +37,7 @@ func MakeStmt(executor Executor, query string, style contextUtils.PreparedStatem
 	return &fireboltStmt{
 		Queries:  preparedQueries,
 		executor: executor,
+		style:    style,
 	}, nil
 }
 
@@ -34,6 +45,7 @@ func MakeStmt(executor Executor, query string, style contextUtils.PreparedStatem
 func (stmt *fireboltStmt) Close() error {
 	stmt.executor = nil
 	stmt.Queries = nil
+	stmt.describeResult = nil
 	return nil
 }
 
@@ -57,14 +69,166 @@ func (stmt *fireboltStmt) Query(args []driver.Value) (driver.Rows, error) {
 
 // QueryContext sends the query to the engine and returns fireboltRows
 func (stmt *fireboltStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	stmt.describeOnFirstExecution(ctx, args)
+	if err := stmt.validateArgs(args); err != nil {
+		return nil, err
+	}
 	return stmt.executor.ExecutePreparedQueries(ctx, stmt.Queries, args, true)
 }
 
-// ExecContext sends the query to the engine and returns empty fireboltResult
+// ExecContext sends the query to the engine and returns fireboltResult with the statistics of every statement
 func (stmt *fireboltStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
-	_, err := stmt.executor.ExecutePreparedQueries(ctx, stmt.Queries, args, true)
+	stmt.describeOnFirstExecution(ctx, args)
+	if err := stmt.validateArgs(args); err != nil {
+		return nil, err
+	}
+	rs, err := stmt.executor.ExecutePreparedQueries(ctx, stmt.Queries, args, true)
+	if err != nil {
+		return nil, err
+	}
+	return rs.Result()
+}
+
+// Describe returns the parameter types and the result columns of the statement. The statement is described
+// on the server on the first call only, afterward the cached result is returned, and the arguments of every
+// execution are validated against the described parameter types before the query is sent.
+// Only single statements can be described.
+func (stmt *fireboltStmt) Describe(ctx context.Context) (*types.DescribeResult, error) {
+	if stmt.describeResult != nil {
+		return stmt.describeResult, nil
+	}
+	if stmt.describeErr != nil {
+		return nil, stmt.describeErr
+	}
+	query, err := stmt.describableStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := stmt.describe(ctx, query)
+	if err != nil {
+		// a cancelled context fails the execution as well, the statement is described on the next call
+		if ctx.Err() == nil {
+			stmt.describeErr = err
+		}
+		return nil, err
+	}
+	stmt.setDescribeResult(query, result)
+	return result, nil
+}
+
+// setDescribeResult caches the describe result on the statement
+func (stmt *fireboltStmt) setDescribeResult(query *SingleStatement, result *types.DescribeResult) {
+	stmt.describeResult = result
+	// time and struct values are formatted for the described parameter types
+	query.parameterTypes = result.ParameterTypes
+}
+
+// describe runs the describe query of the statement on the server
+func (stmt *fireboltStmt) describe(ctx context.Context, query *SingleStatement) (*types.DescribeResult, error) {
+	queryRows, err := stmt.executor.ExecutePreparedQueries(contextUtils.WithDescribe(ctx), []PreparedQuery{query.describeStatement()}, nil, false)
+	if err != nil {
+		return nil, errors.ConstructNestedError("error executing describe query", err)
+	}
+	return ReadDescribeResult(queryRows)
+}
+
+// describableStatement returns the statement to describe, or an error if the statement can't be described
+func (stmt *fireboltStmt) describableStatement() (*SingleStatement, error) {
+	if len(stmt.Queries) != 1 {
+		return nil, fmt.Errorf("only single statements can be described, but the query has %d statements", len(stmt.Queries))
+	}
+	query, ok := stmt.Queries[0].(*SingleStatement)
+	if !ok {
+		return nil, fmt.Errorf("SET statements can't be described")
+	}
+	return query, nil
+}
+
+// describeOnFirstExecution describes a server-side prepared statement that takes arguments on its first execution,
+// so that the arguments are validated and formatted for the parameter types. Statements that can't be described
+// are executed without the parameter types, and the server reports the errors of invalid arguments.
+func (stmt *fireboltStmt) describeOnFirstExecution(ctx context.Context, args []driver.NamedValue) {
+	if len(args) > 0 && stmt.style == contextUtils.PreparedStatementsStyleFbNumeric {
+		stmt.tryDescribe(ctx, args)
+	}
+}
+
+// ResolveParameterTypes describes a query executed once when the formatting of its arguments depends on the
+// types of the parameters, i.e. for time values, Go structs and maps. Other queries are sent without describing them.
+// If cache isn't nil, the outcome of the describe is kept in it for the queries of the same scope, e.g. the engine
+// and the database the query runs on, and reused instead of describing the query again.
+func (stmt *fireboltStmt) ResolveParameterTypes(ctx context.Context, args []driver.NamedValue, cache *DescribeCache, scope string) {
+	if !anyNeedsParameterType(args) {
+		return
+	}
+	query, err := stmt.describableStatement()
+	if cache == nil || err != nil {
+		stmt.tryDescribe(ctx, args)
+		return
+	}
+	key := scope + "\x00" + query.describeStatement().query
+	if outcome, ok := cache.load(key); ok {
+		if outcome.result != nil {
+			stmt.setDescribeResult(query, outcome.result)
+		}
+		stmt.describeErr = outcome.err
+		return
+	}
+	stmt.tryDescribe(ctx, args)
+	if stmt.describeResult != nil || stmt.describeErr != nil {
+		cache.store(key, describeOutcome{result: stmt.describeResult, err: stmt.describeErr})
+	}
+}
+
+func anyNeedsParameterType(args []driver.NamedValue) bool {
+	for _, arg := range args {
+		if needsParameterType(arg.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// tryDescribe describes the statement if it can be described and wasn't yet. Named placeholders are numbered
+// in the describe query, so the statement isn't described if the arguments don't use them.
+func (stmt *fireboltStmt) tryDescribe(ctx context.Context, args []driver.NamedValue) {
+	if stmt.describeResult != nil || stmt.describeErr != nil {
+		return
+	}
+	query, err := stmt.describableStatement()
+	if err != nil || hasNamedPlaceholders(query.paramsPositions) && !query.usesNamedPlaceholders(args) {
+		return
+	}
+	if _, err := stmt.Describe(ctx); err != nil {
+		logging.Infolog.Printf("statement is executed without parameter types, since it couldn't be described: %v", err)
+	}
+}
+
+// ColumnTypes returns the types of the result columns of the statement before it is executed,
+// the statement is described on the first call. The error of the describe is returned if the statement
+// couldn't be described, including on its first execution.
+func (stmt *fireboltStmt) ColumnTypes(ctx context.Context) ([]rows.ColumnType, error) {
+	result, err := stmt.Describe(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return rows.FireboltResult{}, nil
+	columnTypes, err := rows.MakeColumnTypes(resultColumns(result))
+	if err != nil {
+		return nil, errors.ConstructNestedError("error parsing described column types", err)
+	}
+	return columnTypes, nil
+}
+
+// validateArgs checks the arguments against the parameter types of a described statement
+func (stmt *fireboltStmt) validateArgs(args []driver.NamedValue) error {
+	if stmt.describeResult == nil || len(stmt.describeResult.ParameterTypes) == 0 {
+		return nil
+	}
+	serverSideArgs, err := stmt.Queries[0].(*SingleStatement).serverSideArgs(args)
+	if err != nil {
+		// binding errors are reported when the query is formatted
+		return nil
+	}
+	return validateParameters(stmt.describeResult.ParameterTypes, serverSideArgs)
 }
@@ -1,11 +1,19 @@
 package statement
 
 import (
+	"bytes"
 	"context"
 	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/firebolt-db/firebolt-go-sdk/client"
 	"github.com/firebolt-db/firebolt-go-sdk/rows"
+	"github.com/firebolt-db/firebolt-go-sdk/types"
 
 	contextUtils "github.com/firebolt-db/firebolt-go-sdk/context"
 	"github.com/firebolt-db/firebolt-go-sdk/utils"
@@ -19,7 +27,7 @@ type driverExecerMock struct {
 func (c *driverExecerMock) ExecutePreparedQueries(ctx context.Context, queries []PreparedQuery, args []driver.NamedValue, isQuery bool) (rows.ExtendableRowsWithResult, error) {
 	c.callCount += 1
 	c.lastQuery = queries
-	return nil, nil
+	return &rows.InMemoryRows{}, nil
 }
 
 // TestExecStmt tests that Exec and ExecContext actually calls execer
@@ -36,7 +44,7 @@ func TestQueryStmt(t *testing.T) {
 
 	utils.AssertEqual(err, nil, t, "executor returned an error, but shouldn't")
 	utils.AssertEqual(executor.callCount, 1, t, "executor wasn't called")
-	lastQuery, _, err := executor.lastQuery[0].Format([]driver.NamedValue{})
+	lastQuery, _, err := executor.lastQuery[0].Format([]driver.NamedValue{}, nil)
 	if err != nil {
 		t.Fatalf("Failed to format lastQuery: %v", err)
 	}
@@ -46,7 +54,7 @@ func TestQueryStmt(t *testing.T) {
 	utils.AssertEqual(err, nil, t, "executor returned an error, but shouldn't")
 
 	utils.AssertEqual(executor.callCount, 2, t, "executor wasn't called")
-	lastQuery, _, err = executor.lastQuery[0].Format([]driver.NamedValue{})
+	lastQuery, _, err = executor.lastQuery[0].Format([]driver.NamedValue{}, nil)
 	if err != nil {
 		t.Fatalf("Failed to format lastQuery: %v", err)
 	}
@@ -67,7 +75,7 @@ func TestExecStmt(t *testing.T) {
 
 	utils.AssertEqual(err, nil, t, "executor returned an error, but shouldn't")
 	utils.AssertEqual(executor.callCount, 1, t, "executor wasn't called")
-	lastQuery, _, err := executor.lastQuery[0].Format([]driver.NamedValue{})
+	lastQuery, _, err := executor.lastQuery[0].Format([]driver.NamedValue{}, nil)
 	if err != nil {
 		t.Fatalf("Failed to format lastQuery: %v", err)
 	}
@@ -77,7 +85,7 @@ func TestExecStmt(t *testing.T) {
 	utils.AssertEqual(err, nil, t, "executor returned an error, but shouldn't")
 
 	utils.AssertEqual(executor.callCount, 2, t, "executor wasn't called")
-	lastQuery, _, err = executor.lastQuery[0].Format([]driver.NamedValue{})
+	lastQuery, _, err = executor.lastQuery[0].Format([]driver.NamedValue{}, nil)
 	if err != nil {
 		t.Fatalf("Failed to format lastQuery: %v", err)
 	}
@@ -130,3 +138,320 @@ func TestNumInput(t *testing.T) {
 
 	utils.AssertEqual(stmt.NumInput(), -1, t, "NumInput should return -1 for server-side parameters")
 }
+
+// describeExecutorMock returns a describe result for every query
+type describeExecutorMock struct {
+	callCount   int
+	lastQueries []string
+	// lastParameters are the query parameters of the last query
+	lastParameters map[string]string
+	// describeResult overrides the default describe result
+	describeResult string
+}
+
+func (c *describeExecutorMock) ExecutePreparedQueries(ctx context.Context, queries []PreparedQuery, args []driver.NamedValue, isQuery bool) (rows.ExtendableRowsWithResult, error) {
+	c.callCount += 1
+	for _, query := range queries {
+		sql, parameters, err := query.Format(args, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		c.lastQueries = append(c.lastQueries, sql)
+		c.lastParameters = parameters
+	}
+	if !contextUtils.IsDescribe(ctx) {
+		return &rows.InMemoryRows{}, nil
+	}
+	describeResult := `{"parameter_types":{"$1":"int","$2":"array(text)"},"result_columns":[{"name":"a","type":"int"},{"name":"b","type":"array(text) null"},{"name":"c","type":"numeric(38, 2)"}]}`
+	if c.describeResult != "" {
+		describeResult = c.describeResult
+	}
+	response, err := json.Marshal(types.QueryResponse{
+		Meta: []types.Column{{Name: "describe_result", Type: "text"}},
+		Data: [][]interface{}{{describeResult}},
+		Rows: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	queryRows := &rows.InMemoryRows{}
+	return queryRows, queryRows.ProcessAndAppendResponse(client.MakeResponse(io.NopCloser(bytes.NewReader(response)), 200, nil, nil))
+}
+
+// TestDescribeStmt checks that the describe result is cached and used to validate the arguments
+func TestDescribeStmt(t *testing.T) {
+	executor := describeExecutorMock{}
+	stmt, err := MakeStmt(&executor, "SELECT :id AS a, @names AS b, 1.5::numeric(38, 2) AS c WHERE :id > 0", contextUtils.PreparedStatementsStyleFbNumeric)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+
+	result, err := stmt.Describe(context.TODO())
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	utils.AssertEqual(result.ParameterTypes["$1"], "int", t, "parameter type doesn't match")
+	utils.AssertEqual(executor.lastQueries, []string{"SELECT $1 AS a, $2 AS b, 1.5::numeric(38, 2) AS c WHERE $1 > 0"}, t, "named parameters should be numbered in the describe query")
+
+	columnTypes, err := stmt.ColumnTypes(context.TODO())
+	if err != nil {
+		t.Fatalf("ColumnTypes failed: %v", err)
+	}
+	utils.AssertEqual(executor.callCount, 1, t, "describe result should be cached")
+	utils.AssertEqual(len(columnTypes), 3, t, "number of columns doesn't match")
+	utils.AssertEqual(columnTypes[0].ScanType, reflect.TypeOf(int32(0)), t, "scan type doesn't match")
+	utils.AssertEqual(columnTypes[1].Nullable, true, t, "nullability doesn't match")
+	utils.AssertEqual(columnTypes[2].Precision, int64(38), t, "precision doesn't match")
+	utils.AssertEqual(columnTypes[2].Scale, int64(2), t, "scale doesn't match")
+
+	_, err = stmt.QueryContext(context.TODO(), []driver.NamedValue{{Name: "id", Value: int64(1)}, {Name: "names", Value: []string{"a", "b"}}})
+	utils.AssertEqual(err, nil, t, "valid arguments shouldn't be rejected")
+	_, err = stmt.ExecContext(context.TODO(), []driver.NamedValue{{Name: "id", Value: nil}, {Name: "names", Value: []interface{}{"a", nil}}})
+	utils.AssertEqual(err, nil, t, "NULL arguments shouldn't be rejected")
+
+	for _, args := range [][]driver.NamedValue{
+		{{Name: "id", Value: "1"}, {Name: "names", Value: []string{"a"}}},
+		{{Name: "id", Value: int64(1)}, {Name: "names", Value: "a"}},
+		{{Name: "id", Value: int64(1)}, {Name: "names", Value: []int64{1}}},
+	} {
+		if _, err = stmt.ExecContext(context.TODO(), args); err == nil {
+			t.Errorf("arguments %v should be rejected", args)
+		}
+	}
+	utils.AssertEqual(executor.callCount, 3, t, "invalid arguments shouldn't be sent to the server")
+}
+
+// TestDescribeStmtFormatsTimeForParameterTypes checks that time arguments are formatted for the described parameter types
+func TestDescribeStmtFormatsTimeForParameterTypes(t *testing.T) {
+	executor := describeExecutorMock{describeResult: `{"parameter_types":{"$1":"date","$2":"timestamp","$3":"timestamptz null"},"result_columns":[]}`}
+	stmt, err := MakeStmt(&executor, "INSERT INTO t VALUES ($1, $2, $3)", contextUtils.PreparedStatementsStyleFbNumeric)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	if _, err = stmt.Describe(context.TODO()); err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+
+	value := time.Date(2022, 1, 10, 0, 0, 0, 0, time.FixedZone("", 60*60))
+	_, err = stmt.ExecContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: value}, {Ordinal: 2, Value: value}, {Ordinal: 3, Value: value}})
+	utils.AssertEqual(err, nil, t, "ExecContext returned an error")
+	utils.AssertEqual(executor.lastParameters["query_parameters"],
+		`[{"name":"$1","value":"2022-01-10"},{"name":"$2","value":"2022-01-10 00:00:00"},{"name":"$3","value":"2022-01-09 23:00:00+00:00"}]`,
+		t, "time arguments should be formatted for the parameter types")
+}
+
+// TestDescribeStmtBindsStructFields checks that map arguments are bound to the fields of the described STRUCT type
+func TestDescribeStmtBindsStructFields(t *testing.T) {
+	executor := describeExecutorMock{describeResult: `{"parameter_types":{"$1":"struct(name text, id int)"},"result_columns":[]}`}
+	stmt, err := MakeStmt(&executor, "INSERT INTO t VALUES ($1)", contextUtils.PreparedStatementsStyleFbNumeric)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	if _, err = stmt.Describe(context.TODO()); err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+
+	_, err = stmt.ExecContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: map[string]interface{}{"id": 1, "name": "a"}}})
+	utils.AssertEqual(err, nil, t, "ExecContext returned an error")
+	utils.AssertEqual(executor.lastParameters["query_parameters"], `[{"name":"$1","value":"(\"a\",\"1\")"}]`, t, "struct fields should be bound in the order of the type")
+
+	for _, value := range []interface{}{
+		map[string]interface{}{"id": "1", "name": "a"},
+		map[string]interface{}{"id": 1},
+		[]int{1},
+	} {
+		if _, err = stmt.ExecContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: value}}); err == nil {
+			t.Errorf("argument %v should be rejected", value)
+		}
+	}
+	utils.AssertEqual(executor.callCount, 2, t, "invalid arguments shouldn't be sent to the server")
+}
+
+// TestStmtDescribedOnFirstExecution checks that a server-side statement is described on its first execution with arguments
+func TestStmtDescribedOnFirstExecution(t *testing.T) {
+	executor := describeExecutorMock{}
+	stmt, err := MakeStmt(&executor, "SELECT $1 AS a, $2 AS b", contextUtils.PreparedStatementsStyleFbNumeric)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+
+	_, err = stmt.QueryContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: int64(1)}, {Ordinal: 2, Value: []string{"a"}}})
+	utils.AssertEqual(err, nil, t, "QueryContext returned an error")
+	utils.AssertEqual(executor.callCount, 2, t, "statement should be described before its first execution")
+	utils.AssertEqual(stmt.describeResult != nil, true, t, "describe result should be cached")
+
+	_, err = stmt.ExecContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: "1"}, {Ordinal: 2, Value: []string{"a"}}})
+	if err == nil {
+		t.Errorf("arguments that don't match the described parameter types should be rejected")
+	}
+	utils.AssertEqual(executor.callCount, 2, t, "statement should only be described once")
+}
+
+// TestStmtExecutedWhenDescribeFails checks that a statement that can't be described is executed without parameter types
+func TestStmtExecutedWhenDescribeFails(t *testing.T) {
+	executor := describeExecutorMock{describeResult: "not a describe result"}
+	stmt, err := MakeStmt(&executor, "SELECT $1", contextUtils.PreparedStatementsStyleFbNumeric)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err = stmt.ExecContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: int64(1)}})
+		utils.AssertEqual(err, nil, t, "ExecContext returned an error")
+	}
+	utils.AssertEqual(executor.callCount, 3, t, "describe shouldn't be retried")
+	if _, err = stmt.ColumnTypes(context.TODO()); err == nil {
+		t.Errorf("ColumnTypes should return the error of the failed describe")
+	}
+	utils.AssertEqual(executor.callCount, 3, t, "describe shouldn't be retried by ColumnTypes")
+
+	stmt, err = MakeStmt(&executor, "SELECT 1", contextUtils.PreparedStatementsStyleFbNumeric)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	_, err = stmt.ExecContext(context.TODO(), nil)
+	utils.AssertEqual(err, nil, t, "ExecContext returned an error")
+	utils.AssertEqual(executor.callCount, 4, t, "statements without arguments shouldn't be described")
+}
+
+// TestDescribeNativeStmt checks that placeholders of the native style are numbered in the describe query,
+// and that the arguments are formatted for the described parameter types
+func TestDescribeNativeStmt(t *testing.T) {
+	executor := describeExecutorMock{describeResult: `{"parameter_types":{"$1":"date","$2":"timestamp"},"result_columns":[]}`}
+	stmt, err := MakeStmt(&executor, "INSERT INTO t VALUES (?, ?)", contextUtils.PreparedStatementsStyleNative)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	if _, err = stmt.Describe(context.TODO()); err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	value := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	_, err = stmt.ExecContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: value}, {Ordinal: 2, Value: value}})
+	utils.AssertEqual(err, nil, t, "ExecContext returned an error")
+	utils.AssertEqual(executor.lastQueries, []string{"INSERT INTO t VALUES ($1, $2)", "INSERT INTO t VALUES ('2022-01-10', '2022-01-10 00:00:00')"},
+		t, "time arguments should be formatted for the described parameter types")
+
+	executor = describeExecutorMock{describeResult: `{"parameter_types":{"$1":"int","$2":"date"},"result_columns":[]}`}
+	stmt, err = MakeStmt(&executor, "SELECT :id, @day, :id", contextUtils.PreparedStatementsStyleNative)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	if _, err = stmt.Describe(context.TODO()); err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	_, err = stmt.QueryContext(context.TODO(), []driver.NamedValue{{Name: "id", Value: int64(1)}, {Name: "day", Value: value}})
+	utils.AssertEqual(err, nil, t, "QueryContext returned an error")
+	utils.AssertEqual(executor.lastQueries, []string{"SELECT $1, $2, $1", "SELECT 1, '2022-01-10', 1"}, t, "named placeholders should be numbered by name")
+
+	_, err = stmt.QueryContext(context.TODO(), []driver.NamedValue{{Name: "id", Value: "1"}, {Name: "day", Value: value}})
+	if err == nil {
+		t.Errorf("arguments that don't match the described parameter types should be rejected")
+	}
+}
+
+// TestNativeStmtNotDescribedOnExecution checks that only server-side prepared statements are described on their first execution
+func TestNativeStmtNotDescribedOnExecution(t *testing.T) {
+	executor := describeExecutorMock{}
+	stmt, err := MakeStmt(&executor, "SELECT ?", contextUtils.PreparedStatementsStyleNative)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	_, err = stmt.ExecContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: int64(1)}})
+	utils.AssertEqual(err, nil, t, "ExecContext returned an error")
+	utils.AssertEqual(executor.lastQueries, []string{"SELECT 1"}, t, "native statements shouldn't be described on execution")
+}
+
+// TestStmtWithArraySliceDescribed checks that array slices are sent as is in the describe query of a server-side statement
+func TestStmtWithArraySliceDescribed(t *testing.T) {
+	executor := describeExecutorMock{describeResult: `{"parameter_types":{"$1":"int"},"result_columns":[]}`}
+	stmt, err := MakeStmt(&executor, "SELECT arr[1:n] FROM t WHERE id = $1", contextUtils.PreparedStatementsStyleFbNumeric)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	_, err = stmt.QueryContext(context.TODO(), []driver.NamedValue{{Ordinal: 1, Value: int64(1)}})
+	utils.AssertEqual(err, nil, t, "QueryContext returned an error")
+	utils.AssertEqual(executor.lastQueries, []string{"SELECT arr[1:n] FROM t WHERE id = $1", "SELECT arr[1:n] FROM t WHERE id = $1"},
+		t, "array slice shouldn't be replaced")
+}
+
+// TestResolveParameterTypes checks that queries executed once are only described for arguments that depend on the types
+func TestResolveParameterTypes(t *testing.T) {
+	for _, tc := range []struct {
+		args      []driver.NamedValue
+		described bool
+	}{
+		{[]driver.NamedValue{{Ordinal: 1, Value: int64(1)}}, false},
+		{[]driver.NamedValue{{Ordinal: 1, Value: []string{"a"}}}, false},
+		{[]driver.NamedValue{{Ordinal: 1, Value: time.Now()}}, true},
+		{[]driver.NamedValue{{Ordinal: 1, Value: [][]time.Time{{time.Now()}}}}, true},
+		{[]driver.NamedValue{{Ordinal: 1, Value: map[string]interface{}{"a": 1}}}, true},
+	} {
+		executor := describeExecutorMock{}
+		stmt, err := MakeStmt(&executor, "SELECT ?", contextUtils.PreparedStatementsStyleNative)
+		if err != nil {
+			t.Fatalf("Failed to create statement: %v", err)
+		}
+		stmt.ResolveParameterTypes(context.TODO(), tc.args, nil, "")
+		utils.AssertEqual(stmt.describeResult != nil, tc.described, t, fmt.Sprintf("describe of a query with arguments %v doesn't match", tc.args))
+	}
+}
+
+// TestResolveParameterTypesCache checks that the outcome of describing a query executed once is reused
+// for the same query in the same scope
+func TestResolveParameterTypesCache(t *testing.T) {
+	var cache DescribeCache
+	args := []driver.NamedValue{{Ordinal: 1, Value: time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)}}
+	executor := describeExecutorMock{describeResult: `{"parameter_types":{"$1":"date"},"result_columns":[]}`}
+	for i := 0; i < 2; i++ {
+		stmt, err := MakeStmt(&executor, "SELECT ?", contextUtils.PreparedStatementsStyleNative)
+		if err != nil {
+			t.Fatalf("Failed to create statement: %v", err)
+		}
+		stmt.ResolveParameterTypes(context.TODO(), args, &cache, "engine")
+		_, err = stmt.QueryContext(context.TODO(), args)
+		utils.AssertEqual(err, nil, t, "QueryContext returned an error")
+		utils.AssertEqual(executor.lastQueries[len(executor.lastQueries)-1], "SELECT '2022-01-10'", t, "cached parameter types should be used")
+	}
+	utils.AssertEqual(executor.callCount, 3, t, "the query should only be described once")
+
+	stmt, err := MakeStmt(&executor, "SELECT ?", contextUtils.PreparedStatementsStyleNative)
+	if err != nil {
+		t.Fatalf("Failed to create statement: %v", err)
+	}
+	stmt.ResolveParameterTypes(context.TODO(), args, &cache, "other engine")
+	utils.AssertEqual(executor.callCount, 4, t, "the query should be described again in another scope")
+
+	// failures are cached as well
+	executor = describeExecutorMock{describeResult: "not a describe result"}
+	for i := 0; i < 2; i++ {
+		stmt, err = MakeStmt(&executor, "SELECT ? + 1", contextUtils.PreparedStatementsStyleNative)
+		if err != nil {
+			t.Fatalf("Failed to create statement: %v", err)
+		}
+		stmt.ResolveParameterTypes(context.TODO(), args, &cache, "engine")
+		if _, err = stmt.Describe(context.TODO()); err == nil {
+			t.Errorf("the describe error should be kept")
+		}
+	}
+	utils.AssertEqual(executor.callCount, 1, t, "a failed describe shouldn't be retried")
+}
+
+// TestDescribeStmtErrors checks that only single statements can be described
+func TestDescribeStmtErrors(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		style contextUtils.PreparedStatementsStyle
+	}{
+		{"SELECT 1; SELECT 2", contextUtils.PreparedStatementsStyleFbNumeric},
+		{"SET a = b", contextUtils.PreparedStatementsStyleFbNumeric},
+	} {
+		executor := describeExecutorMock{}
+		stmt, err := MakeStmt(&executor, tc.query, tc.style)
+		if err != nil {
+			t.Fatalf("Failed to create statement: %v", err)
+		}
+		if _, err = stmt.Describe(context.TODO()); err == nil {
+			t.Errorf("Describe should fail for query '%s'", tc.query)
+		}
+		utils.AssertEqual(executor.callCount, 0, t, "executor shouldn't be called")
+	}
+}
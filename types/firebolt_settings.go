@@ -1,20 +1,70 @@
 package types
 
 import (
+	"crypto/tls"
 	"net/http"
 	"time"
+
+	"github.com/firebolt-db/firebolt-go-sdk/cache"
 )
 
 type FireboltSettings struct {
-	ClientID           string
-	ClientSecret       string
-	Database           string
-	EngineName         string
-	AccountName        string
-	Url                string
-	NewVersion         bool
-	ClientSideLB       bool
-	DNSTTL             time.Duration
-	Transport          http.RoundTripper
-	DefaultQueryParams map[string]string
+	ClientID            string
+	ClientSecret        string
+	Database            string
+	EngineName          string
+	AccountName         string
+	Url                 string
+	EngineURLs          []string
+	NewVersion          bool
+	ClientSideLB        bool
+	DNSTTL              time.Duration
+	LBPolicy            string
+	SRVName             string
+	DNSServer           string
+	Transport           http.RoundTripper
+	CacheBackend        cache.Backend
+	DefaultQueryParams  map[string]string
+	ResponseCompression []string
+	RequestCompression  string
+	TLS                 TLSSettings
+	Network             NetworkSettings
+}
+
+// TLSSettings configures the TLS connections to the authentication, system engine and engine endpoints
+type TLSSettings struct {
+	// Config is the base TLS configuration, the other settings are applied on top of it
+	Config *tls.Config
+	// CAFile is the path of a PEM bundle of the root CAs trusted instead of the system ones
+	CAFile string
+	// CertFile and KeyFile are the paths of the PEM client certificate and key used for mutual TLS
+	CertFile string
+	KeyFile  string
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13, zero keeps the Go default
+	MinVersion uint16
+}
+
+// IsSet returns whether any TLS setting is configured
+func (s TLSSettings) IsSet() bool {
+	return s.Config != nil || s.CAFile != "" || s.CertFile != "" || s.KeyFile != "" || s.MinVersion != 0
+}
+
+// NetworkSettings tunes the proxy, dialer and connection pool of the SDK's default transport
+type NetworkSettings struct {
+	// ProxyURL is the URL of the HTTP, HTTPS or SOCKS5 proxy, it replaces the proxy of the environment
+	ProxyURL string
+	// NoProxy is a comma separated list of hosts, domains, IP addresses and CIDR ranges reached without proxy,
+	// with the semantics of the NO_PROXY environment variable
+	NoProxy string
+	// DialTimeout bounds the establishment of the TCP connections
+	DialTimeout time.Duration
+	// KeepAlive is the interval of the TCP keep-alive probes
+	KeepAlive time.Duration
+	// MaxIdleConns bounds the number of idle connections kept open across all hosts
+	MaxIdleConns int
+}
+
+// IsSet returns whether any network setting is configured
+func (s NetworkSettings) IsSet() bool {
+	return s != NetworkSettings{}
 }
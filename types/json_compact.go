@@ -30,3 +30,14 @@ type QueryResponse struct {
 	Errors     []ErrorDetails  `json:"errors"`
 	Statistics interface{}     `json:"statistics"`
 }
+
+// Statistics are the execution statistics of a statement reported by the server
+type Statistics struct {
+	Elapsed             float64 `json:"elapsed"`
+	RowsRead            int64   `json:"rows_read"`
+	BytesRead           int64   `json:"bytes_read"`
+	TimeBeforeExecution float64 `json:"time_before_execution"`
+	TimeToExecute       float64 `json:"time_to_execute"`
+	ScannedBytesCache   int64   `json:"scanned_bytes_cache"`
+	ScannedBytesStorage int64   `json:"scanned_bytes_storage"`
+}